@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrAmbiguousAddress = errors.New("address owns several accounts")
+	ErrNoAccount        = errors.New("address owns no account")
+)
+
+// AmbiguousAddressError is returned by ResolveAccountIndex when the address owns several accounts, e.g. sub-accounts.
+// The caller has to pick one of AccountIndexes. It matches ErrAmbiguousAddress with errors.Is.
+type AmbiguousAddressError struct {
+	L1Address      string
+	AccountIndexes []int64
+}
+
+func (e *AmbiguousAddressError) Error() string {
+	return fmt.Sprintf("%v: %v owns accounts %v, pass the account index instead", ErrAmbiguousAddress.Error(), e.L1Address, e.AccountIndexes)
+}
+
+func (e *AmbiguousAddressError) Is(target error) bool {
+	return target == ErrAmbiguousAddress
+}
+
+// accountCache holds the account indexes of the L1 addresses resolved by ResolveAccountIndex, for the lifetime of
+// the client. It's shared by an HTTPClient and its copies.
+type accountCache struct {
+	mu      sync.Mutex
+	indexes map[string][]int64
+}
+
+// AccountIndexes returns the indexes of the accounts owned by an Ethereum address, looking them up once per client.
+func (c *HTTPClient) AccountIndexes(l1Address string) ([]int64, error) {
+	if !common.IsHexAddress(l1Address) {
+		return nil, fmt.Errorf("invalid L1 address %q", l1Address)
+	}
+	key := strings.ToLower(common.HexToAddress(l1Address).Hex())
+
+	c.accounts.mu.Lock()
+	indexes, ok := c.accounts.indexes[key]
+	c.accounts.mu.Unlock()
+	if ok {
+		return indexes, nil
+	}
+
+	accounts, err := c.GetAccountsByL1Address(common.HexToAddress(l1Address).Hex())
+	if err != nil {
+		return nil, err
+	}
+	indexes = make([]int64, 0, len(accounts.SubAccounts))
+	for _, account := range accounts.SubAccounts {
+		indexes = append(indexes, account.Index)
+	}
+
+	c.accounts.mu.Lock()
+	c.accounts.indexes[key] = indexes
+	c.accounts.mu.Unlock()
+	return indexes, nil
+}
+
+// ResolveAccountIndex returns the index of the single account owned by an Ethereum address.
+// It fails with an AmbiguousAddressError when the address owns several, and ErrNoAccount when it owns none.
+func (c *HTTPClient) ResolveAccountIndex(l1Address string) (int64, error) {
+	indexes, err := c.AccountIndexes(l1Address)
+	if err != nil {
+		return -1, err
+	}
+	switch len(indexes) {
+	case 0:
+		return -1, fmt.Errorf("%w: %v", ErrNoAccount, l1Address)
+	case 1:
+		return indexes[0], nil
+	default:
+		return -1, &AmbiguousAddressError{L1Address: l1Address, AccountIndexes: indexes}
+	}
+}
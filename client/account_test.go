@@ -0,0 +1,117 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serveAccountFixture serves the testdata file name on the account endpoint, and stores the query of the
+// last request in query.
+func serveAccountFixture(t *testing.T, name string, query *url.Values) *HTTPClient {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/account" {
+			http.NotFound(w, r)
+			return
+		}
+		*query = r.URL.Query()
+		w.Write(fixture)
+	}))
+	t.Cleanup(srv.Close)
+	return NewHTTPClient(srv.URL)
+}
+
+func TestGetAccountPlain(t *testing.T) {
+	var query url.Values
+	c := serveAccountFixture(t, "account_plain.json", &query)
+
+	account, err := c.GetAccount(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("by") != "index" || query.Get("value") != "42" {
+		t.Errorf("query %v", query)
+	}
+	if account.Index != 42 || account.L1Address != "0x8D7f03FdE1A626223364E592740a233b72395235" ||
+		account.AccountType != 0 || account.Status != 1 {
+		t.Errorf("account %+v", account)
+	}
+	if account.Collateral != "2000.000000" || account.AvailableBalance != "1520.337215" || account.TotalAssetValue != "1987.337215" {
+		t.Errorf("balances %v, %v, %v", account.Collateral, account.AvailableBalance, account.TotalAssetValue)
+	}
+	if account.Name != "" || account.PoolInfo != nil {
+		t.Errorf("pool fields of a plain account: %q, %+v", account.Name, account.PoolInfo)
+	}
+
+	if len(account.Positions) != 1 {
+		t.Fatalf("%v positions", len(account.Positions))
+	}
+	want := Position{
+		MarketId:         0,
+		Symbol:           "ETH",
+		Sign:             -1,
+		Position:         "0.2500",
+		AvgEntryPrice:    "3412.55",
+		PositionValue:    "853.137500",
+		UnrealizedPnl:    "-12.662785",
+		RealizedPnl:      "4.100000",
+		LiquidationPrice: "9811.21",
+		AllocatedMargin:  "0.000000",
+		OpenOrderCount:   1,
+	}
+	if *account.Positions[0] != want {
+		t.Errorf("position %+v, want %+v", *account.Positions[0], want)
+	}
+
+	if len(account.Shares) != 1 {
+		t.Fatalf("%v shares", len(account.Shares))
+	}
+	if share := *account.Shares[0]; share != (PublicPoolShare{PublicPoolIndex: 281474976710654, SharesAmount: 1500, EntryUsdc: "150.000000"}) {
+		t.Errorf("share %+v", share)
+	}
+}
+
+func TestGetAccountPublicPool(t *testing.T) {
+	var query url.Values
+	c := serveAccountFixture(t, "account_public_pool.json", &query)
+
+	accounts, err := c.GetAccountBy("l1_address", "0x8D7f03FdE1A626223364E592740a233b72395235")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("by") != "l1_address" || query.Get("value") != "0x8D7f03FdE1A626223364E592740a233b72395235" {
+		t.Errorf("query %v", query)
+	}
+	if accounts.Code != 200 || accounts.Total != 1 || len(accounts.Accounts) != 1 {
+		t.Fatalf("accounts %+v", accounts)
+	}
+
+	account := accounts.Accounts[0]
+	if account.Index != 281474976710654 || account.AccountType != 1 || account.TotalAssetValue != "100418.250000" {
+		t.Errorf("account %+v", account)
+	}
+	if account.Name != "Basis pool" || account.Description != "Delta neutral funding capture" {
+		t.Errorf("name %q, description %q", account.Name, account.Description)
+	}
+	if len(account.Positions) != 0 || len(account.Shares) != 0 {
+		t.Errorf("positions %v, shares %v", account.Positions, account.Shares)
+	}
+	want := PublicPoolInfo{
+		Status:               0,
+		OperatorFee:          "10.00",
+		MinOperatorShareRate: "5.00",
+		TotalShares:          1_000_000,
+		OperatorShares:       100_000,
+	}
+	if account.PoolInfo == nil || *account.PoolInfo != want {
+		t.Errorf("pool info %+v, want %+v", account.PoolInfo, want)
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when Lighter answers a request with an error: a 200 response whose result code isn't CodeOK,
+// or a 4xx response other than 429 (see RateLimitError). Code is the result code of the body, 0 when it has none,
+// e.g. for a 404 from a proxy. Message is the message of the body, or the whole body when it isn't a result.
+type APIError struct {
+	Code       int
+	Message    string
+	HTTPStatus int
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("%v (code %v)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("%v (status %v)", e.Message, e.HTTPStatus)
+}
+
+// newStatusAPIError builds the APIError of a 4xx response, reading the result code of its body when there is one.
+func newStatusAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{HTTPStatus: status, Message: string(body)}
+	result := &ResultCode{}
+	if err := json.Unmarshal(body, result); err == nil && result.Code != 0 {
+		apiErr.Code = int(result.Code)
+		apiErr.Message = result.Message
+	}
+	return apiErr
+}
+
+// knownCodes are the result codes the Is* helpers recognize. An APIError with one of them is classified by its code
+// only; other errors fall back to matching their message.
+var knownCodes = map[int]struct{}{
+	CodeInvalidNonce:    {},
+	CodeAccidentalPrice: {},
+	CodeNotEnoughMargin: {},
+}
+
+// IsNonceError reports whether Lighter rejected a tx for its nonce, e.g. because it was already used.
+// Re-syncing the nonce (see NonceManager.RecoverGap) and signing again may succeed.
+func IsNonceError(err error) bool {
+	return isAPIError(err, []int{CodeInvalidNonce}, func(msg string) bool {
+		return strings.Contains(msg, "nonce")
+	})
+}
+
+// IsInsufficientBalance reports whether Lighter rejected a tx because the account lacks the funds or margin for it.
+func IsInsufficientBalance(err error) bool {
+	return isAPIError(err, []int{CodeNotEnoughMargin}, func(msg string) bool {
+		return strings.Contains(msg, "insufficient") || strings.Contains(msg, "not enough")
+	})
+}
+
+// IsOrderNotFound reports whether Lighter rejected a cancel or modify because the order doesn't exist anymore,
+// e.g. because it was filled. Its result code isn't known, so it's only recognized by its message.
+func IsOrderNotFound(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatus == http.StatusNotFound && apiErr.Code == 0 {
+		return false // a missing endpoint, not a missing order
+	}
+	return isAPIError(err, nil, func(msg string) bool {
+		return strings.Contains(msg, "order") && strings.Contains(msg, "not found")
+	})
+}
+
+// isAPIError reports whether err is an APIError with one of codes. When its code isn't one of knownCodes,
+// matches is called with its lowercased message instead.
+func isAPIError(err error, codes []int, matches func(msg string) bool) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.Code == code {
+			return true
+		}
+	}
+	if _, ok := knownCodes[apiErr.Code]; ok {
+		return false
+	}
+	return matches(strings.ToLower(apiErr.Message))
+}
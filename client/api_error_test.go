@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIErrorFromResponses(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		status      int
+		body        string
+		want        APIError
+		nonce       bool
+		balance     bool
+		orderAbsent bool
+	}{
+		{
+			name: "invalid nonce", status: http.StatusOK,
+			body:  `{"code":21104,"message":"invalid nonce"}`,
+			want:  APIError{Code: CodeInvalidNonce, Message: "invalid nonce", HTTPStatus: http.StatusOK},
+			nonce: true,
+		},
+		{
+			name: "not enough margin", status: http.StatusOK,
+			body:    `{"code":21739,"message":"not enough margin to create the order"}`,
+			want:    APIError{Code: CodeNotEnoughMargin, Message: "not enough margin to create the order", HTTPStatus: http.StatusOK},
+			balance: true,
+		},
+		{
+			// a known code is classified by its code, whatever its message says
+			name: "accidental price", status: http.StatusOK,
+			body: `{"code":21733,"message":"order price flagged as an accidental price, not enough nonce"}`,
+			want: APIError{Code: CodeAccidentalPrice, Message: "order price flagged as an accidental price, not enough nonce", HTTPStatus: http.StatusOK},
+		},
+		{
+			// an unknown code falls back to the message
+			name: "order not found", status: http.StatusOK,
+			body:        `{"code":29999,"message":"Order not found"}`,
+			want:        APIError{Code: 29999, Message: "Order not found", HTTPStatus: http.StatusOK},
+			orderAbsent: true,
+		},
+		{
+			name: "bad request", status: http.StatusBadRequest,
+			body: `{"code":20001,"message":"invalid param"}`,
+			want: APIError{Code: 20001, Message: "invalid param", HTTPStatus: http.StatusBadRequest},
+		},
+		{
+			name: "missing endpoint", status: http.StatusNotFound,
+			body: `404 page not found, order not found`,
+			want: APIError{Code: 0, Message: "404 page not found, order not found", HTTPStatus: http.StatusNotFound},
+		},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		}))
+		_, err := NewHTTPClient(srv.URL).GetNextNonce(1, 0)
+		srv.Close()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%v: got %v, want an APIError", tc.name, err)
+		}
+		if *apiErr != tc.want {
+			t.Errorf("%v: got %+v, want %+v", tc.name, *apiErr, tc.want)
+		}
+		if IsNonceError(err) != tc.nonce || IsInsufficientBalance(err) != tc.balance || IsOrderNotFound(err) != tc.orderAbsent {
+			t.Errorf("%v: IsNonceError %v, IsInsufficientBalance %v, IsOrderNotFound %v", tc.name,
+				IsNonceError(err), IsInsufficientBalance(err), IsOrderNotFound(err))
+		}
+	}
+}
+
+func TestIsHelpersIgnoreOtherErrors(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		errors.New("invalid nonce"),
+		&RateLimitError{Body: "not enough requests left"},
+	} {
+		if IsNonceError(err) || IsInsufficientBalance(err) || IsOrderNotFound(err) {
+			t.Errorf("%v classified as an APIError", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AuthTokenLifetime is the default lifetime of the auth tokens generated by GetValidAuthToken,
+// see ClientDefaults.AuthTokenTTL.
+const AuthTokenLifetime = 7 * time.Hour
+
+// authTokenCache holds the last auth token generated by GetValidAuthToken. A TxClient is bound to a single
+// (account, apiKey) pair, so one entry per client is enough.
+type authTokenCache struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	token    string
+	deadline time.Time
+}
+
+func (a *authTokenCache) clock() time.Time {
+	if a.now == nil {
+		return time.Now()
+	}
+	return a.now()
+}
+
+// GetValidAuthToken returns an auth token valid for at least minValidity, reusing the previously generated one
+// while it is. Otherwise a new token expiring the client's AuthTokenTTL from now is generated and cached.
+func (c *TxClient) GetValidAuthToken(minValidity time.Duration) (string, error) {
+	ttl := c.Defaults().AuthTokenTTL
+	if minValidity < 0 || minValidity >= ttl {
+		return "", fmt.Errorf("min validity should be within [0, %v). got: %v", ttl, minValidity)
+	}
+
+	c.authToken.mu.Lock()
+	defer c.authToken.mu.Unlock()
+
+	now := c.authToken.clock()
+	if c.authToken.token != "" && c.authToken.deadline.Sub(now) >= minValidity {
+		return c.authToken.token, nil
+	}
+
+	deadline := now.Add(ttl).Truncate(time.Second)
+	token, err := c.GetAuthToken(deadline)
+	if err != nil {
+		return "", err
+	}
+	c.authToken.token = token
+	c.authToken.deadline = deadline
+	return token, nil
+}
+
+// InvalidateAuthToken drops the token cached by GetValidAuthToken, so the next call generates a new one.
+func (c *TxClient) InvalidateAuthToken() {
+	c.authToken.mu.Lock()
+	defer c.authToken.mu.Unlock()
+
+	c.authToken.token = ""
+	c.authToken.deadline = time.Time{}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// BatchTxResult is the outcome of one tx of a batch: its hash, or the error that kept it out of the batch.
+type BatchTxResult struct {
+	TxHash string
+	Err    error
+}
+
+// SendRawTxBatch submits several signed txs in one request. Txs which can't be encoded get their own error and are
+// left out of the batch. The returned error is set when the whole batch failed, i.e. transport failures and batches
+// rejected by Lighter; the results then hold that error for every tx that was part of the batch.
+func (c *HTTPClient) SendRawTxBatch(txs []txtypes.TxInfo) ([]BatchTxResult, error) {
+	return c.sendRawTxBatch(context.Background(), txs)
+}
+
+func (c *HTTPClient) sendRawTxBatch(ctx context.Context, txs []txtypes.TxInfo) ([]BatchTxResult, error) {
+	ctx = c.requestContext(ctx)
+	results := make([]BatchTxResult, len(txs))
+
+	txTypes := make([]uint8, 0, len(txs))
+	txInfos := make([]string, 0, len(txs))
+	sent := make([]int, 0, len(txs))
+	for i, tx := range txs {
+		if tx == nil {
+			results[i].Err = fmt.Errorf("tx is nil")
+			continue
+		}
+		txInfo, err := tx.GetTxInfo()
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		txTypes = append(txTypes, tx.GetTxType())
+		txInfos = append(txInfos, txInfo)
+		sent = append(sent, i)
+	}
+	if len(sent) == 0 {
+		return results, fmt.Errorf("no valid tx in batch")
+	}
+
+	sentResults, err := c.sendEncodedTxBatch(ctx, txTypes, txInfos)
+	for j, i := range sent {
+		results[i] = sentResults[j]
+	}
+	return results, err
+}
+
+// SendEncodedTxBatch is SendRawTxBatch for txs already encoded with GetTxInfo, e.g. signed by another process.
+func (c *HTTPClient) SendEncodedTxBatch(txTypes []uint8, txInfos []string) ([]BatchTxResult, error) {
+	if len(txTypes) != len(txInfos) {
+		return nil, fmt.Errorf("got %d tx types for %d txs", len(txTypes), len(txInfos))
+	}
+	return c.sendEncodedTxBatch(c.requestContext(context.Background()), txTypes, txInfos)
+}
+
+func (c *HTTPClient) sendEncodedTxBatch(ctx context.Context, txTypes []uint8, txInfos []string) ([]BatchTxResult, error) {
+	results := make([]BatchTxResult, len(txInfos))
+
+	txTypesJson, err := json.Marshal(txTypes)
+	if err != nil {
+		return nil, err
+	}
+	txInfosJson, err := json.Marshal(txInfos)
+	if err != nil {
+		return nil, err
+	}
+	data := url.Values{"tx_types": {string(txTypesJson)}, "tx_infos": {string(txInfosJson)}}
+	if !c.FatFingerProtection() {
+		data.Add("price_protection", "false")
+	}
+
+	res := &TxHashes{}
+	if err := c.postAndParseL2HTTPResponse(ctx, "sendTxBatch", data, nil, res); err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results, err
+	}
+
+	for i := range results {
+		if i < len(res.TxHashes) && res.TxHashes[i] != "" {
+			results[i].TxHash = res.TxHashes[i]
+		} else {
+			results[i].Err = fmt.Errorf("no tx hash returned for tx %d of the batch", i)
+		}
+	}
+	return results, nil
+}
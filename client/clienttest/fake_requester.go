@@ -0,0 +1,94 @@
+// Package clienttest provides fakes of the client package, to test code built on a TxClient without a live endpoint.
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var _ client.L2Requester = (*FakeRequester)(nil)
+
+// FakeRequester is a programmable client.L2Requester. Each request calls its Func when set; otherwise:
+//   - GetNextNonceCtx returns the nonce after the last one sent for the API key, like a server accepting every tx
+//   - SendRawTxCtx accepts the tx and returns its hash
+//   - GetStatus reports a server up on no particular chain
+//   - GetApiKeyCtx and AccountIndexes fail
+//
+// Sent txs are recorded, rejected ones included. A FakeRequester is safe for concurrent use; the Funcs must be set
+// before it's used.
+type FakeRequester struct {
+	NextNonceFunc      func(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error)
+	ApiKeyFunc         func(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (*client.AccountApiKeys, error)
+	SendRawTxFunc      func(ctx context.Context, tx txtypes.TxInfo, opts client.SendOpts) (string, error)
+	StatusFunc         func(ctx context.Context) (*client.Status, error)
+	AccountIndexesFunc func(l1Address string) ([]int64, error)
+
+	mu   sync.Mutex
+	sent []txtypes.TxInfo
+}
+
+func (f *FakeRequester) GetNextNonceCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	if f.NextNonceFunc != nil {
+		return f.NextNonceFunc(ctx, accountIndex, apiKeyIndex)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var next int64
+	for _, tx := range f.sent {
+		txAccountIndex, txApiKeyIndex, nonce, ok := client.TxNonce(tx)
+		if ok && txAccountIndex == accountIndex && txApiKeyIndex == apiKeyIndex {
+			next = max(next, nonce+1)
+		}
+	}
+	return next, nil
+}
+
+func (f *FakeRequester) GetApiKeyCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (*client.AccountApiKeys, error) {
+	if f.ApiKeyFunc != nil {
+		return f.ApiKeyFunc(ctx, accountIndex, apiKeyIndex)
+	}
+	return nil, fmt.Errorf("clienttest: no api key programmed for account %v api key %v", accountIndex, apiKeyIndex)
+}
+
+func (f *FakeRequester) SendRawTxCtx(ctx context.Context, tx txtypes.TxInfo, opts client.SendOpts) (string, error) {
+	f.mu.Lock()
+	f.sent = append(f.sent, tx)
+	f.mu.Unlock()
+
+	if f.SendRawTxFunc != nil {
+		return f.SendRawTxFunc(ctx, tx, opts)
+	}
+	return tx.GetTxHash(), nil
+}
+
+func (f *FakeRequester) GetStatus(ctx context.Context) (*client.Status, error) {
+	if f.StatusFunc != nil {
+		return f.StatusFunc(ctx)
+	}
+	return &client.Status{Status: 200}, nil
+}
+
+func (f *FakeRequester) AccountIndexes(l1Address string) ([]int64, error) {
+	if f.AccountIndexesFunc != nil {
+		return f.AccountIndexesFunc(l1Address)
+	}
+	return nil, fmt.Errorf("clienttest: no accounts programmed for %v", l1Address)
+}
+
+// Sent returns the txs passed to SendRawTxCtx, in order.
+func (f *FakeRequester) Sent() []txtypes.TxInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]txtypes.TxInfo(nil), f.sent...)
+}
+
+// Reset forgets the sent txs.
+func (f *FakeRequester) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
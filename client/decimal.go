@@ -0,0 +1,82 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is a decimal number as returned by the API, kept as its original text so no precision is lost.
+// It decodes from JSON strings as well as JSON numbers (scientific notation included), and encodes back
+// as a JSON string. Use Rat or Units for arithmetic; Float64 is only meant for display.
+type Decimal string
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = Decimal(strings.TrimSpace(s))
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid decimal %s", data)
+	}
+	*d = Decimal(n)
+	return nil
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Rat returns the exact value of d. An empty Decimal is zero.
+func (d Decimal) Rat() (*big.Rat, error) {
+	if d == "" {
+		return new(big.Rat), nil
+	}
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", string(d))
+	}
+	return r, nil
+}
+
+// Units converts d into an integer with the given number of decimals, e.g. "12.345" with 2 decimals is 1234.
+// Extra decimals are truncated toward zero. It fails if the result doesn't fit an int64.
+func (d Decimal) Units(decimals uint8) (int64, error) {
+	r, err := d.Rat()
+	if err != nil {
+		return 0, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	num := new(big.Int).Mul(r.Num(), scale)
+	units := num.Quo(num, r.Denom())
+	if !units.IsInt64() {
+		return 0, fmt.Errorf("decimal %q overflows int64 with %d decimals", string(d), decimals)
+	}
+	return units.Int64(), nil
+}
+
+// Float64 returns the nearest float64 to d, for display purposes. Invalid decimals are 0.
+func (d Decimal) Float64() float64 {
+	r, err := d.Rat()
+	if err != nil {
+		return 0
+	}
+	f, _ := r.Float64()
+	return f
+}
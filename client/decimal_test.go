@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalDecode(t *testing.T) {
+	for _, tc := range []struct {
+		json     string
+		want     Decimal
+		decimals uint8
+		units    int64
+	}{
+		{`"0.1"`, "0.1", 6, 100_000},
+		// above 2^53: a float64 would round them
+		{`9007199254740993`, "9007199254740993", 0, 9007199254740993},
+		{`"12345678901234567.89"`, "12345678901234567.89", 2, 1234567890123456789},
+		{`0.30000000000000004441`, "0.30000000000000004441", 19, 3000000000000000444},
+		// scientific notation, as numbers and as strings
+		{`1e-7`, "1e-7", 8, 10},
+		{`-2.5E2`, "-2.5E2", 0, -250},
+		{`"1.5e+3"`, "1.5e+3", 1, 15_000},
+		{`" 3.25 "`, "3.25", 2, 325},
+		// truncated toward zero
+		{`"-1.239"`, "-1.239", 2, -123},
+		{`null`, "", 6, 0},
+	} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(tc.json), &d); err != nil {
+			t.Fatalf("%v: %v", tc.json, err)
+		}
+		if d != tc.want {
+			t.Errorf("%v: decoded %q, want %q", tc.json, d, tc.want)
+		}
+		if units, err := d.Units(tc.decimals); err != nil || units != tc.units {
+			t.Errorf("%v: Units(%v) %v, %v, want %v", tc.json, tc.decimals, units, err, tc.units)
+		}
+	}
+
+	for _, bad := range []string{`true`, `{}`, `[1]`} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(bad), &d); err == nil {
+			t.Errorf("%v decoded as %q", bad, d)
+		}
+	}
+	if _, err := Decimal("abc").Rat(); err == nil {
+		t.Error("invalid decimal has a value")
+	}
+	if _, err := Decimal("1e30").Units(0); err == nil {
+		t.Error("overflowing decimal converted")
+	}
+}
+
+func TestDecimalRatIsExact(t *testing.T) {
+	r, err := Decimal("12345678901234567.89").Rat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewRat(1234567890123456789, 100); r.Cmp(want) != 0 {
+		t.Fatalf("got %v, want %v", r, want)
+	}
+	if f := Decimal("1e-7").Float64(); f != 1e-7 {
+		t.Fatalf("Float64 %v", f)
+	}
+}
+
+func TestDecimalModelFields(t *testing.T) {
+	const accountJSON = `{
+		"index": 7,
+		"collateral": 12345678901234567.89,
+		"available_balance": "9007199254740993.000001",
+		"total_asset_value": 1.2345e4,
+		"positions": [{
+			"market_id": 1,
+			"position": "0.000000000000000001",
+			"avg_entry_price": 6.5E+4,
+			"unrealized_pnl": -1e-9
+		}]
+	}`
+	var account Account
+	if err := json.Unmarshal([]byte(accountJSON), &account); err != nil {
+		t.Fatal(err)
+	}
+	position := account.Positions[0]
+	for got, want := range map[Decimal]Decimal{
+		account.Collateral:       "12345678901234567.89",
+		account.AvailableBalance: "9007199254740993.000001",
+		account.TotalAssetValue:  "1.2345e4",
+		position.Position:        "0.000000000000000001",
+		position.AvgEntryPrice:   "6.5E+4",
+		position.UnrealizedPnl:   "-1e-9",
+	} {
+		if got != want {
+			t.Errorf("decoded %q, want %q", got, want)
+		}
+	}
+	if units, err := position.AvgEntryPrice.Units(2); err != nil || units != 6_500_000 {
+		t.Errorf("avg entry price in cents: %v, %v", units, err)
+	}
+
+	var fundings Fundings
+	if err := json.Unmarshal([]byte(`{"code":200,"fundings":[{"timestamp":1,"value":"0.1","rate":1.25e-5,"direction":"long"}]}`), &fundings); err != nil {
+		t.Fatal(err)
+	}
+	if rate := fundings.Fundings[0].Rate; rate != "1.25e-5" {
+		t.Errorf("funding rate %q", rate)
+	}
+
+	// decimals are encoded back as strings, unchanged
+	encoded, err := json.Marshal(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Position
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != *position {
+		t.Errorf("round trip: got %+v, want %+v", decoded, *position)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["avg_entry_price"] != "6.5E+4" {
+		t.Errorf("encoded avg_entry_price %#v", raw["avg_entry_price"])
+	}
+}
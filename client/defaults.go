@@ -0,0 +1,62 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elliottech/lighter-go/orders"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// maxAuthTokenTTL is the longest auth token lifetime accepted by GetAuthToken.
+const maxAuthTokenTTL = 7 * time.Hour
+
+// ClientDefaults are the values a TxClient uses for what requests leave to it.
+type ClientDefaults struct {
+	// OrderExpiry is how long orders needing an expiry live when they're given orders.DefaultExpiry.
+	OrderExpiry time.Duration
+	// AuthTokenTTL is the lifetime of the auth tokens generated by GetValidAuthToken.
+	AuthTokenTTL time.Duration
+	// TimeInForce replaces orders.DefaultTimeInForce in orders.
+	TimeInForce uint8
+}
+
+// DefaultClientDefaults returns the defaults of a new TxClient.
+func DefaultClientDefaults() ClientDefaults {
+	return ClientDefaults{
+		OrderExpiry:  orders.DefaultExpiryPeriod,
+		AuthTokenTTL: AuthTokenLifetime,
+		TimeInForce:  txtypes.GoodTillTime,
+	}
+}
+
+func (d ClientDefaults) Validate() error {
+	if d.OrderExpiry <= 0 || d.OrderExpiry > time.Duration(txtypes.MaxOrderExpiryPeriod)*time.Millisecond {
+		return fmt.Errorf("order expiry should be within (0, %v]. got: %v", time.Duration(txtypes.MaxOrderExpiryPeriod)*time.Millisecond, d.OrderExpiry)
+	}
+	if d.AuthTokenTTL <= 0 || d.AuthTokenTTL > maxAuthTokenTTL {
+		return fmt.Errorf("auth token TTL should be within (0, %v]. got: %v", maxAuthTokenTTL, d.AuthTokenTTL)
+	}
+	if d.TimeInForce != txtypes.ImmediateOrCancel && d.TimeInForce != txtypes.GoodTillTime && d.TimeInForce != txtypes.PostOnly {
+		return txtypes.ErrOrderTimeInForceInvalid
+	}
+	return nil
+}
+
+// SetDefaults replaces the client's defaults, after validating them.
+func (c *TxClient) SetDefaults(defaults ClientDefaults) error {
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+	c.defaultsMu.Lock()
+	defer c.defaultsMu.Unlock()
+	c.defaults = defaults
+	return nil
+}
+
+// Defaults returns the effective defaults of the client.
+func (c *TxClient) Defaults() ClientDefaults {
+	c.defaultsMu.RLock()
+	defer c.defaultsMu.RUnlock()
+	return c.defaults
+}
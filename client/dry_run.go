@@ -0,0 +1,59 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// maxDryRuns bounds the dry run txs a TxClient remembers; the oldest are forgotten first.
+const maxDryRuns = 1024
+
+// dryRunSet remembers the signed hashes of the latest dry run txs, so SendTx can refuse them.
+// The zero value is ready to use.
+type dryRunSet struct {
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// signed records whether tx was signed with ops as a dry run. A tx signed for real with the same hash as an earlier
+// dry run is sendable again.
+func (s *dryRunSet) signed(ops *types.TransactOpts, tx txtypes.TxInfo) {
+	txHash := tx.GetTxHash()
+	if txHash == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		if !ops.DryRun {
+			return
+		}
+		s.order = list.New()
+		s.entries = make(map[string]*list.Element)
+	}
+
+	if e, ok := s.entries[txHash]; ok {
+		s.order.Remove(e)
+		delete(s.entries, txHash)
+	}
+	if !ops.DryRun {
+		return
+	}
+	s.entries[txHash] = s.order.PushBack(txHash)
+	if s.order.Len() > maxDryRuns {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(string))
+	}
+}
+
+func (s *dryRunSet) has(tx txtypes.TxInfo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[tx.GetTxHash()]
+	return ok
+}
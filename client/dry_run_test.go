@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elliottech/lighter-go/types"
+)
+
+func TestDryRunDoesNotReserveNonce(t *testing.T) {
+	srv := newSendTxServer(t)
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	m := NewNonceManager(func(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) { return 40, nil }, 0)
+	c.SetNonceManager(m)
+
+	for i := 0; i < 2; i++ {
+		dry, err := c.GetCreateOrderTransaction(testOrderReq(1), &types.TransactOpts{DryRun: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dry.Nonce != 40 {
+			t.Fatalf("dry run %v got nonce %v, want 40", i, dry.Nonce)
+		}
+	}
+	dryBatch, err := c.GetCreateOrderTransactions([]*types.CreateOrderTxReq{testOrderReq(1), testOrderReq(2)}, &types.TransactOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dryBatch[0].Nonce != 40 || dryBatch[1].Nonce != 41 {
+		t.Fatalf("dry run batch got nonces %v and %v, want 40 and 41", dryBatch[0].Nonce, dryBatch[1].Nonce)
+	}
+
+	tx, err := c.GetCreateOrderTransaction(testOrderReq(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce != 40 {
+		t.Fatalf("first real tx got nonce %v, want 40", tx.Nonce)
+	}
+}
+
+func TestSendTxRefusesDryRun(t *testing.T) {
+	srv := newSendTxServer(t)
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	req, nonce := testOrderReq(1), int64(1)
+	ops := types.TransactOpts{Nonce: &nonce, ExpiredAt: req.OrderExpiry - 1}
+
+	dryOps := ops
+	dryOps.DryRun = true
+	dry, err := c.GetCreateOrderTransaction(req, &dryOps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendTx(context.Background(), dry); !errors.Is(err, ErrDryRunTx) {
+		t.Fatalf("got %v, want ErrDryRunTx", err)
+	}
+	if posts := len(srv.posts()); posts != 0 {
+		t.Fatalf("dry run sent %v times", posts)
+	}
+
+	// signing the same tx for real makes it sendable
+	tx, err := c.GetCreateOrderTransaction(req, &ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.GetTxHash() != dry.GetTxHash() {
+		t.Fatal("the real tx differs from the dry run")
+	}
+	if _, err := c.SendTx(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+}
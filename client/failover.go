@@ -0,0 +1,178 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFailoverCooldown is how long an endpoint which failed is skipped before being tried again.
+const DefaultFailoverCooldown = 30 * time.Second
+
+// endpoint is shared by an HTTPClient and its copies made by WithRequestOpts, so SetEndpoint repoints all of them.
+// It holds the base URLs to fail over between, the primary first, and the one currently in use.
+type endpoint struct {
+	mu       sync.RWMutex
+	url      string
+	urls     []string
+	failedAt map[string]time.Time
+	cooldown time.Duration
+}
+
+func newEndpoint(baseUrl string) *endpoint {
+	return &endpoint{
+		url:      baseUrl,
+		urls:     []string{baseUrl},
+		failedAt: make(map[string]time.Time),
+		cooldown: DefaultFailoverCooldown,
+	}
+}
+
+// WithFallbackEndpoints adds base URLs to fail over to when the primary one can't be reached, tried in order.
+func WithFallbackEndpoints(baseUrls ...string) HTTPClientOption {
+	return func(c *HTTPClient) {
+		c.endpoint.urls = append(c.endpoint.urls, baseUrls...)
+	}
+}
+
+// WithFailoverCooldown replaces DefaultFailoverCooldown.
+func WithFailoverCooldown(cooldown time.Duration) HTTPClientOption {
+	return func(c *HTTPClient) {
+		c.endpoint.cooldown = cooldown
+	}
+}
+
+// SetEndpoints replaces the base URLs of the client: the primary one, which is used from now on, then the
+// fallbacks. See SetEndpoint.
+func (c *HTTPClient) SetEndpoints(baseUrls []string) error {
+	if len(baseUrls) == 0 {
+		return fmt.Errorf("no endpoint")
+	}
+	for _, baseUrl := range baseUrls {
+		if err := checkEndpoint(baseUrl); err != nil {
+			return err
+		}
+	}
+
+	c.endpoint.mu.Lock()
+	defer c.endpoint.mu.Unlock()
+	c.endpoint.url = baseUrls[0]
+	c.endpoint.urls = slices.Clone(baseUrls)
+	c.endpoint.failedAt = make(map[string]time.Time)
+	return nil
+}
+
+// Endpoints returns the base URLs of the client, the primary first.
+func (c *HTTPClient) Endpoints() []string {
+	c.endpoint.mu.RLock()
+	defer c.endpoint.mu.RUnlock()
+	return slices.Clone(c.endpoint.urls)
+}
+
+func checkEndpoint(baseUrl string) error {
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q. err: %v", baseUrl, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q, expected an http(s) URL", baseUrl)
+	}
+	return nil
+}
+
+// canFailOver reports whether a request which failed with err may be retried against another endpoint.
+// Reads are retried on any network error. Txs (POST requests) only when the request demonstrably never reached
+// a server, so a tx which may have been received isn't submitted twice.
+func canFailOver(method string, err error) bool {
+	var ne *NetworkError
+	if !errors.As(err, &ne) {
+		return false
+	}
+	if method == http.MethodGet {
+		return true
+	}
+	switch ne.Kind {
+	case NetworkErrorDNSFailure, NetworkErrorConnRefused, NetworkErrorTLS:
+		return true
+	default:
+		return false
+	}
+}
+
+// failOver marks the base URL failed and switches to the next one which isn't cooling down, returning it.
+// It returns "" when there is none. tried holds the URLs already tried by the request, which are skipped.
+func (e *endpoint) failOver(failed string, tried []string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.failedAt[failed] = now
+	start := slices.Index(e.urls, failed)
+	for i := 1; i <= len(e.urls); i++ {
+		candidate := e.urls[(start+i+len(e.urls))%len(e.urls)]
+		if slices.Contains(tried, candidate) {
+			continue
+		}
+		if failedAt, ok := e.failedAt[candidate]; ok && now.Sub(failedAt) < e.cooldown {
+			continue
+		}
+		// a concurrent request may already have moved on to another endpoint
+		if e.url == failed {
+			e.url = candidate
+		}
+		return candidate
+	}
+	return ""
+}
+
+// baseOf returns the base URL of the client which req was built for, "" if it's for another host.
+func (e *endpoint) baseOf(req *http.Request) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, baseUrl := range append([]string{e.url}, e.urls...) {
+		u, err := url.Parse(baseUrl)
+		if err == nil && u.Scheme == req.URL.Scheme && u.Host == req.URL.Host && strings.HasPrefix(req.URL.Path, strings.TrimSuffix(u.Path, "/")) {
+			return baseUrl
+		}
+	}
+	return ""
+}
+
+// succeeded clears the failure of a base URL which answered.
+func (e *endpoint) succeeded(baseUrl string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.failedAt, baseUrl)
+}
+
+// rebase points req, built for the base URL from, to the base URL to.
+func rebase(req *http.Request, from, to string) (*http.Request, error) {
+	fromURL, err := url.Parse(from)
+	if err != nil {
+		return nil, err
+	}
+	toURL, err := url.Parse(to)
+	if err != nil {
+		return nil, err
+	}
+
+	rebased := req.Clone(req.Context())
+	rebased.URL.Scheme = toURL.Scheme
+	rebased.URL.Host = toURL.Host
+	rebased.URL.Path = strings.TrimSuffix(toURL.Path, "/") + strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(fromURL.Path, "/"))
+	rebased.URL.RawPath = ""
+	rebased.Host = ""
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		rebased.Body = body
+	}
+	return rebased, nil
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	testChainId      = 304
+	testAccountIndex = 5
+	testApiKeyIndex  = 3
+)
+
+// newTestTxClient returns a TxClient with a fixed key, sending through requester (nil for an offline client).
+func newTestTxClient(t *testing.T, requester L2Requester) *TxClient {
+	t.Helper()
+	keyManager, err := keys.DeriveApiKey(make([]byte, keys.MinMasterSeedLength), testAccountIndex, testApiKeyIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTxClientWithKeyManager(requester, keyManager, testAccountIndex, testApiKeyIndex, testChainId)
+}
+
+func testOrderReq(clientOrderIndex int64) *types.CreateOrderTxReq {
+	return &types.CreateOrderTxReq{
+		MarketIndex:      1,
+		ClientOrderIndex: clientOrderIndex,
+		BaseAmount:       1000,
+		Price:            250000,
+		TimeInForce:      txtypes.GoodTillTime,
+		OrderExpiry:      time.Now().Add(time.Hour).UnixMilli(),
+	}
+}
+
+// signTestOrder signs an order with the given nonce.
+func signTestOrder(t *testing.T, c *TxClient, nonce int64) *txtypes.L2CreateOrderTxInfo {
+	t.Helper()
+	tx, err := c.GetCreateOrderTransaction(testOrderReq(nonce), &types.TransactOpts{Nonce: &nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
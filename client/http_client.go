@@ -1,9 +1,15 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 )
 
@@ -12,6 +18,7 @@ var (
 		Timeout:   10 * time.Second,
 		KeepAlive: 60 * time.Second,
 	}
+	// transport is cloned by every HTTPClient, so closing the connections of one doesn't affect the others
 	transport = &http.Transport{
 		DialContext:         dialer.DialContext,
 		MaxConnsPerHost:     1000,
@@ -19,31 +26,291 @@ var (
 		IdleConnTimeout:     10 * time.Second,
 		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
 	}
+)
 
-	httpClient = &http.Client{
-		Timeout:   time.Second * 30,
-		Transport: transport,
-	}
+const (
+	defaultBasePath = "/api/v1"
+	defaultTimeout  = 30 * time.Second
 )
 
 type HTTPClient struct {
-	endpoint            string
+	// mu guards the settings which can change while requests are in flight: headers, channelName,
+	// fatFingerProtection, the timeouts and the callbacks. It's shared with the clones of WithRequestOpts,
+	// which share headers too.
+	mu                  *sync.RWMutex
+	httpClient          *http.Client // timeouts are set per request by doOnce
+	endpoint            *endpoint
 	channelName         string
 	fatFingerProtection bool
+	basePath            string
+	headers             http.Header
+	defaultQuery        url.Values
+	requestOpts         *RequestOpts
+	onMaintenance       func(*MaintenanceError)
+	onRequest           func(RequestLog)
+	markets             *marketCache
+	accounts            *accountCache
+	health              *health
+	rateLimit           *rateLimitTracker
+	timeout             time.Duration
+	sendTimeout         time.Duration
+}
+
+type HTTPClientOption func(*HTTPClient)
+
+// WithHeader adds a header sent with every request. Reserved headers (Content-Type, Authorization)
+// can't be set this way; requests made by a client configured with one fail.
+func WithHeader(key, value string) HTTPClientOption {
+	return func(c *HTTPClient) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithBasePath replaces the default "/api/v1" prefix of the API paths, e.g. for gateways serving another version.
+func WithBasePath(basePath string) HTTPClientOption {
+	return func(c *HTTPClient) {
+		c.basePath = basePath
+	}
+}
+
+// WithDefaultQuery adds query params sent with every request. Params set by a request take precedence.
+func WithDefaultQuery(params map[string]string) HTTPClientOption {
+	return func(c *HTTPClient) {
+		for key, value := range params {
+			c.defaultQuery.Set(key, value)
+		}
+	}
 }
 
-func NewHTTPClient(baseUrl string) *HTTPClient {
+// WithTimeout replaces the default 30s timeout of the requests.
+func WithTimeout(timeout time.Duration) HTTPClientOption {
+	return func(c *HTTPClient) {
+		c.timeout = timeout
+	}
+}
+
+func NewHTTPClient(baseUrl string, opts ...HTTPClientOption) *HTTPClient {
 	if baseUrl == "" {
 		return nil
 	}
 
-	return &HTTPClient{
-		endpoint:            baseUrl,
+	c := &HTTPClient{
+		mu:                  &sync.RWMutex{},
+		httpClient:          &http.Client{Transport: transport.Clone()},
+		endpoint:            newEndpoint(baseUrl),
 		channelName:         "",
 		fatFingerProtection: true,
+		basePath:            defaultBasePath,
+		headers:             make(http.Header),
+		defaultQuery:        make(url.Values),
+		health:              &health{unreachableAfter: defaultUnreachableAfter},
+		rateLimit:           &rateLimitTracker{status: RateLimitStatus{Limit: -1, Remaining: -1}},
+		timeout:             defaultTimeout,
+		markets:             &marketCache{},
+		accounts:            &accountCache{indexes: make(map[string][]int64)},
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// SetEndpoint switches the client to another base URL, e.g. to fail over to a backup gateway.
+// Requests already in flight complete against the previous URL; new ones use baseUrl.
+// It drops the fallback endpoints; use SetEndpoints to keep some.
+func (c *HTTPClient) SetEndpoint(baseUrl string) error {
+	return c.SetEndpoints([]string{baseUrl})
+}
+
+// Endpoint returns the base URL requests are currently sent to.
+func (c *HTTPClient) Endpoint() string {
+	c.endpoint.mu.RLock()
+	defer c.endpoint.mu.RUnlock()
+	return c.endpoint.url
 }
 
 func (c *HTTPClient) SetFatFingerProtection(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.fatFingerProtection = enabled
 }
+
+// SetTimeout sets how long a request may take, reading the response included. Zero or less disables the timeout.
+func (c *HTTPClient) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = timeout
+}
+
+// SetSendTimeout overrides the timeout of the POST requests, i.e. the ones sending txs. Zero uses the SetTimeout one.
+func (c *HTTPClient) SetSendTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendTimeout = timeout
+}
+
+func (c *HTTPClient) requestTimeout(method string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if method == http.MethodPost && c.sendTimeout != 0 {
+		return c.sendTimeout
+	}
+	return c.timeout
+}
+
+// cancelOnClose releases the timeout of a request once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// RequestLog describes a finished request. URL has no query, since it may carry auth tokens.
+// Status is 0 when no response was received.
+type RequestLog struct {
+	Method   string
+	URL      string
+	Status   int
+	Err      error
+	Duration time.Duration
+}
+
+// OnRequest registers a callback invoked after every request, e.g. to log them.
+func (c *HTTPClient) OnRequest(fn func(RequestLog)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRequest = fn
+}
+
+func (c *HTTPClient) logRequest(req *http.Request, status int, err error, start time.Time) {
+	c.mu.RLock()
+	onRequest := c.onRequest
+	c.mu.RUnlock()
+	if onRequest == nil {
+		return
+	}
+	onRequest(RequestLog{
+		Method:   req.Method,
+		URL:      redactURL(req.URL),
+		Status:   status,
+		Err:      err,
+		Duration: time.Since(start),
+	})
+}
+
+// do sends req, classifying transport failures as NetworkError and tracking the health of the endpoint.
+// When the endpoint can't be reached and canFailOver allows it, req is sent again to the next endpoint.
+func (c *HTTPClient) do(req *http.Request) (*http.Response, error) {
+	base := c.endpoint.baseOf(req)
+	tried := []string{base}
+	for {
+		resp, err := c.doOnce(req)
+		if err == nil {
+			if base != "" {
+				c.endpoint.succeeded(base)
+			}
+			return resp, nil
+		}
+		// a request cancelled or timed out by the caller isn't retried elsewhere
+		if base == "" || req.Context().Err() != nil || !canFailOver(req.Method, err) {
+			return nil, err
+		}
+		next := c.endpoint.failOver(base, tried)
+		if next == "" {
+			return nil, err
+		}
+		if req, err = rebase(req, base, next); err != nil {
+			return nil, err
+		}
+		base = next
+		tried = append(tried, next)
+	}
+}
+
+func (c *HTTPClient) doOnce(req *http.Request) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	timeout := c.requestTimeout(req.Method)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		// the *url.Error of a transport failure quotes the full URL, auth tokens included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(req.URL)
+		}
+		if ctx.Err() == context.DeadlineExceeded && req.Context().Err() == nil {
+			err = fmt.Errorf("request to %v timed out after %v. err: %w", redactURL(req.URL), timeout, err)
+		}
+		cancel()
+		err = classifyNetworkError(err)
+		c.health.record(err)
+		c.logRequest(req, 0, err, start)
+		return nil, err
+	}
+	c.health.record(nil)
+	c.rateLimit.record(resp.Header, time.Now())
+	c.logRequest(req, resp.StatusCode, nil, start)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// redactURL returns u without its credentials and query, since they may carry auth tokens.
+func redactURL(u *url.URL) string {
+	return fmt.Sprintf("%v://%v%v", u.Scheme, u.Host, u.Path)
+}
+
+// SetDefaultHeader sets a header sent with every request, replacing its previous value. Headers set by a request,
+// like Channel-Name, take precedence. Reserved headers (Content-Type, Authorization) are rejected.
+func (c *HTTPClient) SetDefaultHeader(key, value string) error {
+	return c.SetDefaultHeaders(map[string]string{key: value})
+}
+
+// SetDefaultHeaders sets several default headers at once, see SetDefaultHeader. None is set if one is reserved.
+func (c *HTTPClient) SetDefaultHeaders(headers map[string]string) error {
+	h := make(http.Header, len(headers))
+	for key, value := range headers {
+		h.Set(key, value)
+	}
+	if err := checkHeaders(h); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, values := range h {
+		c.headers[key] = values
+	}
+	return nil
+}
+
+// SetChannelName sets the Channel-Name header sent with txs. The header is omitted when name is empty.
+func (c *HTTPClient) SetChannelName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.channelName = name
+}
+
+func (c *HTTPClient) channel() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.channelName
+}
+
+func (c *HTTPClient) FatFingerProtection() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fatFingerProtection
+}
+
+// CloseIdleConnections closes the idle keep-alive connections of the client's transport, which it only shares
+// with its WithRequestOpts clones.
+func (c *HTTPClient) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSettersDuringRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":200,"nonce":7}`)
+	}))
+	defer srv.Close()
+	c := NewHTTPClient(srv.URL)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := c.GetNextNonce(1, 0); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := c.WithRequestOpts(RequestOpts{}).GetNextNonce(1, 0); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for i := 0; time.Now().Before(deadline); i++ {
+		if err := c.SetDefaultHeader(fmt.Sprintf("X-Test-%v", i%10), "v"); err != nil {
+			t.Fatal(err)
+		}
+		c.SetChannelName(fmt.Sprintf("channel-%v", i))
+		c.SetTimeout(time.Duration(i+1) * time.Second)
+		c.SetSendTimeout(time.Duration(i) * time.Second)
+		c.SetFatFingerProtection(i%2 == 0)
+		c.OnRequest(func(RequestLog) {})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	got := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Clone()
+		fmt.Fprint(w, `{"code":200,"nonce":7}`)
+	}))
+	defer srv.Close()
+	c := NewHTTPClient(srv.URL)
+
+	if err := c.SetDefaultHeader("Content-Type", "text/plain"); err == nil {
+		t.Fatal("reserved header accepted")
+	}
+	if err := c.SetDefaultHeader("X-Gateway-Key", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNextNonce(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if h := <-got; h.Get("X-Gateway-Key") != "secret" {
+		t.Fatalf("X-Gateway-Key = %q", h.Get("X-Gateway-Key"))
+	}
+}
+
+func TestTransportErrorsRedactAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	c := NewHTTPClient(url)
+	var logged RequestLog
+	c.OnRequest(func(r RequestLog) { logged = r })
+	_, err := c.GetActiveOrders(1, 0, "secret-auth-token")
+	if err == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "secret-auth-token") {
+		t.Fatalf("error leaks the auth token: %v", err)
+	}
+	if strings.Contains(logged.URL, "secret-auth-token") || strings.Contains(logged.Err.Error(), "secret-auth-token") {
+		t.Fatalf("request log leaks the auth token: %+v", logged)
+	}
+}
+
+func TestGetCtxHonorsContext(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"code":200,"accounts":[]}`)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewHTTPClient(srv.URL).GetAccountCtx(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if requests != 0 {
+		t.Fatalf("cancelled GET sent %v requests", requests)
+	}
+}
+
+func TestEndpointURL(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint, want string
+	}{
+		{"https://host", "https://host/api/v1/nextNonce"},
+		{"https://host/", "https://host/api/v1/nextNonce"},
+		{"https://host/a/b", "https://host/a/b/api/v1/nextNonce"},
+		{"https://host/a/b/", "https://host/a/b/api/v1/nextNonce"},
+	} {
+		u, err := NewHTTPClient(tc.endpoint).endpointURL("/api/v1/nextNonce")
+		if err != nil || u.String() != tc.want {
+			t.Errorf("%v: got %v, %v, want %v", tc.endpoint, u, err, tc.want)
+		}
+	}
+}
+
+func TestRequestPaths(t *testing.T) {
+	paths := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"code":200,"tx_hash":"hash"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code":200,"nonce":7}`)
+	}))
+	defer srv.Close()
+	tx := signTestOrder(t, newTestTxClient(t, nil), 1)
+
+	for _, prefix := range []string{"", "/", "/a/b/"} {
+		c := NewHTTPClient(srv.URL + prefix)
+		want := strings.TrimSuffix(prefix, "/") + "/api/v1/"
+		if _, err := c.GetNextNonce(1, 0); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-paths; got != want+"nextNonce" {
+			t.Errorf("%q: GET %v, want %v", prefix, got, want+"nextNonce")
+		}
+		if _, err := c.SendRawTx(tx); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-paths; got != want+"sendTx" {
+			t.Errorf("%q: POST %v, want %v", prefix, got, want+"sendTx")
+		}
+	}
+}
@@ -1,12 +1,13 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	gopath "path"
 	"strconv"
 	"strings"
 
@@ -19,24 +20,57 @@ func (c *HTTPClient) parseResultStatus(respBody []byte) error {
 		return err
 	}
 	if resultStatus.Code != CodeOK {
-		return errors.New(resultStatus.Message)
+		return &APIError{Code: int(resultStatus.Code), Message: resultStatus.Message, HTTPStatus: http.StatusOK}
 	}
 	return nil
 }
 
 func (c *HTTPClient) getAndParseL2HTTPResponse(path string, params map[string]any, result interface{}) error {
-	u, err := url.Parse(c.endpoint)
+	return c.getAndParseL2HTTPResponseCtx(context.Background(), path, params, result)
+}
+
+func (c *HTTPClient) getAndParseL2HTTPResponseCtx(ctx context.Context, path string, params map[string]any, result interface{}) error {
+	return c.getAndParseURLPath(ctx, gopath.Join("/", c.basePath, path), params, result)
+}
+
+// endpointURL returns the URL of urlPath on the endpoint. A path prefix of the endpoint, as in
+// https://gateway.example.com/lighter, is kept and urlPath is appended to it.
+func (c *HTTPClient) endpointURL(urlPath string) (*url.URL, error) {
+	u, err := url.Parse(c.Endpoint())
+	if err != nil {
+		return nil, err
+	}
+	return u.JoinPath(urlPath), nil
+}
+
+// getAndParseURLPath is getAndParseL2HTTPResponseCtx for a URL path relative to the endpoint, outside of the base path.
+func (c *HTTPClient) getAndParseURLPath(ctx context.Context, urlPath string, params map[string]any, result interface{}) error {
+	ctx = c.requestContext(ctx)
+	u, err := c.endpointURL(urlPath)
 	if err != nil {
 		return err
 	}
-	u.Path = path
 
 	q := u.Query()
 	for k, v := range params {
 		q.Set(k, fmt.Sprintf("%v", v))
 	}
+	if err := c.applyParams(ctx, q); err != nil {
+		return err
+	}
 	u.RawQuery = q.Encode()
-	resp, err := httpClient.Get(u.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if err := c.applyDefaultHeaders(req); err != nil {
+		return err
+	}
+	if err := applyRequestHeaders(ctx, req); err != nil {
+		return err
+	}
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -46,10 +80,12 @@ func (c *HTTPClient) getAndParseL2HTTPResponse(path string, params map[string]an
 		return err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return errors.New(string(body))
+		return c.statusError(resp, body)
 	}
-	if err = c.parseResultStatus(body); err != nil {
-		return err
+	if !skipResultCode(ctx) {
+		if err = c.parseResultStatus(body); err != nil {
+			return err
+		}
 	}
 	if err := json.Unmarshal(body, result); err != nil {
 		return err
@@ -58,8 +94,13 @@ func (c *HTTPClient) getAndParseL2HTTPResponse(path string, params map[string]an
 }
 
 func (c *HTTPClient) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return c.GetNextNonceCtx(context.Background(), accountIndex, apiKeyIndex)
+}
+
+// GetNextNonceCtx is GetNextNonce bounded by ctx. A cancelled ctx fails with an error matching ctx.Err().
+func (c *HTTPClient) GetNextNonceCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
 	result := &NextNonce{}
-	err := c.getAndParseL2HTTPResponse("api/v1/nextNonce", map[string]any{"account_index": accountIndex, "api_key_index": apiKeyIndex}, result)
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "nextNonce", map[string]any{"account_index": accountIndex, "api_key_index": apiKeyIndex}, result)
 	if err != nil {
 		return -1, err
 	}
@@ -67,15 +108,54 @@ func (c *HTTPClient) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64,
 }
 
 func (c *HTTPClient) GetApiKey(accountIndex int64, apiKeyIndex uint8) (*AccountApiKeys, error) {
+	return c.GetApiKeyCtx(context.Background(), accountIndex, apiKeyIndex)
+}
+
+// GetApiKeyCtx is GetApiKey bounded by ctx.
+func (c *HTTPClient) GetApiKeyCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (*AccountApiKeys, error) {
 	result := &AccountApiKeys{}
-	err := c.getAndParseL2HTTPResponse("api/v1/apikeys", map[string]any{"account_index": accountIndex, "api_key_index": apiKeyIndex}, result)
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "apikeys", map[string]any{"account_index": accountIndex, "api_key_index": apiKeyIndex}, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// allApiKeys asks the apikeys endpoint for every API key of the account.
+const allApiKeys uint8 = 255
+
+// GetApiKeys returns all the API keys registered on the account.
+func (c *HTTPClient) GetApiKeys(accountIndex int64) (*AccountApiKeys, error) {
+	return c.GetApiKeysCtx(context.Background(), accountIndex)
+}
+
+// GetApiKeysCtx is GetApiKeys bounded by ctx.
+func (c *HTTPClient) GetApiKeysCtx(ctx context.Context, accountIndex int64) (*AccountApiKeys, error) {
+	return c.GetApiKeyCtx(ctx, accountIndex, allApiKeys)
+}
+
+type SendOpts struct {
+	// PriceProtection overrides the client's fat finger protection setting for this tx only. Nil uses the client's setting.
+	PriceProtection *bool
+	// BypassCache sends the tx even if the TxClient's SubmissionCache already holds an outcome for it.
+	BypassCache bool
+}
+
 func (c *HTTPClient) SendRawTx(tx txtypes.TxInfo) (string, error) {
+	return c.SendRawTxWithOpts(tx, SendOpts{})
+}
+
+func (c *HTTPClient) SendRawTxWithOpts(tx txtypes.TxInfo, opts SendOpts) (string, error) {
+	return c.sendRawTx(context.Background(), tx, opts)
+}
+
+// SendRawTxCtx is SendRawTxWithOpts bounded by ctx. A tx whose request is cancelled may still have reached Lighter.
+func (c *HTTPClient) SendRawTxCtx(ctx context.Context, tx txtypes.TxInfo, opts SendOpts) (string, error) {
+	return c.sendRawTx(ctx, tx, opts)
+}
+
+func (c *HTTPClient) sendRawTx(ctx context.Context, tx txtypes.TxInfo, opts SendOpts) (string, error) {
+	ctx = c.requestContext(ctx)
 	txType := tx.GetTxType()
 	txInfo, err := tx.GetTxInfo()
 	if err != nil {
@@ -84,39 +164,84 @@ func (c *HTTPClient) SendRawTx(tx txtypes.TxInfo) (string, error) {
 
 	data := url.Values{"tx_type": {strconv.Itoa(int(txType))}, "tx_info": {txInfo}}
 
-	if c.fatFingerProtection == false {
+	priceProtection := c.FatFingerProtection()
+	if opts.PriceProtection != nil {
+		priceProtection = *opts.PriceProtection
+	}
+	if !priceProtection {
 		data.Add("price_protection", "false")
 	}
 
-	req, _ := http.NewRequest("POST", c.endpoint+"/api/v1/sendTx", strings.NewReader(data.Encode()))
-	req.Header.Set("Channel-Name", c.channelName)
+	res := &TxHash{}
+	if err := c.postAndParseL2HTTPResponse(ctx, "sendTx", data, nil, res); err != nil {
+		return "", err
+	}
+
+	return res.TxHash, nil
+}
+
+func (c *HTTPClient) postAndParseL2HTTPResponse(ctx context.Context, path string, form url.Values, headers http.Header, result interface{}) error {
+	u, err := c.endpointURL(gopath.Join("/", c.basePath, path))
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	if err := c.applyParams(ctx, q); err != nil {
+		return err
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	if err := c.applyDefaultHeaders(req); err != nil {
+		return err
+	}
+	if channelName := c.channel(); channelName != "" {
+		req.Header.Set("Channel-Name", channelName)
+	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := httpClient.Do(req)
+	if err := checkHeaders(headers); err != nil {
+		return err
+	}
+	for key, values := range headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if err := applyRequestHeaders(ctx, req); err != nil {
+		return err
+	}
+	resp, err := c.do(req)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(string(body))
+		return c.statusError(resp, body)
 	}
-	if err = c.parseResultStatus(body); err != nil {
-		return "", err
+	if !skipResultCode(ctx) {
+		if err = c.parseResultStatus(body); err != nil {
+			return err
+		}
 	}
-	res := &TxHash{}
-	if err := json.Unmarshal(body, res); err != nil {
-		return "", err
+	if err := json.Unmarshal(body, result); err != nil {
+		return err
 	}
-
-	return res.TxHash, nil
+	return nil
 }
 
 func (c *HTTPClient) GetTransferFeeInfo(accountIndex, toAccountIndex int64, auth string) (*TransferFeeInfo, error) {
+	return c.GetTransferFeeInfoCtx(context.Background(), accountIndex, toAccountIndex, auth)
+}
+
+// GetTransferFeeInfoCtx is GetTransferFeeInfo bounded by ctx.
+func (c *HTTPClient) GetTransferFeeInfoCtx(ctx context.Context, accountIndex, toAccountIndex int64, auth string) (*TransferFeeInfo, error) {
 	result := &TransferFeeInfo{}
-	err := c.getAndParseL2HTTPResponse("api/v1/transferFeeInfo", map[string]any{
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "transferFeeInfo", map[string]any{
 		"account_index":    accountIndex,
 		"to_account_index": toAccountIndex,
 		"auth":             auth,
@@ -126,3 +251,187 @@ func (c *HTTPClient) GetTransferFeeInfo(accountIndex, toAccountIndex int64, auth
 	}
 	return result, nil
 }
+
+// GetAccountTrades returns one page of trades for the account, oldest first.
+// Pass the NextCursor of the previous page to continue; an empty cursor starts from the beginning.
+func (c *HTTPClient) GetAccountTrades(accountIndex int64, cursor string, limit int64, auth string) (*Trades, error) {
+	return c.GetAccountTradesCtx(context.Background(), accountIndex, cursor, limit, auth)
+}
+
+// GetAccountTradesCtx is GetAccountTrades bounded by ctx.
+func (c *HTTPClient) GetAccountTradesCtx(ctx context.Context, accountIndex int64, cursor string, limit int64, auth string) (*Trades, error) {
+	params := map[string]any{
+		"account_index": accountIndex,
+		"sort_by":       "timestamp",
+		"sort_dir":      "asc",
+		"limit":         limit,
+		"auth":          auth,
+	}
+	if cursor != "" {
+		params["cursor"] = cursor
+	}
+
+	result := &Trades{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "trades", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAccountBy returns the accounts matching by and value, with their positions. by is "index" for an account
+// index, or "l1_address" for the accounts owned by an Ethereum address.
+func (c *HTTPClient) GetAccountBy(by string, value string) (*Accounts, error) {
+	return c.GetAccountByCtx(context.Background(), by, value)
+}
+
+// GetAccountByCtx is GetAccountBy bounded by ctx.
+func (c *HTTPClient) GetAccountByCtx(ctx context.Context, by string, value string) (*Accounts, error) {
+	result := &Accounts{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "account", map[string]any{"by": by, "value": value}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAccount returns the account with the given index, with its positions.
+func (c *HTTPClient) GetAccount(accountIndex int64) (*Account, error) {
+	return c.GetAccountCtx(context.Background(), accountIndex)
+}
+
+// GetAccountCtx is GetAccount bounded by ctx.
+func (c *HTTPClient) GetAccountCtx(ctx context.Context, accountIndex int64) (*Account, error) {
+	result, err := c.GetAccountByCtx(ctx, "index", strconv.FormatInt(accountIndex, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Accounts) == 0 {
+		return nil, fmt.Errorf("account %v not found", accountIndex)
+	}
+	return result.Accounts[0], nil
+}
+
+// GetActiveOrders returns the resting orders of the account on the market. It requires an auth token of the account.
+func (c *HTTPClient) GetActiveOrders(accountIndex int64, marketIndex uint8, auth string) (*Orders, error) {
+	return c.GetActiveOrdersCtx(context.Background(), accountIndex, marketIndex, auth)
+}
+
+// GetActiveOrdersCtx is GetActiveOrders bounded by ctx.
+func (c *HTTPClient) GetActiveOrdersCtx(ctx context.Context, accountIndex int64, marketIndex uint8, auth string) (*Orders, error) {
+	result := &Orders{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "accountActiveOrders", map[string]any{
+		"account_index": accountIndex,
+		"market_id":     marketIndex,
+		"auth":          auth,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAccountsByL1Address returns the accounts owned by an Ethereum address.
+func (c *HTTPClient) GetAccountsByL1Address(l1Address string) (*SubAccounts, error) {
+	return c.GetAccountsByL1AddressCtx(context.Background(), l1Address)
+}
+
+// GetAccountsByL1AddressCtx is GetAccountsByL1Address bounded by ctx.
+func (c *HTTPClient) GetAccountsByL1AddressCtx(ctx context.Context, l1Address string) (*SubAccounts, error) {
+	result := &SubAccounts{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "accountsByL1Address", map[string]any{"l1_address": l1Address}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOrderBooks returns the metadata of every market.
+func (c *HTTPClient) GetOrderBooks() (*OrderBooks, error) {
+	return c.GetOrderBooksCtx(context.Background())
+}
+
+// GetOrderBooksCtx is GetOrderBooks bounded by ctx.
+func (c *HTTPClient) GetOrderBooksCtx(ctx context.Context) (*OrderBooks, error) {
+	result := &OrderBooks{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "orderBooks", nil, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOrderBookDetails returns the details of a market.
+func (c *HTTPClient) GetOrderBookDetails(marketIndex uint8) (*OrderBookDetails, error) {
+	return c.GetOrderBookDetailsCtx(context.Background(), marketIndex)
+}
+
+// GetOrderBookDetailsCtx is GetOrderBookDetails bounded by ctx.
+func (c *HTTPClient) GetOrderBookDetailsCtx(ctx context.Context, marketIndex uint8) (*OrderBookDetails, error) {
+	result := &OrderBookDetails{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "orderBookDetails", map[string]any{"market_id": marketIndex}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOrderBookOrders returns at most limit of the best asks and bids of the market.
+func (c *HTTPClient) GetOrderBookOrders(marketIndex uint8, limit int64) (*OrderBookOrders, error) {
+	return c.GetOrderBookOrdersCtx(context.Background(), marketIndex, limit)
+}
+
+// GetOrderBookOrdersCtx is GetOrderBookOrders bounded by ctx.
+func (c *HTTPClient) GetOrderBookOrdersCtx(ctx context.Context, marketIndex uint8, limit int64) (*OrderBookOrders, error) {
+	result := &OrderBookOrders{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "orderBookOrders", map[string]any{
+		"market_id": marketIndex,
+		"limit":     limit,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCandlesticks returns at most countBack candles of the market between the two timestamps (in milliseconds).
+func (c *HTTPClient) GetCandlesticks(marketIndex uint8, resolution string, startTimestamp, endTimestamp, countBack int64) (*Candlesticks, error) {
+	return c.GetCandlesticksCtx(context.Background(), marketIndex, resolution, startTimestamp, endTimestamp, countBack)
+}
+
+// GetCandlesticksCtx is GetCandlesticks bounded by ctx.
+func (c *HTTPClient) GetCandlesticksCtx(ctx context.Context, marketIndex uint8, resolution string, startTimestamp, endTimestamp, countBack int64) (*Candlesticks, error) {
+	result := &Candlesticks{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "candlesticks", map[string]any{
+		"market_id":       marketIndex,
+		"resolution":      resolution,
+		"start_timestamp": startTimestamp,
+		"end_timestamp":   endTimestamp,
+		"count_back":      countBack,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFundings returns at most countBack funding entries of the market between the two timestamps (in milliseconds).
+func (c *HTTPClient) GetFundings(marketIndex uint8, resolution string, startTimestamp, endTimestamp, countBack int64) (*Fundings, error) {
+	return c.GetFundingsCtx(context.Background(), marketIndex, resolution, startTimestamp, endTimestamp, countBack)
+}
+
+// GetFundingsCtx is GetFundings bounded by ctx.
+func (c *HTTPClient) GetFundingsCtx(ctx context.Context, marketIndex uint8, resolution string, startTimestamp, endTimestamp, countBack int64) (*Fundings, error) {
+	result := &Fundings{}
+	err := c.getAndParseL2HTTPResponseCtx(ctx, "fundings", map[string]any{
+		"market_id":       marketIndex,
+		"resolution":      resolution,
+		"start_timestamp": startTimestamp,
+		"end_timestamp":   endTimestamp,
+		"count_back":      countBack,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -1,7 +1,15 @@
 package client
 
+import "github.com/elliottech/lighter-go/keys"
+
 const (
 	CodeOK = 200
+
+	// Result codes of rejected txs, see APIError. Lighter doesn't publish an exhaustive list; these are the ones
+	// the Is* helpers recognize.
+	CodeInvalidNonce    = 21104
+	CodeAccidentalPrice = 21733 // the order price is too far from the mark price, see HTTPClient.SetFatFingerProtection
+	CodeNotEnoughMargin = 21739
 )
 
 type ResultCode struct {
@@ -21,6 +29,11 @@ type ApiKey struct {
 	PublicKey    string `json:"public_key"`
 }
 
+// PubKey parses PublicKey, which the API encodes as prefix-less hex.
+func (k *ApiKey) PubKey() (keys.PubKey, error) {
+	return keys.ParseAPIHex(k.PublicKey)
+}
+
 type AccountApiKeys struct {
 	ResultCode
 	ApiKeys []*ApiKey `json:"api_keys"`
@@ -31,7 +44,199 @@ type TxHash struct {
 	TxHash string `json:"tx_hash,example=0x70997970C51812dc3A010C7d01b50e0d17dc79C8"`
 }
 
+type TxHashes struct {
+	ResultCode
+	TxHashes []string `json:"tx_hash"`
+}
+
+// SubAccount is one of the accounts owned by an L1 address.
+type SubAccount struct {
+	Index       int64  `json:"index"`
+	L1Address   string `json:"l1_address"`
+	AccountType uint8  `json:"account_type"`
+}
+
+// SubAccounts lists the accounts (the master account and its sub-accounts) of an L1 address.
+type SubAccounts struct {
+	ResultCode
+	L1Address   string        `json:"l1_address"`
+	SubAccounts []*SubAccount `json:"sub_accounts"`
+}
+
+// OrderBook is the metadata of a market.
+type OrderBook struct {
+	Symbol                 string `json:"symbol"`
+	MarketId               uint8  `json:"market_id"`
+	Status                 string `json:"status"`
+	SupportedSizeDecimals  uint8  `json:"supported_size_decimals"`
+	SupportedPriceDecimals uint8  `json:"supported_price_decimals"`
+}
+
+type OrderBooks struct {
+	ResultCode
+	OrderBooks []*OrderBook `json:"order_books"`
+}
+
+// OrderBookDetail holds the margin parameters of a market. Margin fractions are in txtypes.MarginFractionTick units.
+type OrderBookDetail struct {
+	Symbol                       string `json:"symbol"`
+	MarketId                     uint8  `json:"market_id"`
+	DefaultInitialMarginFraction uint16 `json:"default_initial_margin_fraction"`
+	MinInitialMarginFraction     uint16 `json:"min_initial_margin_fraction"`
+	MaintenanceMarginFraction    uint16 `json:"maintenance_margin_fraction"`
+}
+
+type OrderBookDetails struct {
+	ResultCode
+	OrderBookDetails []*OrderBookDetail `json:"order_book_details"`
+}
+
+type OrderBookOrder struct {
+	Price               Decimal `json:"price"`                 // USDC per whole base unit
+	RemainingBaseAmount Decimal `json:"remaining_base_amount"` // in whole base units
+}
+
+// OrderBookOrders holds the best resting orders of a market, best first.
+type OrderBookOrders struct {
+	ResultCode
+	TotalAsks int64             `json:"total_asks"`
+	Asks      []*OrderBookOrder `json:"asks"`
+	TotalBids int64             `json:"total_bids"`
+	Bids      []*OrderBookOrder `json:"bids"`
+}
+
 type TransferFeeInfo struct {
 	ResultCode
 	TransferFee int64 `json:"transfer_fee_usdc"`
 }
+
+type Trade struct {
+	TradeId      int64   `json:"trade_id"`
+	TxHash       string  `json:"tx_hash"`
+	Type         string  `json:"type"`
+	MarketId     uint8   `json:"market_id"`
+	Size         Decimal `json:"size"`       // base amount, in whole base units
+	Price        Decimal `json:"price"`      // USDC per whole base unit
+	UsdAmount    Decimal `json:"usd_amount"` // in whole USDC
+	AskId        int64   `json:"ask_id"`
+	BidId        int64   `json:"bid_id"`
+	AskAccountId int64   `json:"ask_account_id"`
+	BidAccountId int64   `json:"bid_account_id"`
+	IsMakerAsk   bool    `json:"is_maker_ask"`
+	BlockHeight  int64   `json:"block_height"`
+	Timestamp    int64   `json:"timestamp"`
+	TakerFee     int64   `json:"taker_fee"` // fee rate, in txtypes.FeeTick units
+	MakerFee     int64   `json:"maker_fee"` // fee rate, in txtypes.FeeTick units
+}
+
+type Trades struct {
+	ResultCode
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Trades     []*Trade `json:"trades"`
+}
+
+type Candlestick struct {
+	Timestamp   int64   `json:"timestamp"`
+	Open        Decimal `json:"open"`
+	High        Decimal `json:"high"`
+	Low         Decimal `json:"low"`
+	Close       Decimal `json:"close"`
+	Volume0     Decimal `json:"volume0"`
+	Volume1     Decimal `json:"volume1"`
+	LastTradeId int64   `json:"last_trade_id"`
+}
+
+type Candlesticks struct {
+	ResultCode
+	Resolution   string         `json:"resolution"`
+	Candlesticks []*Candlestick `json:"candlesticks"`
+}
+
+type Funding struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     Decimal `json:"value"` // in whole USDC
+	Rate      Decimal `json:"rate"`  // funding rate, exactly as sent by the API
+	Direction string  `json:"direction"`
+}
+
+type Fundings struct {
+	ResultCode
+	Resolution string     `json:"resolution"`
+	Fundings   []*Funding `json:"fundings"`
+}
+
+type Position struct {
+	MarketId         uint8   `json:"market_id"`
+	Symbol           string  `json:"symbol"`
+	Sign             int8    `json:"sign"`     // 1 for long, -1 for short
+	Position         Decimal `json:"position"` // in whole base units
+	AvgEntryPrice    Decimal `json:"avg_entry_price"`
+	PositionValue    Decimal `json:"position_value"` // in whole USDC
+	UnrealizedPnl    Decimal `json:"unrealized_pnl"`
+	RealizedPnl      Decimal `json:"realized_pnl"`
+	LiquidationPrice Decimal `json:"liquidation_price"`
+	MarginMode       uint8   `json:"margin_mode"`
+	AllocatedMargin  Decimal `json:"allocated_margin"` // isolated margin, in whole USDC
+	OpenOrderCount   int64   `json:"open_order_count"`
+}
+
+type Account struct {
+	Index            int64       `json:"index"`
+	L1Address        string      `json:"l1_address"`
+	AccountType      uint8       `json:"account_type"`
+	Status           uint8       `json:"status"`
+	Collateral       Decimal     `json:"collateral"` // in whole USDC
+	AvailableBalance Decimal     `json:"available_balance"`
+	TotalAssetValue  Decimal     `json:"total_asset_value"`
+	Positions        []*Position `json:"positions"`
+
+	// Shares are the shares the account holds in public pools.
+	Shares []*PublicPoolShare `json:"shares,omitempty"`
+	// Name, Description and PoolInfo are only set for public pool accounts.
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	PoolInfo    *PublicPoolInfo `json:"pool_info,omitempty"`
+}
+
+type PublicPoolShare struct {
+	PublicPoolIndex int64   `json:"public_pool_index"`
+	SharesAmount    int64   `json:"shares_amount"`
+	EntryUsdc       Decimal `json:"entry_usdc"` // in whole USDC
+}
+
+type PublicPoolInfo struct {
+	Status               uint8   `json:"status"`
+	OperatorFee          Decimal `json:"operator_fee"` // percentage of the profits
+	MinOperatorShareRate Decimal `json:"min_operator_share_rate"`
+	TotalShares          int64   `json:"total_shares"`
+	OperatorShares       int64   `json:"operator_shares"`
+}
+
+type Accounts struct {
+	ResultCode
+	Total    int64      `json:"total"`
+	Accounts []*Account `json:"accounts"`
+}
+
+type Order struct {
+	OrderIndex          int64   `json:"order_index"`
+	ClientOrderIndex    int64   `json:"client_order_index"`
+	MarketIndex         uint8   `json:"market_index"`
+	InitialBaseAmount   Decimal `json:"initial_base_amount"` // in whole base units
+	RemainingBaseAmount Decimal `json:"remaining_base_amount"`
+	Price               Decimal `json:"price"` // USDC per whole base unit
+	TriggerPrice        Decimal `json:"trigger_price"`
+	IsAsk               bool    `json:"is_ask"`
+	Type                string  `json:"type"`
+	TimeInForce         string  `json:"time_in_force"`
+	ReduceOnly          bool    `json:"reduce_only"`
+	OrderExpiry         int64   `json:"order_expiry"`
+	Status              string  `json:"status"`
+	Timestamp           int64   `json:"timestamp"`
+}
+
+type Orders struct {
+	ResultCode
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Orders     []*Order `json:"orders"`
+}
@@ -0,0 +1,283 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var (
+	ErrInterceptorRejected = errors.New("rejected by interceptor")
+	ErrKillSwitchEngaged   = errors.New("kill switch engaged")
+	ErrTxModifiedAfterSign = errors.New("tx was modified after signing")
+)
+
+// Interceptor hooks into the lifecycle of the transactions created and sent by a TxClient.
+// Interceptors are invoked in registration order. A non-nil error from OnBeforeSign or OnBeforeSend
+// aborts the operation and is returned wrapped in ErrInterceptorRejected.
+type Interceptor interface {
+	// OnBeforeSign is called with the request (e.g. *types.CreateOrderTxReq, nil for CreateSubAccount) before
+	// the default ops are filled and the tx is signed, with opts.Ctx as ctx. Both req and opts may be modified.
+	OnBeforeSign(ctx context.Context, req any, opts *types.TransactOpts) error
+	// OnBeforeSend is called with the signed tx. The tx must not be modified; SendTx rejects it if it changed.
+	OnBeforeSend(ctx context.Context, tx txtypes.TxInfo) error
+	// OnAfterSend is called once the tx was submitted, with the tx hash or the error returned by Lighter.
+	// It is not called when the tx was rejected by an interceptor.
+	OnAfterSend(ctx context.Context, tx txtypes.TxInfo, hash string, err error)
+}
+
+// InterceptorFuncs implements Interceptor with optional funcs. Nil funcs are skipped.
+type InterceptorFuncs struct {
+	BeforeSign func(ctx context.Context, req any, opts *types.TransactOpts) error
+	BeforeSend func(ctx context.Context, tx txtypes.TxInfo) error
+	AfterSend  func(ctx context.Context, tx txtypes.TxInfo, hash string, err error)
+}
+
+var _ Interceptor = (*InterceptorFuncs)(nil)
+
+func (f *InterceptorFuncs) OnBeforeSign(ctx context.Context, req any, opts *types.TransactOpts) error {
+	if f.BeforeSign == nil {
+		return nil
+	}
+	return f.BeforeSign(ctx, req, opts)
+}
+
+func (f *InterceptorFuncs) OnBeforeSend(ctx context.Context, tx txtypes.TxInfo) error {
+	if f.BeforeSend == nil {
+		return nil
+	}
+	return f.BeforeSend(ctx, tx)
+}
+
+func (f *InterceptorFuncs) OnAfterSend(ctx context.Context, tx txtypes.TxInfo, hash string, err error) {
+	if f.AfterSend != nil {
+		f.AfterSend(ctx, tx, hash, err)
+	}
+}
+
+// KillSwitch rejects every transaction while engaged, except order cancellations,
+// so positions can still be unwound. It can be toggled at runtime from any goroutine.
+type KillSwitch struct {
+	engaged atomic.Bool
+}
+
+var _ Interceptor = (*KillSwitch)(nil)
+
+func NewKillSwitch() *KillSwitch {
+	return &KillSwitch{}
+}
+
+func (k *KillSwitch) Engage() {
+	k.engaged.Store(true)
+}
+
+func (k *KillSwitch) Release() {
+	k.engaged.Store(false)
+}
+
+func (k *KillSwitch) Engaged() bool {
+	return k.engaged.Load()
+}
+
+func (k *KillSwitch) OnBeforeSign(_ context.Context, req any, _ *types.TransactOpts) error {
+	switch req.(type) {
+	case *types.CancelOrderTxReq, *types.CancelAllOrdersTxReq:
+		return nil
+	}
+	if k.Engaged() {
+		return ErrKillSwitchEngaged
+	}
+	return nil
+}
+
+func (k *KillSwitch) OnBeforeSend(_ context.Context, tx txtypes.TxInfo) error {
+	switch tx.GetTxType() {
+	case txtypes.TxTypeL2CancelOrder, txtypes.TxTypeL2CancelAllOrders:
+		return nil
+	}
+	if k.Engaged() {
+		return ErrKillSwitchEngaged
+	}
+	return nil
+}
+
+func (k *KillSwitch) OnAfterSend(context.Context, txtypes.TxInfo, string, error) {}
+
+// Use registers interceptors, which run after the ones already registered.
+func (c *TxClient) Use(interceptors ...Interceptor) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+	c.interceptors = append(c.interceptors[:len(c.interceptors):len(c.interceptors)], interceptors...)
+}
+
+func (c *TxClient) getInterceptors() []Interceptor {
+	c.interceptorsMu.RLock()
+	defer c.interceptorsMu.RUnlock()
+	return c.interceptors
+}
+
+// prepareOps runs the OnBeforeSign interceptors for req and fills the default ops.
+// The returned reservation holds the nonce taken from the NonceManager, if any, to release if signing fails.
+func (c *TxClient) prepareOps(req any, ops *types.TransactOpts) (*types.TransactOpts, nonceReservation, error) {
+	if c.closed.Load() {
+		return nil, nonceReservation{}, ErrClientClosed
+	}
+	if ops == nil {
+		ops = new(types.TransactOpts)
+	}
+	ctx := ops.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, interceptor := range c.getInterceptors() {
+		if err := interceptor.OnBeforeSign(ctx, req, ops); err != nil {
+			return nil, nonceReservation{}, fmt.Errorf("%w: %w", ErrInterceptorRejected, err)
+		}
+	}
+	m := c.nonceManager
+	reserved := ops.Nonce == nil && m != nil && !ops.DryRun
+	ops, err := c.FullFillDefaultOps(ops)
+	if err != nil || !reserved {
+		return ops, nonceReservation{}, err
+	}
+	return ops, nonceReservation{m, *ops.FromAccountIndex, *ops.ApiKeyIndex, *ops.Nonce}, nil
+}
+
+// nonceReservation is a nonce handed out by a NonceManager for a tx being signed. The zero value holds none.
+type nonceReservation struct {
+	m            *NonceManager
+	accountIndex int64
+	apiKeyIndex  uint8
+	nonce        int64
+}
+
+// releaseOnError reports the nonce as Failed when *err is set, so the next tx doesn't skip it.
+func (r nonceReservation) releaseOnError(err *error) {
+	if r.m != nil && *err != nil {
+		r.m.Failed(r.accountIndex, r.apiKeyIndex, r.nonce)
+	}
+}
+
+// SendTx submits a tx signed by this client through its HTTPClient, running the send interceptors around it.
+func (c *TxClient) SendTx(ctx context.Context, tx txtypes.TxInfo) (string, error) {
+	return c.SendTxWithOpts(ctx, tx, SendOpts{})
+}
+
+// SendTxWithOpts is SendTx with per-call options.
+// When a SubmissionCache is set and the same tx was already submitted, the cached outcome is returned without sending it again.
+func (c *TxClient) SendTxWithOpts(ctx context.Context, tx txtypes.TxInfo, opts SendOpts) (hash string, err error) {
+	if c.closed.Load() {
+		return "", ErrClientClosed
+	}
+	if c.requester == nil {
+		return "", fmt.Errorf("HTTPClient is nil, can't send tx")
+	}
+	if c.dryRuns.has(tx) {
+		return "", ErrDryRunTx
+	}
+
+	txHash, sendKey := tx.GetTxHash(), c.sendKey(opts)
+	sent := false
+	if c.submissionCache != nil && !opts.BypassCache && txHash != "" {
+		if hash, err, done := c.submissionCache.claim(ctx, txHash, sendKey); done {
+			return hash, err
+		}
+		defer func() { c.submissionCache.finish(txHash, sendKey, hash, err, sent) }()
+	}
+
+	accountIndex, apiKeyIndex, nonce, tracked := TxNonce(tx)
+	tracked = tracked && c.nonceManager != nil
+
+	interceptors := c.getInterceptors()
+	signed, err := txSnapshot(tx)
+	if err != nil {
+		return "", err
+	}
+	for _, interceptor := range interceptors {
+		if err := interceptor.OnBeforeSend(ctx, tx); err != nil {
+			if tracked {
+				c.nonceManager.Failed(accountIndex, apiKeyIndex, nonce)
+			}
+			return "", fmt.Errorf("%w: %w", ErrInterceptorRejected, err)
+		}
+	}
+	if err := c.checkUnmodified(tx, signed); err != nil {
+		if tracked {
+			c.nonceManager.Failed(accountIndex, apiKeyIndex, nonce)
+		}
+		return "", err
+	}
+
+	if tracked {
+		c.nonceManager.Submitted(accountIndex, apiKeyIndex, nonce)
+	}
+
+	hash, err = c.requester.SendRawTxCtx(ctx, tx, opts)
+	sent = true
+
+	if tracked {
+		switch {
+		case err == nil:
+			c.nonceManager.Acked(accountIndex, apiKeyIndex, nonce)
+		case sendNotLanded(err):
+			c.nonceManager.Failed(accountIndex, apiKeyIndex, nonce)
+		}
+		// otherwise the tx may have landed: the nonce stays pending until acknowledged or flagged as stale
+	}
+
+	for _, interceptor := range interceptors {
+		interceptor.OnAfterSend(ctx, tx, hash, err)
+	}
+	return hash, err
+}
+
+// sendKey identifies the options a tx is sent with, for the SubmissionCache: the price protection it gets.
+func (c *TxClient) sendKey(opts SendOpts) string {
+	switch {
+	case opts.PriceProtection != nil:
+		return strconv.FormatBool(*opts.PriceProtection)
+	case c.HTTP() != nil:
+		return strconv.FormatBool(c.HTTP().FatFingerProtection())
+	default:
+		return ""
+	}
+}
+
+// txSnapshot returns the tx as it's sent, signature included, along with the hash it was signed with.
+func txSnapshot(tx txtypes.TxInfo) (string, error) {
+	txInfo, err := tx.GetTxInfo()
+	if err != nil {
+		return "", err
+	}
+	return tx.GetTxHash() + "/" + txInfo, nil
+}
+
+// checkUnmodified verifies that the tx is still the snapshot taken before the send interceptors ran,
+// and that it hashes to the value it was signed with.
+func (c *TxClient) checkUnmodified(tx txtypes.TxInfo, snapshot string) error {
+	current, err := txSnapshot(tx)
+	if err != nil {
+		return err
+	}
+	if current != snapshot {
+		return ErrTxModifiedAfterSign
+	}
+	signedHash := tx.GetTxHash()
+	if signedHash == "" {
+		return nil
+	}
+	msgHash, err := tx.Hash(c.chainId)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(msgHash) != signedHash {
+		return ErrTxModifiedAfterSign
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+type ctxKey struct{}
+
+func TestOnBeforeSignRejects(t *testing.T) {
+	srv := newSendTxServer(t)
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	errNo := errors.New("no")
+	var gotCtx context.Context
+	c.Use(&InterceptorFuncs{BeforeSign: func(ctx context.Context, req any, opts *types.TransactOpts) error {
+		gotCtx = ctx
+		return errNo
+	}})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "caller")
+	nonce := int64(1)
+	_, err := c.GetCreateOrderTransaction(testOrderReq(1), &types.TransactOpts{Ctx: ctx, Nonce: &nonce})
+	if !errors.Is(err, ErrInterceptorRejected) || !errors.Is(err, errNo) {
+		t.Fatalf("got %v, want the rejection", err)
+	}
+	if gotCtx == nil || gotCtx.Value(ctxKey{}) != "caller" {
+		t.Fatal("OnBeforeSign didn't get ops.Ctx")
+	}
+}
+
+func TestOnBeforeSendRejects(t *testing.T) {
+	srv := newSendTxServer(t)
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	tx := signTestOrder(t, c, 1)
+	errNo := errors.New("no")
+	afterSends := 0
+	c.Use(&InterceptorFuncs{
+		BeforeSend: func(context.Context, txtypes.TxInfo) error { return errNo },
+		AfterSend:  func(context.Context, txtypes.TxInfo, string, error) { afterSends++ },
+	})
+
+	if _, err := c.SendTx(context.Background(), tx); !errors.Is(err, ErrInterceptorRejected) || !errors.Is(err, errNo) {
+		t.Fatalf("got %v, want the rejection", err)
+	}
+	if posts := len(srv.posts()); posts != 0 {
+		t.Fatalf("rejected tx sent %v times", posts)
+	}
+	if afterSends != 0 {
+		t.Fatal("OnAfterSend called for a rejected tx")
+	}
+}
+
+func TestSendRejectsTxModifiedByInterceptor(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(tx *txtypes.L2CreateOrderTxInfo)
+	}{
+		{"body", func(tx *txtypes.L2CreateOrderTxInfo) { tx.Price++ }},
+		{"signature", func(tx *txtypes.L2CreateOrderTxInfo) { tx.Sig[0] ^= 1 }},
+		{"signed hash", func(tx *txtypes.L2CreateOrderTxInfo) { tx.SignedHash = "" }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newSendTxServer(t)
+			c := newTestTxClient(t, NewHTTPClient(srv.URL))
+			tx := signTestOrder(t, c, 1)
+			c.Use(&InterceptorFuncs{BeforeSend: func(_ context.Context, sent txtypes.TxInfo) error {
+				tc.modify(sent.(*txtypes.L2CreateOrderTxInfo))
+				return nil
+			}})
+
+			if _, err := c.SendTx(context.Background(), tx); !errors.Is(err, ErrTxModifiedAfterSign) {
+				t.Fatalf("got %v, want ErrTxModifiedAfterSign", err)
+			}
+			if posts := len(srv.posts()); posts != 0 {
+				t.Fatalf("modified tx sent %v times", posts)
+			}
+		})
+	}
+}
+
+func TestOnAfterSend(t *testing.T) {
+	srv := newSendTxServer(t)
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	type afterSend struct {
+		tx   txtypes.TxInfo
+		hash string
+		err  error
+	}
+	var calls []afterSend
+	c.Use(&InterceptorFuncs{AfterSend: func(_ context.Context, tx txtypes.TxInfo, hash string, err error) {
+		calls = append(calls, afterSend{tx, hash, err})
+	}})
+
+	tx := signTestOrder(t, c, 1)
+	if _, err := c.SendTx(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0].tx != tx || calls[0].hash != "hash" || calls[0].err != nil {
+		t.Fatalf("after a success: %+v", calls)
+	}
+
+	srv.respond = func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":21104,"message":"invalid nonce"}`)
+	}
+	if _, err := c.SendTx(context.Background(), signTestOrder(t, c, 2)); err == nil {
+		t.Fatal("rejected tx succeeded")
+	}
+	var apiErr *APIError
+	if len(calls) != 2 || !errors.As(calls[1].err, &apiErr) || apiErr.Code != CodeInvalidNonce {
+		t.Fatalf("after a failure: %+v", calls)
+	}
+}
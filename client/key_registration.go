@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The stages of EnsureKeyRegistered fail with distinct errors.
+var (
+	ErrKeyMismatch            = errors.New("api key is not registered on Lighter")
+	ErrL1SignatureRejected    = errors.New("L1 signature rejected")
+	ErrKeyRegistrationSubmit  = errors.New("failed to submit the ChangePubKey tx")
+	ErrKeyRegistrationTimeout = errors.New("timed out waiting for the api key to be registered")
+)
+
+// DefaultKeyRegistrationPollInterval is how often SubmitKeyRegistration checks whether the key is live.
+const DefaultKeyRegistrationPollInterval = 2 * time.Second
+
+// L1Signer signs a message with the L1 wallet owning the account, like personal_sign, and returns the 0x-hex signature.
+type L1Signer func(messageToSign string) (string, error)
+
+// CheckKeyRegistered compares the public key of the client with the one registered on Lighter for its
+// account and api key index, like the CheckClient export. It fails with ErrKeyMismatch when they differ
+// or when no key is registered.
+func (c *TxClient) CheckKeyRegistered() error {
+	if c.requester == nil {
+		return fmt.Errorf("HTTPClient is nil, can't check the api key")
+	}
+	serverKeys, err := c.requester.GetApiKeyCtx(context.Background(), c.accountIndex, c.apiKeyIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get Api Keys. err: %w", err)
+	}
+
+	pubKeyStr := keys.FromKeyManager(c.keyManager).APIHex()
+	for _, ak := range serverKeys.ApiKeys {
+		if ak.ApiKeyIndex != c.apiKeyIndex {
+			continue
+		}
+		if ak.PublicKey != pubKeyStr {
+			return fmt.Errorf("%w: ownPubKey: %s registered: %s", ErrKeyMismatch, pubKeyStr, ak.PublicKey)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: no key at api key index %v", ErrKeyMismatch, c.apiKeyIndex)
+}
+
+// GetKeyRegistrationTransaction signs a ChangePubKey tx registering the client's own key. It still needs the
+// L1 signature of its GetL1SignatureBody before being submitted with SubmitKeyRegistration.
+func (c *TxClient) GetKeyRegistrationTransaction(ops *types.TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
+	return c.GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: c.keyManager.PubKeyBytes()}, ops)
+}
+
+// SubmitKeyRegistration attaches the L1 signature to a ChangePubKey tx of the client, submits it and polls every
+// pollInterval until the key is live or ctx is done. The signature is checked first: it must come from an L1
+// address owning the account of the tx.
+func (c *TxClient) SubmitKeyRegistration(ctx context.Context, tx *txtypes.L2ChangePubKeyTxInfo, l1Sig string, pollInterval time.Duration) (string, error) {
+	if tx.AccountIndex != c.accountIndex || tx.ApiKeyIndex != c.apiKeyIndex {
+		return "", fmt.Errorf("ChangePubKey tx is for account %v api key %v, not for this client", tx.AccountIndex, tx.ApiKeyIndex)
+	}
+	pubKey := c.keyManager.PubKeyBytes()
+	if !slices.Equal(tx.PubKey, pubKey[:]) {
+		return "", fmt.Errorf("ChangePubKey tx doesn't register the key of this client")
+	}
+	if err := c.CheckL1Signature(tx.GetL1SignatureBody(), l1Sig); err != nil {
+		return "", err
+	}
+	tx.L1Sig = l1Sig
+
+	hash, err := c.SendTx(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrKeyRegistrationSubmit, err)
+	}
+
+	if pollInterval <= 0 {
+		pollInterval = DefaultKeyRegistrationPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		err = c.CheckKeyRegistered()
+		if err == nil {
+			return hash, nil
+		}
+		select {
+		case <-ctx.Done():
+			return hash, fmt.Errorf("%w: tx %v, last check: %w", ErrKeyRegistrationTimeout, hash, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnsureKeyRegistered registers the client's key on Lighter when CheckKeyRegistered reports a mismatch:
+// it signs a ChangePubKey tx, asks signL1 for the L1 signature of its message and submits it with
+// SubmitKeyRegistration. It does nothing when the key is already registered.
+func (c *TxClient) EnsureKeyRegistered(ctx context.Context, signL1 L1Signer, pollInterval time.Duration) error {
+	err := c.CheckKeyRegistered()
+	if err == nil || !errors.Is(err, ErrKeyMismatch) {
+		return err
+	}
+
+	tx, err := c.GetKeyRegistrationTransaction(nil)
+	if err != nil {
+		return err
+	}
+	l1Sig, err := signL1(tx.GetL1SignatureBody())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrL1SignatureRejected, err)
+	}
+	_, err = c.SubmitKeyRegistration(ctx, tx, l1Sig, pollInterval)
+	return err
+}
+
+// RecoverL1Signer returns the address which signed the message, personal_sign style, with a 65 bytes 0x-hex
+// signature. The recovery id v may be 0/1 or 27/28. It fails with ErrL1SignatureRejected for a malformed signature.
+func RecoverL1Signer(message, l1Sig string) (string, error) {
+	sig, err := hexutil.Decode(l1Sig)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid hex. err: %w", ErrL1SignatureRejected, err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("%w: expected %v bytes, got %v", ErrL1SignatureRejected, crypto.SignatureLength, len(sig))
+	}
+	sig = slices.Clone(sig)
+	switch v := sig[crypto.RecoveryIDOffset]; v {
+	case 0, 1:
+	case 27, 28:
+		sig[crypto.RecoveryIDOffset] -= 27
+	default:
+		return "", fmt.Errorf("%w: invalid recovery id %v", ErrL1SignatureRejected, v)
+	}
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrL1SignatureRejected, err)
+	}
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
+
+// CheckL1Signature checks the L1 signature of a message, as returned by RecoverL1Signer. When the client is
+// online, the signer must also own the account of the client; offline, only the signature itself is checked.
+func (c *TxClient) CheckL1Signature(message, l1Sig string) error {
+	signer, err := RecoverL1Signer(message, l1Sig)
+	if err != nil {
+		return err
+	}
+	if c.requester == nil {
+		return nil
+	}
+
+	owned, err := c.requester.AccountIndexes(signer)
+	if err != nil {
+		return fmt.Errorf("failed to get the accounts of %v. err: %w", signer, err)
+	}
+	if !slices.Contains(owned, c.accountIndex) {
+		return fmt.Errorf("%w: signed by %v, which doesn't own account %v", ErrL1SignatureRejected, signer, c.accountIndex)
+	}
+	return nil
+}
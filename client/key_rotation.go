@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// KeyRotation is a pending api key rotation, returned by RotateApiKey: a new key and the ChangePubKey tx
+// registering it in place of the client's key. The tx isn't sent yet.
+type KeyRotation struct {
+	KeyManager signer.KeyManager
+	Tx         *txtypes.L2ChangePubKeyTxInfo
+}
+
+// RotateApiKey generates a new key and signs the ChangePubKey tx registering it at the client's api key index.
+// Nothing is sent: store the new private key first, then get the L1 signature of Tx.GetL1SignatureBody() and
+// pass it to ConfirmRotation. Until then the client keeps using its current key.
+// As required by the protocol, the tx is signed by the new key.
+func (c *TxClient) RotateApiKey(ops *types.TransactOpts) (*KeyRotation, error) {
+	keyManager, err := keys.Generate()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.withKeyManager(keyManager).GetKeyRegistrationTransaction(ops)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyRotation{KeyManager: keyManager, Tx: tx}, nil
+}
+
+// ConfirmRotation submits the ChangePubKey tx of a rotation with its L1 signature, and waits until the new key is
+// live, like SubmitKeyRegistration. It returns a client using the new key, with the HTTPClient, interceptors,
+// NonceManager, SubmissionCache and defaults of c; c itself is left unchanged.
+func (c *TxClient) ConfirmRotation(ctx context.Context, rotation *KeyRotation, l1Sig string) (*TxClient, string, error) {
+	if rotation == nil || rotation.KeyManager == nil || rotation.Tx == nil {
+		return nil, "", fmt.Errorf("no key rotation to confirm")
+	}
+	rotated := c.withKeyManager(rotation.KeyManager)
+	hash, err := rotated.SubmitKeyRegistration(ctx, rotation.Tx, l1Sig, DefaultKeyRegistrationPollInterval)
+	if err != nil {
+		return nil, hash, err
+	}
+	return rotated, hash, nil
+}
+
+// withKeyManager returns a copy of the client signing with another key.
+func (c *TxClient) withKeyManager(keyManager signer.KeyManager) *TxClient {
+	rotated := NewTxClientWithKeyManager(c.requester, keyManager, c.accountIndex, c.apiKeyIndex, c.chainId)
+	rotated.Use(c.getInterceptors()...)
+	rotated.nonceManager = c.nonceManager
+	rotated.submissionCache = c.submissionCache
+	rotated.defaults = c.Defaults()
+	return rotated
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// minMaintenanceRetry is how long to wait before retrying when a maintenance response doesn't announce its end.
+const minMaintenanceRetry = time.Minute
+
+var ErrMaintenance = errors.New("lighter is under maintenance")
+
+// MaintenanceError is returned instead of a plain status error when Lighter answers 503 because of scheduled
+// maintenance. Start and End are zero when the response doesn't announce the window.
+// It matches ErrMaintenance with errors.Is.
+type MaintenanceError struct {
+	Message string
+	Start   time.Time
+	End     time.Time
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := ErrMaintenance.Error()
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if !e.End.IsZero() {
+		msg += fmt.Sprintf(" (until %v)", e.End.UTC().Format(time.RFC3339))
+	}
+	return msg
+}
+
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenance
+}
+
+// RetryAfter returns how long to wait before trying again: until the announced end of the window,
+// and at least a minute.
+func (e *MaintenanceError) RetryAfter(now time.Time) time.Duration {
+	if e.End.IsZero() {
+		return minMaintenanceRetry
+	}
+	return max(e.End.Sub(now), minMaintenanceRetry)
+}
+
+// maintenanceBody is the body of a 503 during scheduled maintenance. The timestamps are in unix seconds.
+type maintenanceBody struct {
+	ResultCode
+	Maintenance *struct {
+		Start int64 `json:"start_timestamp"`
+		End   int64 `json:"end_timestamp"`
+	} `json:"maintenance"`
+}
+
+// OnMaintenance registers a callback invoked every time a request hits a maintenance response,
+// e.g. to pause strategies until the window ends.
+func (c *HTTPClient) OnMaintenance(fn func(*MaintenanceError)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onMaintenance = fn
+}
+
+// statusError builds the error of a non-200 response, recognizing maintenance responses, rate limits
+// and server errors.
+func (c *HTTPClient) statusError(resp *http.Response, body []byte) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), Body: string(body)}
+	case resp.StatusCode < http.StatusInternalServerError:
+		return newStatusAPIError(resp.StatusCode, body)
+	}
+	var me *MaintenanceError
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		me = parseMaintenance(resp, body)
+	}
+	if me == nil {
+		return &ServerError{Status: resp.StatusCode, Body: string(body)}
+	}
+	c.mu.RLock()
+	onMaintenance := c.onMaintenance
+	c.mu.RUnlock()
+	if onMaintenance != nil {
+		onMaintenance(me)
+	}
+	return me
+}
+
+// parseMaintenance returns nil when the 503 isn't a maintenance response, i.e. its body has neither
+// a maintenance window nor a message mentioning maintenance.
+func parseMaintenance(resp *http.Response, body []byte) *MaintenanceError {
+	var mb maintenanceBody
+	if err := json.Unmarshal(body, &mb); err != nil {
+		return nil
+	}
+	if mb.Maintenance == nil && !strings.Contains(strings.ToLower(mb.Message), "maintenance") {
+		return nil
+	}
+
+	me := &MaintenanceError{Message: mb.Message}
+	if mb.Maintenance != nil {
+		if mb.Maintenance.Start > 0 {
+			me.Start = time.Unix(mb.Maintenance.Start, 0)
+		}
+		if mb.Maintenance.End > 0 {
+			me.End = time.Unix(mb.Maintenance.End, 0)
+		}
+	}
+	if me.End.IsZero() {
+		now := time.Now()
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now); retryAfter > 0 {
+			me.End = now.Add(retryAfter)
+		}
+	}
+	return me
+}
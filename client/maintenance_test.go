@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceResponse(t *testing.T) {
+	start, end := time.Now().Add(-time.Minute).Unix(), time.Now().Add(time.Hour).Unix()
+	body := fmt.Sprintf(`{"code":503,"message":"scheduled maintenance","maintenance":{"start_timestamp":%v,"end_timestamp":%v}}`, start, end)
+
+	err := getNonceFrom(t, http.StatusServiceUnavailable, "", body)
+	var me *MaintenanceError
+	if !errors.As(err, &me) || !errors.Is(err, ErrMaintenance) || errors.Is(err, ErrServer) {
+		t.Fatalf("got %v, want a MaintenanceError", err)
+	}
+	if me.Message != "scheduled maintenance" || me.Start.Unix() != start || me.End.Unix() != end {
+		t.Fatalf("got %+v", me)
+	}
+	if d := me.RetryAfter(time.Unix(end, 0).Add(-10 * time.Minute)); d != 10*time.Minute {
+		t.Fatalf("RetryAfter %v, want until the end", d)
+	}
+	if d := me.RetryAfter(time.Unix(end, 0)); d != minMaintenanceRetry {
+		t.Fatalf("RetryAfter %v at the end, want the minimum", d)
+	}
+}
+
+func TestMaintenanceMessageWithRetryAfter(t *testing.T) {
+	err := getNonceFrom(t, http.StatusServiceUnavailable, "120", `{"code":503,"message":"Under maintenance"}`)
+	var me *MaintenanceError
+	if !errors.As(err, &me) {
+		t.Fatalf("got %v, want a MaintenanceError", err)
+	}
+	if until := time.Until(me.End); !me.Start.IsZero() || until <= 110*time.Second || until > 120*time.Second {
+		t.Fatalf("got %+v, want an end from the Retry-After", me)
+	}
+}
+
+func TestPlain503IsServerError(t *testing.T) {
+	for _, body := range []string{`service unavailable`, `{"code":503,"message":"upstream unavailable"}`} {
+		err := getNonceFrom(t, http.StatusServiceUnavailable, "", body)
+		var serverErr *ServerError
+		if !errors.As(err, &serverErr) || errors.Is(err, ErrMaintenance) {
+			t.Fatalf("%v: got %v, want a ServerError", body, err)
+		}
+	}
+}
+
+func TestOnMaintenance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"code":503,"message":"under maintenance"}`)
+	}))
+	defer srv.Close()
+	c := NewHTTPClient(srv.URL)
+	var got []*MaintenanceError
+	c.OnMaintenance(func(me *MaintenanceError) { got = append(got, me) })
+
+	_, err := c.GetNextNonce(1, 0)
+	if len(got) != 1 || !errors.Is(err, got[0]) {
+		t.Fatalf("callback got %v for %v", got, err)
+	}
+}
+
+func TestRetryMaintenance(t *testing.T) {
+	maintenance := step{status: http.StatusServiceUnavailable, body: `{"code":503,"message":"under maintenance"}`}
+
+	requester, transport, clock := newRetryRequester(RetryPolicy{RetryMaintenance: true}, maintenance, nonceStep)
+	if _, err := requester.GetNextNonceCtx(context.Background(), 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	// no announced end: the minimum, far above the backoff
+	if want := []time.Duration{minMaintenanceRetry}; !reflect.DeepEqual(clock.slept, want) || transport.callCount() != 2 {
+		t.Fatalf("slept %v in %v calls, want %v", clock.slept, transport.callCount(), want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	requester, transport, clock = newRetryRequester(RetryPolicy{RetryMaintenance: true}, maintenance, nonceStep)
+	if _, err := requester.GetNextNonceCtx(ctx, 1, 0); !errors.Is(err, ErrMaintenance) {
+		t.Fatalf("got %v, want no retry past the deadline", err)
+	}
+	if transport.callCount() != 1 || len(clock.slept) != 0 {
+		t.Fatalf("slept %v in %v calls", clock.slept, transport.callCount())
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var ErrMarginFractionOutOfRange = errors.New("initial margin fraction out of the market range")
+
+// MarginFractionRangeError is returned by CheckInitialMarginFraction when the market doesn't allow the fraction.
+// Min and Max are the allowed range, inclusive. It matches ErrMarginFractionOutOfRange with errors.Is.
+type MarginFractionRangeError struct {
+	MarketIndex           uint8
+	InitialMarginFraction uint16
+	Min                   uint16
+	Max                   uint16
+}
+
+func (e *MarginFractionRangeError) Error() string {
+	return fmt.Sprintf("%v: market %v allows an initial margin fraction in [%v, %v], got %v",
+		ErrMarginFractionOutOfRange.Error(), e.MarketIndex, e.Min, e.Max, e.InitialMarginFraction)
+}
+
+func (e *MarginFractionRangeError) Is(target error) bool {
+	return target == ErrMarginFractionOutOfRange
+}
+
+// CheckInitialMarginFraction checks an UpdateLeverage initial margin fraction against the range the market allows,
+// from its minimum initial margin fraction (the max leverage) to txtypes.MarginFractionTick (1x leverage).
+// It fails with ErrUnknownMarket when the market doesn't exist.
+func (c *HTTPClient) CheckInitialMarginFraction(marketIndex uint8, initialMarginFraction uint16) error {
+	details, err := c.GetOrderBookDetails(marketIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get market details. err: %w", err)
+	}
+	for _, detail := range details.OrderBookDetails {
+		if detail.MarketId != marketIndex {
+			continue
+		}
+		rangeErr := &MarginFractionRangeError{
+			MarketIndex:           marketIndex,
+			InitialMarginFraction: initialMarginFraction,
+			Min:                   detail.MinInitialMarginFraction,
+			Max:                   uint16(txtypes.MarginFractionTick),
+		}
+		if initialMarginFraction < rangeErr.Min || initialMarginFraction > rangeErr.Max {
+			return rangeErr
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrUnknownMarket, marketIndex)
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+)
+
+const defaultUnreachableAfter = 3
+
+type NetworkErrorKind int
+
+const (
+	NetworkErrorOther NetworkErrorKind = iota
+	NetworkErrorDNSFailure
+	NetworkErrorConnRefused
+	NetworkErrorTLS
+	NetworkErrorTimeout
+)
+
+func (k NetworkErrorKind) String() string {
+	switch k {
+	case NetworkErrorDNSFailure:
+		return "dns_failure"
+	case NetworkErrorConnRefused:
+		return "conn_refused"
+	case NetworkErrorTLS:
+		return "tls_error"
+	case NetworkErrorTimeout:
+		return "timeout"
+	default:
+		return "other"
+	}
+}
+
+// NetworkError is returned when a request didn't get any answer from Lighter.
+type NetworkError struct {
+	Kind NetworkErrorKind
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error (%v): %v", e.Kind, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// classifyNetworkError wraps a transport error of http.Client.Do into a NetworkError.
+// Context cancellation is left as is, since it's not a network failure.
+func classifyNetworkError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	kind := NetworkErrorOther
+	var (
+		dnsErr  *net.DNSError
+		certErr *tls.CertificateVerificationError
+		recErr  tls.RecordHeaderError
+		unknown x509.UnknownAuthorityError
+		hostErr x509.HostnameError
+		netErr  net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		kind = NetworkErrorDNSFailure
+	case errors.Is(err, syscall.ECONNREFUSED):
+		kind = NetworkErrorConnRefused
+	case errors.As(err, &certErr), errors.As(err, &recErr), errors.As(err, &unknown), errors.As(err, &hostErr):
+		kind = NetworkErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		kind = NetworkErrorTimeout
+	}
+	return &NetworkError{Kind: kind, Err: err}
+}
+
+// health tracks whether Lighter is reachable. It's shared by an HTTPClient and its copies.
+type health struct {
+	mu               sync.Mutex
+	unreachableAfter int
+	failures         int
+	unreachable      bool
+	onChange         func(reachable bool, err error)
+}
+
+// OnHealthChange registers a callback invoked when Lighter becomes unreachable, after n consecutive
+// requests failed with a NetworkError (3 if n < 1), and when it's reachable again, on the first request
+// getting an answer. Transitions are reported once; the callback is called synchronously.
+func (c *HTTPClient) OnHealthChange(n int, fn func(reachable bool, err error)) {
+	if n < 1 {
+		n = defaultUnreachableAfter
+	}
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	c.health.unreachableAfter = n
+	c.health.onChange = fn
+}
+
+// Reachable reports whether the last requests got an answer, as tracked for OnHealthChange.
+func (c *HTTPClient) Reachable() bool {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	return !c.health.unreachable
+}
+
+// record updates the health with the outcome of http.Client.Do.
+func (h *health) record(err error) {
+	var ne *NetworkError
+	if err != nil && !errors.As(err, &ne) {
+		return
+	}
+
+	h.mu.Lock()
+	var notify func(bool, error)
+	if err == nil {
+		h.failures = 0
+		if h.unreachable {
+			h.unreachable = false
+			notify = h.onChange
+		}
+	} else {
+		h.failures++
+		if !h.unreachable && h.failures >= max(h.unreachableAfter, 1) {
+			h.unreachable = true
+			notify = h.onChange
+		}
+	}
+	h.mu.Unlock()
+
+	if notify != nil {
+		notify(err == nil, err)
+	}
+}
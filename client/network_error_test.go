@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// closedAddr returns the address of a local port nothing listens on anymore.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+// serveConns runs a local listener handling every connection with handle.
+func serveConns(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func networkErrorOf(t *testing.T, c *HTTPClient) *NetworkError {
+	t.Helper()
+	_, err := c.GetNextNonce(1, 0)
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("got %v, want a NetworkError", err)
+	}
+	return netErr
+}
+
+func TestNetworkErrorKinds(t *testing.T) {
+	refused := closedAddr(t)
+	silent := serveConns(t, func(conn net.Conn) {
+		time.Sleep(time.Second)
+		conn.Close()
+	})
+	notTLS := serveConns(t, func(conn net.Conn) {
+		conn.Write([]byte("definitely not a TLS record"))
+		conn.Close()
+	})
+
+	for _, tc := range []struct {
+		name  string
+		setup func() *HTTPClient
+		want  NetworkErrorKind
+	}{
+		{"refused", func() *HTTPClient { return NewHTTPClient("http://" + refused) }, NetworkErrorConnRefused},
+		{"dns", func() *HTTPClient {
+			// the resolver's server is unreachable, so no name resolves
+			c := NewHTTPClient("http://lighter.test")
+			resolver := &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "tcp", refused)
+			}}
+			transport := c.httpClient.Transport.(*http.Transport).Clone()
+			transport.DialContext = (&net.Dialer{Resolver: resolver}).DialContext
+			c.httpClient.Transport = transport
+			return c
+		}, NetworkErrorDNSFailure},
+		{"timeout", func() *HTTPClient {
+			c := NewHTTPClient("http://" + silent)
+			c.SetTimeout(100 * time.Millisecond)
+			return c
+		}, NetworkErrorTimeout},
+		{"tls", func() *HTTPClient { return NewHTTPClient("https://" + notTLS) }, NetworkErrorTLS},
+	} {
+		if got := networkErrorOf(t, tc.setup()); got.Kind != tc.want {
+			t.Errorf("%v: got %v (%v), want %v", tc.name, got.Kind, got.Err, tc.want)
+		}
+	}
+}
+
+func TestHealthChanges(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":200,"network_id":1,"timestamp":1}`)
+	}))
+	defer srv.Close()
+
+	c := NewHTTPClient("http://" + closedAddr(t))
+	type change struct {
+		reachable bool
+		kind      NetworkErrorKind
+	}
+	var changes []change
+	c.OnHealthChange(2, func(reachable bool, err error) {
+		ch := change{reachable: reachable}
+		var netErr *NetworkError
+		if errors.As(err, &netErr) {
+			ch.kind = netErr.Kind
+		}
+		changes = append(changes, ch)
+	})
+
+	networkErrorOf(t, c)
+	if len(changes) != 0 || !c.Reachable() {
+		t.Fatalf("unreachable after 1 failure: %+v", changes)
+	}
+	networkErrorOf(t, c)
+	networkErrorOf(t, c)
+	if want := []change{{false, NetworkErrorConnRefused}}; len(changes) != 1 || changes[0] != want[0] || c.Reachable() {
+		t.Fatalf("changes %+v after 3 failures, want %+v", changes, want)
+	}
+
+	// the server is back: the first answer reports it
+	if err := c.SetEndpoint(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[1] != (change{reachable: true}) || !c.Reachable() {
+		t.Fatalf("changes %+v after the probe", changes)
+	}
+}
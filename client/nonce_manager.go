@@ -0,0 +1,441 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	defaultNonceStaleAfter = 30 * time.Second
+	nonceGapChanSize       = 16
+)
+
+// NonceFetcher returns the next nonce the server expects. HTTPClient.GetNextNonceCtx satisfies it.
+type NonceFetcher func(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error)
+
+// ErrNoncesOutstanding is returned by RecoverGap while nonces handed out by Next were neither submitted nor failed,
+// since their txs could be sent with a nonce the recovery reassigns.
+var ErrNoncesOutstanding = errors.New("nonces are handed out but not submitted yet")
+
+type NonceGapReason string
+
+const (
+	NonceGapOutOfOrderAck NonceGapReason = "out_of_order_ack"
+	NonceGapStale         NonceGapReason = "stale"
+	NonceGapSendFailed    NonceGapReason = "send_failed"
+)
+
+// NonceGap reports a nonce that was probably lost, which makes every later nonce of the same API key fail.
+type NonceGap struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64
+	Reason       NonceGapReason
+	DetectedAt   time.Time
+}
+
+type PendingNonce struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64
+	SubmittedAt  time.Time
+}
+
+type nonceKey struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+}
+
+// NonceManager hands out nonces locally, so a tx doesn't need a nonce round trip, and tracks the ones
+// that were submitted but not yet acknowledged to detect gaps.
+// A gap is flagged when a later nonce is acknowledged while an earlier one is still pending,
+// when a pending nonce ages past the stale threshold, or when a send fails while later nonces are already handed out.
+type NonceManager struct {
+	fetch      NonceFetcher
+	staleAfter time.Duration
+	now        func() time.Time
+
+	mu        sync.Mutex
+	next      map[nonceKey]int64
+	handedOut map[nonceKey]map[int64]struct{} // returned by Next, not yet Submitted nor Failed
+	pending   map[nonceKey]map[int64]time.Time
+	flagged   map[nonceKey]map[int64]struct{}
+	onGap     func(NonceGap)
+	gaps      chan NonceGap
+}
+
+// NewNonceManager uses staleAfter as the age after which an unacknowledged nonce is flagged; 0 uses the default of 30 seconds.
+func NewNonceManager(fetch NonceFetcher, staleAfter time.Duration) *NonceManager {
+	if staleAfter <= 0 {
+		staleAfter = defaultNonceStaleAfter
+	}
+	return &NonceManager{
+		fetch:      fetch,
+		staleAfter: staleAfter,
+		now:        time.Now,
+		next:       make(map[nonceKey]int64),
+		handedOut:  make(map[nonceKey]map[int64]struct{}),
+		pending:    make(map[nonceKey]map[int64]time.Time),
+		flagged:    make(map[nonceKey]map[int64]struct{}),
+		gaps:       make(chan NonceGap, nonceGapChanSize),
+	}
+}
+
+// OnGap sets a callback invoked for every detected gap. It's called without holding the manager's lock.
+func (m *NonceManager) OnGap(fn func(NonceGap)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onGap = fn
+}
+
+// Gaps returns a channel receiving the detected gaps. Gaps are dropped when nobody drains it.
+func (m *NonceManager) Gaps() <-chan NonceGap {
+	return m.gaps
+}
+
+// Next returns the next nonce to use, fetching it from the server the first time an API key is seen.
+// The nonce is outstanding until it's reported with Submitted or Failed; call Failed for a nonce which won't be sent.
+func (m *NonceManager) Next(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return m.NextCtx(context.Background(), accountIndex, apiKeyIndex)
+}
+
+// NextCtx is Next bounded by ctx.
+func (m *NonceManager) NextCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+
+	m.mu.Lock()
+	if _, ok := m.next[key]; ok {
+		defer m.mu.Unlock()
+		return m.handOutLocked(key), nil
+	}
+	m.mu.Unlock()
+
+	fetched, err := m.fetch(ctx, accountIndex, apiKeyIndex)
+	if err != nil {
+		return -1, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// another goroutine might have fetched it in the meantime
+	if _, ok := m.next[key]; !ok {
+		m.next[key] = fetched
+	}
+	return m.handOutLocked(key), nil
+}
+
+// Peek returns the nonce Next would hand out, without handing it out, e.g. for a tx that won't be sent.
+func (m *NonceManager) Peek(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+
+	m.mu.Lock()
+	nonce, ok := m.next[key]
+	m.mu.Unlock()
+	if ok {
+		return nonce, nil
+	}
+
+	fetched, err := m.fetch(ctx, accountIndex, apiKeyIndex)
+	if err != nil {
+		return -1, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.next[key]; !ok {
+		m.next[key] = fetched
+	}
+	return m.next[key], nil
+}
+
+// handOutLocked returns the next nonce of key and records it as handed out. Requires m.mu.
+func (m *NonceManager) handOutLocked(key nonceKey) int64 {
+	nonce := m.next[key]
+	m.next[key] = nonce + 1
+	if m.handedOut[key] == nil {
+		m.handedOut[key] = make(map[int64]struct{})
+	}
+	m.handedOut[key][nonce] = struct{}{}
+	return nonce
+}
+
+// Submitted marks a nonce as sent and waiting for acknowledgement.
+func (m *NonceManager) Submitted(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.handedOut[key], nonce)
+	if m.pending[key] == nil {
+		m.pending[key] = make(map[int64]time.Time)
+	}
+	m.pending[key][nonce] = m.now()
+}
+
+// Acked marks a nonce as accepted by the server. Earlier nonces still pending are flagged as a gap.
+func (m *NonceManager) Acked(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+
+	m.mu.Lock()
+	delete(m.pending[key], nonce)
+	var gaps []NonceGap
+	for pendingNonce := range m.pending[key] {
+		if pendingNonce < nonce {
+			gaps = append(gaps, m.flagLocked(key, pendingNonce, NonceGapOutOfOrderAck)...)
+		}
+	}
+	m.mu.Unlock()
+
+	m.emit(gaps)
+}
+
+// Failed marks a nonce which was not consumed by the server: its tx was rejected or never sent.
+// If it was the last handed out nonce it's reused, otherwise it's flagged as a gap. Don't call it when the tx may
+// have landed, e.g. after a timeout: leave it pending, so it's acknowledged later or flagged as stale.
+func (m *NonceManager) Failed(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+
+	m.mu.Lock()
+	delete(m.handedOut[key], nonce)
+	delete(m.pending[key], nonce)
+	var gaps []NonceGap
+	if m.next[key] == nonce+1 {
+		m.next[key] = nonce
+	} else {
+		gaps = m.flagLocked(key, nonce, NonceGapSendFailed)
+	}
+	m.mu.Unlock()
+
+	m.emit(gaps)
+}
+
+// Check flags the pending nonces older than the stale threshold and returns all newly detected gaps.
+func (m *NonceManager) Check() []NonceGap {
+	now := m.now()
+
+	m.mu.Lock()
+	var gaps []NonceGap
+	for key, nonces := range m.pending {
+		for nonce, submittedAt := range nonces {
+			if now.Sub(submittedAt) > m.staleAfter {
+				gaps = append(gaps, m.flagLocked(key, nonce, NonceGapStale)...)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	m.emit(gaps)
+	return gaps
+}
+
+// Watch calls Check every interval until ctx is done.
+func (m *NonceManager) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Pending returns the submitted but unacknowledged nonces, sorted by account, API key and nonce.
+func (m *NonceManager) Pending() []PendingNonce {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]PendingNonce, 0)
+	for key, nonces := range m.pending {
+		for nonce, submittedAt := range nonces {
+			res = append(res, PendingNonce{
+				AccountIndex: key.accountIndex,
+				ApiKeyIndex:  key.apiKeyIndex,
+				Nonce:        nonce,
+				SubmittedAt:  submittedAt,
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].AccountIndex != res[j].AccountIndex {
+			return res[i].AccountIndex < res[j].AccountIndex
+		}
+		if res[i].ApiKeyIndex != res[j].ApiKeyIndex {
+			return res[i].ApiKeyIndex < res[j].ApiKeyIndex
+		}
+		return res[i].Nonce < res[j].Nonce
+	})
+	return res
+}
+
+// RecoverGap resyncs the next nonce of the API key with the server, then calls resign, in nonce order,
+// for every pending nonce the server didn't consume, with the new nonce to re-sign its payload with.
+// Pending nonces below the server's next nonce were consumed and are dropped.
+// The caller is expected to re-sign and re-submit the payload, reporting it with Submitted/Acked as usual.
+// It fails with ErrNoncesOutstanding while nonces of the API key were handed out by Next but not yet submitted.
+func (m *NonceManager) RecoverGap(ctx context.Context, accountIndex int64, apiKeyIndex uint8, resign func(ctx context.Context, oldNonce, newNonce int64) error) error {
+	key := nonceKey{accountIndex, apiKeyIndex}
+
+	if err := m.checkNoneOutstanding(key); err != nil {
+		return err
+	}
+	serverNonce, err := m.fetch(ctx, accountIndex, apiKeyIndex)
+	if err != nil {
+		return fmt.Errorf("failed to resync nonce. err: %w", err)
+	}
+
+	m.mu.Lock()
+	if err := m.checkNoneOutstandingLocked(key); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	affected := make([]int64, 0)
+	for nonce := range m.pending[key] {
+		if nonce >= serverNonce {
+			affected = append(affected, nonce)
+		}
+	}
+	sort.Slice(affected, func(i, j int) bool { return affected[i] < affected[j] })
+	delete(m.pending, key)
+	delete(m.flagged, key)
+	m.next[key] = serverNonce + int64(len(affected))
+	m.mu.Unlock()
+
+	for i, oldNonce := range affected {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := resign(ctx, oldNonce, serverNonce+int64(i)); err != nil {
+			return fmt.Errorf("failed to re-sign payload with nonce %v. err: %w", oldNonce, err)
+		}
+	}
+	return nil
+}
+
+func (m *NonceManager) checkNoneOutstanding(key nonceKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkNoneOutstandingLocked(key)
+}
+
+// checkNoneOutstandingLocked fails with ErrNoncesOutstanding when nonces of key are handed out. Requires m.mu.
+func (m *NonceManager) checkNoneOutstandingLocked(key nonceKey) error {
+	if len(m.handedOut[key]) == 0 {
+		return nil
+	}
+	nonces := make([]int64, 0, len(m.handedOut[key]))
+	for nonce := range m.handedOut[key] {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	return fmt.Errorf("%w: %v", ErrNoncesOutstanding, nonces)
+}
+
+// Reset forgets everything known about the API key; its next nonce is fetched again on the next call.
+func (m *NonceManager) Reset(accountIndex int64, apiKeyIndex uint8) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.next, key)
+	delete(m.handedOut, key)
+	delete(m.pending, key)
+	delete(m.flagged, key)
+}
+
+// flagLocked returns the gap for the nonce, unless it was already flagged. Requires m.mu.
+func (m *NonceManager) flagLocked(key nonceKey, nonce int64, reason NonceGapReason) []NonceGap {
+	if m.flagged[key] == nil {
+		m.flagged[key] = make(map[int64]struct{})
+	}
+	if _, ok := m.flagged[key][nonce]; ok {
+		return nil
+	}
+	m.flagged[key][nonce] = struct{}{}
+	return []NonceGap{{
+		AccountIndex: key.accountIndex,
+		ApiKeyIndex:  key.apiKeyIndex,
+		Nonce:        nonce,
+		Reason:       reason,
+		DetectedAt:   m.now(),
+	}}
+}
+
+func (m *NonceManager) emit(gaps []NonceGap) {
+	if len(gaps) == 0 {
+		return
+	}
+	m.mu.Lock()
+	onGap := m.onGap
+	m.mu.Unlock()
+
+	for _, gap := range gaps {
+		if onGap != nil {
+			onGap(gap)
+		}
+		select {
+		case m.gaps <- gap:
+		default:
+		}
+	}
+}
+
+// TxNonce returns the signer identity and nonce of a tx. ok is false for tx types it doesn't know.
+func TxNonce(tx txtypes.TxInfo) (accountIndex int64, apiKeyIndex uint8, nonce int64, ok bool) {
+	switch t := tx.(type) {
+	case *txtypes.L2ChangePubKeyTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2CreateSubAccountTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2CreatePublicPoolTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2UpdatePublicPoolTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2TransferTxInfo:
+		return t.FromAccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2WithdrawTxInfo:
+		return t.FromAccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2CreateOrderTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2CancelOrderTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2ModifyOrderTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2MintSharesTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2BurnSharesTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2UpdateLeverageTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	case *txtypes.L2UpdateMarginTxInfo:
+		return t.AccountIndex, t.ApiKeyIndex, t.Nonce, true
+	}
+	return 0, 0, 0, false
+}
+
+// sendNotLanded reports whether a failed send certainly didn't consume its nonce: Lighter rejected the tx, or the
+// request never reached it. Timeouts, cancellations and server errors are ambiguous, since the tx may have landed.
+func sendNotLanded(err error) bool {
+	var (
+		rateLimitErr *RateLimitError
+		netErr       *NetworkError
+	)
+	switch {
+	case isPermanentSendError(err), errors.As(err, &rateLimitErr):
+		return true
+	case errors.As(err, &netErr):
+		return netErr.Kind == NetworkErrorDNSFailure || netErr.Kind == NetworkErrorConnRefused || netErr.Kind == NetworkErrorTLS
+	default:
+		return false
+	}
+}
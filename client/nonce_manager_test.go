@@ -0,0 +1,250 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/client/clienttest"
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// nonceServer plays the nonce check of Lighter: a tx is accepted only with the next nonce of its API key.
+// Txs whose nonce is in lost time out before reaching it.
+type nonceServer struct {
+	mu       sync.Mutex
+	expected int64
+	lost     map[int64]bool
+}
+
+func (s *nonceServer) requester() *clienttest.FakeRequester {
+	return &clienttest.FakeRequester{
+		NextNonceFunc: func(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+			if err := ctx.Err(); err != nil {
+				return -1, err
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.expected, nil
+		},
+		SendRawTxFunc: func(ctx context.Context, tx txtypes.TxInfo, opts client.SendOpts) (string, error) {
+			_, _, nonce, _ := client.TxNonce(tx)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.lost[nonce] {
+				delete(s.lost, nonce)
+				return "", &client.NetworkError{Kind: client.NetworkErrorTimeout, Err: context.DeadlineExceeded}
+			}
+			if nonce != s.expected {
+				return "", &client.APIError{Code: client.CodeInvalidNonce, Message: "invalid nonce", HTTPStatus: 200}
+			}
+			s.expected++
+			return tx.GetTxHash(), nil
+		},
+	}
+}
+
+func newNonceTestClient(t *testing.T, requester client.L2Requester) *client.TxClient {
+	t.Helper()
+	keyManager, err := keys.DeriveApiKey(make([]byte, keys.MinMasterSeedLength), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.NewTxClientWithKeyManager(requester, keyManager, 5, 3, 304)
+}
+
+func nonceTestOrder(clientOrderIndex int64) *types.CreateOrderTxReq {
+	return &types.CreateOrderTxReq{
+		MarketIndex:      1,
+		ClientOrderIndex: clientOrderIndex,
+		BaseAmount:       1000,
+		Price:            250000,
+		TimeInForce:      txtypes.GoodTillTime,
+		OrderExpiry:      time.Now().Add(time.Hour).UnixMilli(),
+	}
+}
+
+func TestNonceManagerRecoversLostTx(t *testing.T) {
+	server := &nonceServer{expected: 10, lost: map[int64]bool{10: true}}
+	requester := server.requester()
+	c := newNonceTestClient(t, requester)
+	m := client.NewNonceManager(requester.GetNextNonceCtx, time.Nanosecond)
+	c.SetNonceManager(m)
+	ctx := context.Background()
+
+	// nonce 10 times out without reaching the server, so 11 is rejected
+	orders := map[int64]*types.CreateOrderTxReq{}
+	for i, wantNonce := range []int64{10, 11} {
+		req := nonceTestOrder(int64(i))
+		tx, err := c.GetCreateOrderTransaction(req, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Nonce != wantNonce {
+			t.Fatalf("order %v got nonce %v, want %v", i, tx.Nonce, wantNonce)
+		}
+		orders[tx.Nonce] = req
+		if _, err := c.SendTx(ctx, tx); err == nil {
+			t.Fatalf("order %v was accepted", i)
+		}
+	}
+	// the timed out nonce may have landed: it stays pending instead of being reused
+	if pending := m.Pending(); len(pending) != 1 || pending[0].Nonce != 10 {
+		t.Fatalf("pending %+v, want nonce 10 only", pending)
+	}
+
+	// the rejected nonce is handed out again; recovering would reassign it under the holder's feet
+	held, err := c.GetCreateOrderTransaction(orders[11], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if held.Nonce != 11 {
+		t.Fatalf("rejected nonce not reused: got %v", held.Nonce)
+	}
+	if err := m.RecoverGap(ctx, 5, 3, nil); !errors.Is(err, client.ErrNoncesOutstanding) {
+		t.Fatalf("RecoverGap with nonce 11 handed out: %v", err)
+	}
+	m.Failed(5, 3, held.Nonce)
+
+	time.Sleep(time.Millisecond)
+	gaps := m.Check()
+	if len(gaps) != 1 || gaps[0].Nonce != 10 || gaps[0].Reason != client.NonceGapStale {
+		t.Fatalf("gaps %+v, want nonce 10 stale", gaps)
+	}
+
+	if err := m.RecoverGap(ctx, 5, 3, func(ctx context.Context, oldNonce, newNonce int64) error {
+		if oldNonce != 10 || newNonce != 10 {
+			t.Fatalf("resign(%v, %v), want (10, 10)", oldNonce, newNonce)
+		}
+		tx, err := c.GetCreateOrderTransaction(orders[oldNonce], &types.TransactOpts{Nonce: &newNonce})
+		if err != nil {
+			return err
+		}
+		_, err = c.SendTx(ctx, tx)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := c.GetCreateOrderTransaction(orders[11], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendTx(ctx, tx); err != nil || tx.Nonce != 11 {
+		t.Fatalf("order with nonce %v after the recovery: %v", tx.Nonce, err)
+	}
+	if pending := m.Pending(); len(pending) != 0 {
+		t.Fatalf("pending %+v after the recovery", pending)
+	}
+	if sent := len(requester.Sent()); sent != 4 {
+		t.Fatalf("%v txs sent, want 4", sent)
+	}
+}
+
+func TestNonceManagerRejectedSendIsReused(t *testing.T) {
+	server := &nonceServer{expected: 3}
+	requester := server.requester()
+	c := newNonceTestClient(t, requester)
+	m := client.NewNonceManager(requester.GetNextNonceCtx, 0)
+	c.SetNonceManager(m)
+
+	nonce := int64(7)
+	tx, err := c.GetCreateOrderTransaction(nonceTestOrder(1), &types.TransactOpts{Nonce: &nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendTx(context.Background(), tx); err == nil {
+		t.Fatal("wrong nonce accepted")
+	}
+	if pending := m.Pending(); len(pending) != 0 {
+		t.Fatalf("rejected nonce still pending: %+v", pending)
+	}
+}
+
+func TestNonceManagerRecoverGapHonorsContext(t *testing.T) {
+	server := &nonceServer{}
+	requester := server.requester()
+	m := client.NewNonceManager(requester.GetNextNonceCtx, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.RecoverGap(ctx, 5, 3, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestNonceManagerNextHonorsOpsContext(t *testing.T) {
+	server := &nonceServer{expected: 3}
+	requester := server.requester()
+	c := newNonceTestClient(t, requester)
+	m := client.NewNonceManager(requester.GetNextNonceCtx, 0)
+	c.SetNonceManager(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetCreateOrderTransaction(nonceTestOrder(1), &types.TransactOpts{Ctx: ctx}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	tx, err := c.GetCreateOrderTransaction(nonceTestOrder(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce != 3 {
+		t.Fatalf("nonce %v after the cancelled fetch, want 3", tx.Nonce)
+	}
+}
+
+func TestNonceManagerSignFailureReleasesNonce(t *testing.T) {
+	server := &nonceServer{expected: 10}
+	requester := server.requester()
+	c := newNonceTestClient(t, requester)
+	m := client.NewNonceManager(requester.GetNextNonceCtx, 0)
+	c.SetNonceManager(m)
+
+	expired := nonceTestOrder(1)
+	expired.OrderExpiry = time.Now().Add(-time.Hour).UnixMilli()
+	if _, err := c.GetCreateOrderTransaction(expired, nil); err == nil {
+		t.Fatal("order with a past expiry signed")
+	}
+	if err := m.RecoverGap(context.Background(), 5, 3, nil); err != nil {
+		t.Fatalf("RecoverGap after the failed signing: %v", err)
+	}
+	tx, err := c.GetCreateOrderTransaction(nonceTestOrder(2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce != 10 {
+		t.Fatalf("nonce %v after the failed signing, want 10", tx.Nonce)
+	}
+}
+
+func TestNonceManagerBatchFailureReleasesNonces(t *testing.T) {
+	server := &nonceServer{expected: 10}
+	requester := server.requester()
+	c := newNonceTestClient(t, requester)
+	m := client.NewNonceManager(requester.GetNextNonceCtx, 0)
+	c.SetNonceManager(m)
+
+	expired := nonceTestOrder(3)
+	expired.OrderExpiry = time.Now().Add(-time.Hour).UnixMilli()
+	txs, err := c.GetCreateOrderTransactions([]*types.CreateOrderTxReq{nonceTestOrder(1), nonceTestOrder(2), expired}, nil)
+	if err == nil || txs != nil {
+		t.Fatalf("got %v txs, %v, want the failure of order 2", len(txs), err)
+	}
+	if err := m.RecoverGap(context.Background(), 5, 3, nil); err != nil {
+		t.Fatalf("RecoverGap after the failed batch: %v", err)
+	}
+
+	txs, err = c.GetCreateOrderTransactions([]*types.CreateOrderTxReq{nonceTestOrder(1), nonceTestOrder(2)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txs[0].Nonce != 10 || txs[1].Nonce != 11 {
+		t.Fatalf("nonces %v, %v after the failed batch, want 10, 11", txs[0].Nonce, txs[1].Nonce)
+	}
+}
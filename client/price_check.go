@@ -0,0 +1,113 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrEmptyBook     = errors.New("order book side is empty")
+	ErrCrossedBook   = errors.New("order book is crossed")
+	ErrUnknownMarket = errors.New("unknown market")
+)
+
+// marketCache holds the price decimals of the markets, fetched once from the orderBooks endpoint.
+// It's shared by an HTTPClient and its copies.
+type marketCache struct {
+	mu            sync.Mutex
+	priceDecimals map[uint8]uint8
+}
+
+func (c *HTTPClient) priceDecimals(marketIndex uint8) (uint8, error) {
+	c.markets.mu.Lock()
+	defer c.markets.mu.Unlock()
+
+	if decimals, ok := c.markets.priceDecimals[marketIndex]; ok {
+		return decimals, nil
+	}
+	books, err := c.GetOrderBooks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get markets. err: %w", err)
+	}
+	c.markets.priceDecimals = make(map[uint8]uint8, len(books.OrderBooks))
+	for _, book := range books.OrderBooks {
+		c.markets.priceDecimals[book.MarketId] = book.SupportedPriceDecimals
+	}
+	decimals, ok := c.markets.priceDecimals[marketIndex]
+	if !ok {
+		return 0, fmt.Errorf("%w: %v", ErrUnknownMarket, marketIndex)
+	}
+	return decimals, nil
+}
+
+// PriceCheck is the outcome of CheckOrderPrice. Prices are in the order's Price units.
+type PriceCheck struct {
+	Price uint32 `json:"price"`
+	// ReferencePrice is the best price of the side the order would take from: the best ask for buys,
+	// the best bid for sells.
+	ReferencePrice uint32 `json:"referencePrice"`
+	// DeviationBps is how far, in basis points of ReferencePrice, the order goes through the reference price:
+	// above it for buys, below it for sells. Prices on the passive side count as 0.
+	DeviationBps int64 `json:"deviationBps"`
+	Exceeded     bool  `json:"exceeded"`
+}
+
+// CheckOrderPrice compares the Price of an order with the live order book, before signing it, and reports
+// whether it goes more than thresholdBps through the best price of the opposite side. It's meant to warn
+// about fat-finger prices; the server applies its own price protection regardless.
+// It fails with ErrEmptyBook when the opposite side has no orders, ErrCrossedBook when the book is crossed
+// and ErrUnknownMarket when the market doesn't exist.
+func (c *HTTPClient) CheckOrderPrice(marketIndex uint8, price uint32, isAsk bool, thresholdBps int64) (*PriceCheck, error) {
+	if thresholdBps < 0 {
+		return nil, fmt.Errorf("threshold should not be negative. got: %v", thresholdBps)
+	}
+	decimals, err := c.priceDecimals(marketIndex)
+	if err != nil {
+		return nil, err
+	}
+	book, err := c.GetOrderBookOrders(marketIndex, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(book.Asks) > 0 && len(book.Bids) > 0 {
+		bestAsk, err := book.Asks[0].Price.Units(decimals)
+		if err != nil {
+			return nil, err
+		}
+		bestBid, err := book.Bids[0].Price.Units(decimals)
+		if err != nil {
+			return nil, err
+		}
+		if bestBid >= bestAsk {
+			return nil, ErrCrossedBook
+		}
+	}
+
+	side := book.Asks
+	if isAsk {
+		side = book.Bids
+	}
+	if len(side) == 0 {
+		return nil, ErrEmptyBook
+	}
+	reference, err := side[0].Price.Units(decimals)
+	if err != nil {
+		return nil, err
+	}
+	if reference <= 0 {
+		return nil, fmt.Errorf("invalid reference price %v", side[0].Price)
+	}
+
+	through := int64(price) - reference
+	if isAsk {
+		through = reference - int64(price)
+	}
+	check := &PriceCheck{
+		Price:          price,
+		ReferencePrice: uint32(reference),
+		DeviationBps:   max(through, 0) * 10_000 / reference,
+	}
+	check.Exceeded = check.DeviationBps > thresholdBps
+	return check, nil
+}
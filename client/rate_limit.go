@@ -0,0 +1,78 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitStatus is the rate limit budget Lighter reported in the headers of the latest response carrying one.
+// Limit and Remaining are -1 when the response didn't have them; ResetAt is zero when unknown.
+// UpdatedAt is zero until a response with rate limit headers was received.
+type RateLimitStatus struct {
+	Limit      int64
+	Remaining  int64
+	ResetAt    time.Time
+	RetryAfter time.Duration
+	UpdatedAt  time.Time
+}
+
+type rateLimitTracker struct {
+	mu     sync.Mutex
+	status RateLimitStatus
+}
+
+// RateLimitStatus returns the budget reported by the latest response, to throttle before getting 429s.
+func (c *HTTPClient) RateLimitStatus() RateLimitStatus {
+	c.rateLimit.mu.Lock()
+	defer c.rateLimit.mu.Unlock()
+	return c.rateLimit.status
+}
+
+// record updates the status from the headers of a response. Responses without rate limit headers leave it as is.
+func (t *rateLimitTracker) record(header http.Header, now time.Time) {
+	limit, hasLimit := parseRateLimitHeader(header.Get("X-RateLimit-Limit"))
+	remaining, hasRemaining := parseRateLimitHeader(header.Get("X-RateLimit-Remaining"))
+	resetAt, hasReset := parseRateLimitReset(header.Get("X-RateLimit-Reset"), now)
+	retryAfter := parseRetryAfter(header.Get("Retry-After"), now)
+	if !hasLimit && !hasRemaining && !hasReset && retryAfter == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = RateLimitStatus{
+		Limit:      limit,
+		Remaining:  remaining,
+		ResetAt:    resetAt,
+		RetryAfter: retryAfter,
+		UpdatedAt:  now,
+	}
+}
+
+func parseRateLimitHeader(header string) (int64, bool) {
+	if header == "" {
+		return -1, false
+	}
+	value, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || value < 0 {
+		return -1, false
+	}
+	return value, true
+}
+
+// resetEpochThreshold tells the two forms of X-RateLimit-Reset apart: values below it are seconds until the reset,
+// others a unix timestamp in seconds.
+const resetEpochThreshold = 1_000_000_000
+
+func parseRateLimitReset(header string, now time.Time) (time.Time, bool) {
+	value, ok := parseRateLimitHeader(header)
+	if !ok {
+		return time.Time{}, false
+	}
+	if value < resetEpochThreshold {
+		return now.Add(time.Duration(value) * time.Second), true
+	}
+	return time.Unix(value, 0), true
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// RawGet calls an endpoint the client doesn't model yet and returns the raw response.
+// path is relative to the base path (e.g. "orderBooks"); the request goes through the same
+// headers, params and error handling as the modeled endpoints. For endpoints whose responses
+// aren't wrapped in a ResultCode, set RequestOpts.SkipResultCode on ctx.
+func (c *HTTPClient) RawGet(ctx context.Context, path string, params map[string]any) (json.RawMessage, error) {
+	var result json.RawMessage
+	if err := c.getAndParseL2HTTPResponseCtx(ctx, path, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RawPost is RawGet for form-encoded POST endpoints. headers are added to the request;
+// reserved ones (Content-Type, Authorization) are rejected.
+func (c *HTTPClient) RawPost(ctx context.Context, path string, form url.Values, headers map[string]string) (json.RawMessage, error) {
+	ctx = c.requestContext(ctx)
+	h := make(http.Header, len(headers))
+	for key, value := range headers {
+		h.Set(key, value)
+	}
+	var result json.RawMessage
+	if err := c.postAndParseL2HTTPResponse(ctx, path, form, h, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+var reservedHeaders = []string{"Content-Type", "Authorization"}
+
+// RequestOpts holds extra headers and query params for the requests made with a context.
+// They're applied on top of the client's default headers; a query param already set by the
+// request itself can't be overridden.
+type RequestOpts struct {
+	Headers http.Header
+	Params  url.Values
+	// SkipResultCode accepts 200 responses without checking their ResultCode, for endpoints whose responses
+	// aren't wrapped in it. Meant for RawGet and RawPost.
+	SkipResultCode bool
+}
+
+type requestOptsKey struct{}
+
+// WithRequestOpts returns a context carrying opts, to be passed to SendTx or the *Ctx request helpers.
+func WithRequestOpts(ctx context.Context, opts RequestOpts) context.Context {
+	return context.WithValue(ctx, requestOptsKey{}, opts)
+}
+
+func requestOptsFrom(ctx context.Context) (RequestOpts, bool) {
+	opts, ok := ctx.Value(requestOptsKey{}).(RequestOpts)
+	return opts, ok
+}
+
+func skipResultCode(ctx context.Context) bool {
+	opts, ok := requestOptsFrom(ctx)
+	return ok && opts.SkipResultCode
+}
+
+func checkHeaders(headers http.Header) error {
+	for _, key := range reservedHeaders {
+		if _, ok := headers[key]; ok {
+			return fmt.Errorf("header %v is reserved and can't be overridden", key)
+		}
+	}
+	return nil
+}
+
+func (c *HTTPClient) applyDefaultHeaders(req *http.Request) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if err := checkHeaders(c.headers); err != nil {
+		return err
+	}
+	for key, values := range c.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	return nil
+}
+
+func applyRequestHeaders(ctx context.Context, req *http.Request) error {
+	opts, ok := requestOptsFrom(ctx)
+	if !ok {
+		return nil
+	}
+	headers := make(http.Header, len(opts.Headers))
+	for key, values := range opts.Headers {
+		headers[http.CanonicalHeaderKey(key)] = values
+	}
+	if err := checkHeaders(headers); err != nil {
+		return err
+	}
+	for key, values := range headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	return nil
+}
+
+// applyParams adds the per-call params of ctx to the params q of the request, then the client's default
+// params which weren't set by either.
+func (c *HTTPClient) applyParams(ctx context.Context, q url.Values) error {
+	if opts, ok := requestOptsFrom(ctx); ok {
+		for key, values := range opts.Params {
+			if q.Has(key) {
+				return fmt.Errorf("query param %v is already set by the request", key)
+			}
+			q[key] = append([]string(nil), values...)
+		}
+	}
+	for key, values := range c.defaultQuery {
+		if !q.Has(key) {
+			q[key] = append([]string(nil), values...)
+		}
+	}
+	return nil
+}
+
+// WithRequestOpts returns a copy of the client whose requests carry opts.
+// TxClient.SendTx takes them from its context instead, see the package-level WithRequestOpts.
+func (c *HTTPClient) WithRequestOpts(opts RequestOpts) *HTTPClient {
+	c.mu.RLock()
+	clone := *c
+	c.mu.RUnlock()
+	clone.requestOpts = &opts
+	return &clone
+}
+
+// requestContext attaches the client's RequestOpts to ctx, unless ctx already carries its own.
+func (c *HTTPClient) requestContext(ctx context.Context) context.Context {
+	if c.requestOpts == nil {
+		return ctx
+	}
+	if _, ok := requestOptsFrom(ctx); ok {
+		return ctx
+	}
+	return WithRequestOpts(ctx, *c.requestOpts)
+}
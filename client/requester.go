@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// L2Requester is what a TxClient needs from Lighter. HTTPClient implements it; other transports, decorators
+// (see RequesterMiddleware) and fakes (see the clienttest package) can be passed to NewTxClient instead.
+type L2Requester interface {
+	GetNextNonceCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error)
+	GetApiKeyCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (*AccountApiKeys, error)
+	SendRawTxCtx(ctx context.Context, tx txtypes.TxInfo, opts SendOpts) (string, error)
+	GetStatus(ctx context.Context) (*Status, error)
+	AccountIndexes(l1Address string) ([]int64, error)
+}
+
+var _ L2Requester = (*HTTPClient)(nil)
+
+// RequesterMiddleware decorates an L2Requester, e.g. to retry or cache requests. The returned requester may embed
+// next to only override some methods, and should implement Unwrap() L2Requester returning next, so TxClient.HTTP
+// still finds the HTTPClient underneath.
+type RequesterMiddleware func(next L2Requester) L2Requester
+
+// WrapRequester applies the middlewares to r, the first one being the outermost.
+func WrapRequester(r L2Requester, middlewares ...RequesterMiddleware) L2Requester {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		r = middlewares[i](r)
+	}
+	return r
+}
+
+// httpClientOf returns the HTTPClient r is or wraps, nil if there is none.
+func httpClientOf(r L2Requester) *HTTPClient {
+	for r != nil {
+		switch v := r.(type) {
+		case *HTTPClient:
+			return v
+		case interface{ Unwrap() L2Requester }:
+			r = v.Unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// normalizeRequester turns a nil *HTTPClient, as passed for offline clients, into a nil L2Requester.
+func normalizeRequester(r L2Requester) L2Requester {
+	if c, ok := r.(*HTTPClient); ok && c == nil {
+		return nil
+	}
+	return r
+}
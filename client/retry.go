@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryPolicy configures WithRetry. Zero fields use the defaults: 3 attempts, backoff from 100ms capped at 5s.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// RetrySends allows sending a tx again after a failure which may have happened after Lighter got it. It's only
+	// safe because the same signed tx is sent: a replay is rejected for its nonce rather than executed twice.
+	RetrySends bool
+	// RetryMaintenance retries through a maintenance window (MaintenanceError), waiting until its announced end,
+	// and at least a minute, instead of the backoff. It's off by default since a window usually lasts far longer
+	// than a caller waits for a request; history.RetryMaintenance does the same for downloads.
+	RetryMaintenance bool
+	// OnAttempt is called after every failed attempt, e.g. for metrics. Delay is 0 when no retry follows.
+	OnAttempt func(RetryAttempt)
+
+	// Sleep and Rand replace the timer and the jitter source, e.g. with a fake clock in tests.
+	// Sleep must return ctx.Err() when ctx is done first. Rand returns a number in [0, 1).
+	Sleep func(ctx context.Context, d time.Duration) error
+	Rand  func() float64
+}
+
+// RetryAttempt describes a failed attempt of a request made through WithRetry.
+type RetryAttempt struct {
+	// Op is the L2Requester method, e.g. "GetNextNonceCtx".
+	Op      string
+	Attempt int
+	Err     error
+	Delay   time.Duration
+}
+
+// WithRetry retries the requests failing without an answer (NetworkError), with a 5xx (ServerError) or with a
+// 429 (RateLimitError, after its Retry-After if longer than the backoff). A maintenance window is only retried
+// with RetryPolicy.RetryMaintenance; other errors, e.g. an APIError, are returned at once. Retries back off
+// exponentially with full jitter and stop before the deadline of the ctx. Sends are only retried with
+// RetryPolicy.RetrySends.
+func WithRetry(policy RetryPolicy) RequesterMiddleware {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = defaultRetryAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaultRetryBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultRetryMaxDelay
+	}
+	if policy.Sleep == nil {
+		policy.Sleep = sleepCtx
+	}
+	if policy.Rand == nil {
+		policy.Rand = rand.Float64
+	}
+	return func(next L2Requester) L2Requester {
+		return &retryRequester{next: next, policy: policy}
+	}
+}
+
+type retryRequester struct {
+	next   L2Requester
+	policy RetryPolicy
+}
+
+func (r *retryRequester) Unwrap() L2Requester {
+	return r.next
+}
+
+func (r *retryRequester) GetNextNonceCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return withRetries(ctx, r.policy, "GetNextNonceCtx", true, func() (int64, error) {
+		return r.next.GetNextNonceCtx(ctx, accountIndex, apiKeyIndex)
+	})
+}
+
+func (r *retryRequester) GetApiKeyCtx(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (*AccountApiKeys, error) {
+	return withRetries(ctx, r.policy, "GetApiKeyCtx", true, func() (*AccountApiKeys, error) {
+		return r.next.GetApiKeyCtx(ctx, accountIndex, apiKeyIndex)
+	})
+}
+
+func (r *retryRequester) SendRawTxCtx(ctx context.Context, tx txtypes.TxInfo, opts SendOpts) (string, error) {
+	return withRetries(ctx, r.policy, "SendRawTxCtx", r.policy.RetrySends, func() (string, error) {
+		return r.next.SendRawTxCtx(ctx, tx, opts)
+	})
+}
+
+func (r *retryRequester) GetStatus(ctx context.Context) (*Status, error) {
+	return withRetries(ctx, r.policy, "GetStatus", true, func() (*Status, error) {
+		return r.next.GetStatus(ctx)
+	})
+}
+
+func (r *retryRequester) AccountIndexes(l1Address string) ([]int64, error) {
+	return withRetries(context.Background(), r.policy, "AccountIndexes", true, func() ([]int64, error) {
+		return r.next.AccountIndexes(l1Address)
+	})
+}
+
+func withRetries[T any](ctx context.Context, policy RetryPolicy, op string, retryable bool, call func() (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := call()
+		if err == nil {
+			return res, nil
+		}
+
+		delay, retry := policy.retryDelay(ctx, attempt, err)
+		retry = retry && retryable
+		if !retry {
+			delay = 0
+		}
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(RetryAttempt{Op: op, Attempt: attempt, Err: err, Delay: delay})
+		}
+		if !retry {
+			return res, err
+		}
+		if sleepErr := policy.Sleep(ctx, delay); sleepErr != nil {
+			return res, err
+		}
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt, and false when err isn't transient, the attempts
+// are exhausted or the ctx would expire first.
+func (p RetryPolicy) retryDelay(ctx context.Context, attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	var (
+		netErr         *NetworkError
+		serverErr      *ServerError
+		rateLimitErr   *RateLimitError
+		maintenanceErr *MaintenanceError
+	)
+	switch {
+	case errors.As(err, &netErr), errors.As(err, &serverErr), errors.As(err, &rateLimitErr):
+	case p.RetryMaintenance && errors.As(err, &maintenanceErr):
+	default:
+		return 0, false
+	}
+
+	backoff := p.BaseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	delay := time.Duration(p.Rand() * float64(backoff))
+	if rateLimitErr != nil && rateLimitErr.RetryAfter > delay {
+		delay = rateLimitErr.RetryAfter
+	}
+	if maintenanceErr != nil {
+		delay = maintenanceErr.RetryAfter(time.Now())
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+		return 0, false
+	}
+	return delay, true
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
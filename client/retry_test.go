@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// step is one scripted answer of scriptTransport: a response, or a transport error when err is set.
+type step struct {
+	status     int
+	retryAfter string
+	body       string
+	err        error
+}
+
+var nonceStep = step{status: http.StatusOK, body: `{"code":200,"nonce":7}`}
+
+// scriptTransport answers the requests with its steps in order, failing the requests past the last one.
+type scriptTransport struct {
+	mu    sync.Mutex
+	steps []step
+	calls int
+}
+
+func (s *scriptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls >= len(s.steps) {
+		s.calls++
+		return nil, errors.New("unscripted request")
+	}
+	st := s.steps[s.calls]
+	s.calls++
+	if st.err != nil {
+		return nil, st.err
+	}
+	header := make(http.Header)
+	if st.retryAfter != "" {
+		header.Set("Retry-After", st.retryAfter)
+	}
+	return &http.Response{
+		StatusCode: st.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(st.body)),
+		Request:    req,
+	}, nil
+}
+
+func (s *scriptTransport) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// fakeClock records the delays slept by WithRetry instead of waiting.
+type fakeClock struct {
+	slept []time.Duration
+}
+
+func (f *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	f.slept = append(f.slept, d)
+	return ctx.Err()
+}
+
+// newRetryRequester returns an HTTPClient answering with steps, wrapped by WithRetry with policy. The jitter
+// always picks half of the backoff.
+func newRetryRequester(policy RetryPolicy, steps ...step) (L2Requester, *scriptTransport, *fakeClock) {
+	transport := &scriptTransport{steps: steps}
+	httpClient := NewHTTPClient("http://lighter.test")
+	httpClient.httpClient.Transport = transport
+	clock := &fakeClock{}
+	policy.Sleep = clock.sleep
+	policy.Rand = func() float64 { return 0.5 }
+	return WithRetry(policy)(httpClient), transport, clock
+}
+
+func serverErrorStep() step {
+	return step{status: http.StatusBadGateway, body: "bad gateway"}
+}
+
+func TestRetryBackoffIsCapped(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 6, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	requester, transport, clock := newRetryRequester(policy,
+		serverErrorStep(), serverErrorStep(), serverErrorStep(), serverErrorStep(), serverErrorStep(), nonceStep)
+
+	nonce, err := requester.GetNextNonceCtx(context.Background(), 1, 0)
+	if err != nil || nonce != 7 {
+		t.Fatalf("got %v, %v", nonce, err)
+	}
+	// backoffs of 100, 200, then capped at 300ms, halved by the jitter
+	want := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond, 150 * time.Millisecond, 150 * time.Millisecond}
+	if !reflect.DeepEqual(clock.slept, want) {
+		t.Fatalf("slept %v, want %v", clock.slept, want)
+	}
+	if calls := transport.callCount(); calls != 6 {
+		t.Fatalf("%v calls, want 6", calls)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts []RetryAttempt
+	policy := RetryPolicy{OnAttempt: func(a RetryAttempt) { attempts = append(attempts, a) }}
+	requester, transport, clock := newRetryRequester(policy,
+		serverErrorStep(), step{err: errors.New("connection reset")}, serverErrorStep(), nonceStep)
+
+	_, err := requester.GetNextNonceCtx(context.Background(), 1, 0)
+	if !errors.Is(err, ErrServer) {
+		t.Fatalf("got %v, want the last ServerError", err)
+	}
+	if calls := transport.callCount(); calls != 3 {
+		t.Fatalf("%v calls, want the default 3", calls)
+	}
+	if want := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}; !reflect.DeepEqual(clock.slept, want) {
+		t.Fatalf("slept %v, want %v", clock.slept, want)
+	}
+	var netErr *NetworkError
+	if len(attempts) != 3 || !errors.As(attempts[1].Err, &netErr) || attempts[2].Delay != 0 || attempts[2].Attempt != 3 {
+		t.Fatalf("attempts %+v", attempts)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	requester, _, clock := newRetryRequester(RetryPolicy{},
+		step{status: http.StatusTooManyRequests, retryAfter: "2"},
+		step{status: http.StatusTooManyRequests}, // no hint: the backoff
+		nonceStep)
+
+	if _, err := requester.GetNextNonceCtx(context.Background(), 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Duration{2 * time.Second, 100 * time.Millisecond}; !reflect.DeepEqual(clock.slept, want) {
+		t.Fatalf("slept %v, want %v", clock.slept, want)
+	}
+}
+
+func TestRetryStopsBeforeDeadline(t *testing.T) {
+	requester, transport, clock := newRetryRequester(RetryPolicy{},
+		step{status: http.StatusTooManyRequests, retryAfter: "30"}, nonceStep)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := requester.GetNextNonceCtx(ctx, 1, 0)
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("got %v, want the RateLimitError", err)
+	}
+	if calls := transport.callCount(); calls != 1 || len(clock.slept) != 0 {
+		t.Fatalf("%v calls and slept %v, want no retry past the deadline", calls, clock.slept)
+	}
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	for _, st := range []step{
+		{status: http.StatusBadRequest, body: `{"code":20001,"message":"invalid param"}`},
+		{status: http.StatusNotFound, body: "404 page not found"},
+		{status: http.StatusOK, body: `{"code":21104,"message":"invalid nonce"}`},
+		{status: http.StatusServiceUnavailable, body: `{"code":503,"message":"under maintenance"}`},
+	} {
+		requester, transport, clock := newRetryRequester(RetryPolicy{}, st, nonceStep)
+		if _, err := requester.GetNextNonceCtx(context.Background(), 1, 0); err == nil {
+			t.Fatalf("status %v: succeeded", st.status)
+		}
+		if calls := transport.callCount(); calls != 1 || len(clock.slept) != 0 {
+			t.Fatalf("status %v: %v calls and slept %v, want no retry", st.status, calls, clock.slept)
+		}
+	}
+}
+
+func TestRetrySendsOnlyWhenAllowed(t *testing.T) {
+	tx := signTestOrder(t, newTestTxClient(t, nil), 1)
+	sent := step{status: http.StatusOK, body: `{"code":200,"tx_hash":"hash"}`}
+
+	requester, transport, _ := newRetryRequester(RetryPolicy{}, serverErrorStep(), sent)
+	if _, err := requester.SendRawTxCtx(context.Background(), tx, SendOpts{}); !errors.Is(err, ErrServer) {
+		t.Fatalf("got %v, want the ServerError", err)
+	}
+	if calls := transport.callCount(); calls != 1 {
+		t.Fatalf("send retried without RetrySends: %v calls", calls)
+	}
+
+	requester, transport, _ = newRetryRequester(RetryPolicy{RetrySends: true}, serverErrorStep(), sent)
+	if hash, err := requester.SendRawTxCtx(context.Background(), tx, SendOpts{}); err != nil || hash != "hash" {
+		t.Fatalf("with RetrySends: %q, %v", hash, err)
+	}
+	if calls := transport.callCount(); calls != 2 {
+		t.Fatalf("with RetrySends: %v calls, want 2", calls)
+	}
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrChainIdMismatch = errors.New("chainId mismatch")
+
+// ChainIdMismatchError is returned by VerifyChainId when the server signs for another chain than the client.
+// It matches ErrChainIdMismatch with errors.Is.
+type ChainIdMismatchError struct {
+	Client uint32
+	Server uint32
+}
+
+func (e *ChainIdMismatchError) Error() string {
+	return fmt.Sprintf("%v: client=%v server=%v", ErrChainIdMismatch.Error(), e.Client, e.Server)
+}
+
+func (e *ChainIdMismatchError) Is(target error) bool {
+	return target == ErrChainIdMismatch
+}
+
+// Status is the answer of the root endpoint of Lighter, which reports whether the server is up.
+type Status struct {
+	Status    int   `json:"status"`
+	NetworkId int   `json:"network_id"`
+	Timestamp int64 `json:"timestamp"` // server time, in seconds
+	// ChainId is the chain id txs are signed for, when the server reports it.
+	ChainId *uint32 `json:"chain_id,omitempty"`
+}
+
+// GetStatus calls the root endpoint of the server, e.g. to check it's reachable before trading.
+func (c *HTTPClient) GetStatus(ctx context.Context) (*Status, error) {
+	// the status isn't wrapped in a ResultCode
+	opts, _ := requestOptsFrom(c.requestContext(ctx))
+	opts.SkipResultCode = true
+
+	result := &Status{}
+	if err := c.getAndParseURLPath(WithRequestOpts(ctx, opts), "/", nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// HealthCheck is the outcome of TxClient.HealthCheck.
+type HealthCheck struct {
+	// Reachable is false when the request got no answer from the server, see NetworkError.
+	Reachable  bool    `json:"reachable"`
+	ServerTime *int64  `json:"serverTime"`
+	ChainId    *uint32 `json:"chainId"`
+	// ChainIdMismatch is set when the server reports a chain id other than the client's, which makes
+	// every signature of the client invalid.
+	ChainIdMismatch bool   `json:"chainIdMismatch"`
+	Error           string `json:"error,omitempty"`
+}
+
+// HealthCheck calls GetStatus and compares the server's chain id with the client's. Errors are reported
+// in the result rather than returned.
+func (c *TxClient) HealthCheck(ctx context.Context) *HealthCheck {
+	if c.requester == nil {
+		return &HealthCheck{Error: "HTTPClient is nil, can't check the server"}
+	}
+	status, err := c.requester.GetStatus(ctx)
+	if err != nil {
+		var netErr *NetworkError
+		return &HealthCheck{Reachable: !errors.As(err, &netErr), Error: err.Error()}
+	}
+
+	check := &HealthCheck{
+		Reachable:  true,
+		ServerTime: &status.Timestamp,
+		ChainId:    status.ChainId,
+	}
+	if status.ChainId != nil && *status.ChainId != c.chainId {
+		check.ChainIdMismatch = true
+	}
+	return check
+}
+
+// VerifyChainId compares the server's chain id with the client's, e.g. after creating the client or after a failover
+// to another endpoint. It returns a ChainIdMismatchError when they differ, and the GetStatus error, a NetworkError
+// when the server can't be reached, when the check can't be made. A server not reporting its chain id passes.
+func (c *TxClient) VerifyChainId(ctx context.Context) error {
+	if c.requester == nil {
+		return fmt.Errorf("HTTPClient is nil, can't check the server")
+	}
+	status, err := c.requester.GetStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the chain id of the server. err: %w", err)
+	}
+	if status.ChainId != nil && *status.ChainId != c.chainId {
+		return &ChainIdMismatchError{Client: c.chainId, Server: *status.ChainId}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrRateLimited = errors.New("rate limited by lighter")
+	ErrServer      = errors.New("lighter server error")
+)
+
+// RateLimitError is returned when Lighter answers 429. RetryAfter comes from the Retry-After header,
+// and is zero when the response doesn't have one. It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	msg := ErrRateLimited.Error()
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %v)", e.RetryAfter)
+	}
+	return msg
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// ServerError is returned when Lighter answers with a 5xx status, other than a maintenance 503.
+// It matches ErrServer with errors.Is.
+type ServerError struct {
+	Status int
+	Body   string
+}
+
+func (e *ServerError) Error() string {
+	msg := fmt.Sprintf("%v (status %v)", ErrServer.Error(), e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func (e *ServerError) Is(target error) bool {
+	return target == ErrServer
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as an HTTP date.
+// It returns zero when the header is missing, invalid or already passed.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// getNonceFrom makes a request to a server answering with status, the Retry-After header when set, and body.
+func getNonceFrom(t *testing.T, status int, retryAfter, body string) error {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	_, err := NewHTTPClient(srv.URL).GetNextNonce(1, 0)
+	return err
+}
+
+func TestRateLimitErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{"retry after seconds", "7", 7 * time.Second},
+		{"retry after date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+		{"no retry after", "", 0},
+		{"invalid retry after", "soon", 0},
+	} {
+		err := getNonceFrom(t, http.StatusTooManyRequests, tc.retryAfter, "slow down")
+		var rateLimitErr *RateLimitError
+		if !errors.As(err, &rateLimitErr) || !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("%v: got %v, want a RateLimitError", tc.name, err)
+		}
+		// an HTTP date has a precision of a second
+		if d := rateLimitErr.RetryAfter - tc.want; d > 0 || d < -2*time.Second {
+			t.Errorf("%v: RetryAfter %v, want %v", tc.name, rateLimitErr.RetryAfter, tc.want)
+		}
+		if rateLimitErr.Body != "slow down" {
+			t.Errorf("%v: body %q", tc.name, rateLimitErr.Body)
+		}
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		err := getNonceFrom(t, status, "", "try later")
+		var serverErr *ServerError
+		if !errors.As(err, &serverErr) || !errors.Is(err, ErrServer) {
+			t.Fatalf("%v: got %v, want a ServerError", status, err)
+		}
+		if serverErr.Status != status || serverErr.Body != "try later" {
+			t.Errorf("%v: got %+v", status, serverErr)
+		}
+		if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrMaintenance) {
+			t.Errorf("%v: %v matches another status", status, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SubmissionCache remembers the outcome of submitted txs by their signed hash, so re-submitting the exact
+// same tx (e.g. after a network blip hid the first response) returns the original result instead of posting it again.
+// Only successes and permanent failures are cached; transport errors and retryable statuses are not.
+// A cached failure is only returned for a tx sent with the same price protection, since it may be why it failed.
+// Concurrent submissions of the same tx are sent once. It's bounded in size, evicting the oldest entries first,
+// and safe for concurrent use.
+type SubmissionCache struct {
+	size      int
+	retention time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	order    *list.List
+	entries  map[string]*list.Element
+	inflight map[string]chan struct{} // closed when the submission ends
+}
+
+type submission struct {
+	txHash      string
+	sendKey     string
+	hash        string
+	err         error
+	submittedAt time.Time
+}
+
+func NewSubmissionCache(size int, retention time.Duration) *SubmissionCache {
+	if size < 1 {
+		size = 1
+	}
+	return &SubmissionCache{
+		size:      size,
+		retention: retention,
+		now:       time.Now,
+		order:     list.New(),
+		entries:   make(map[string]*list.Element),
+		inflight:  make(map[string]chan struct{}),
+	}
+}
+
+// claim returns the outcome of txHash, or claims its submission, which the caller must end with finish. sendKey
+// identifies the send options the tx is sent with now, see TxClient.sendKey. While a submission is claimed, the
+// other callers sending the same tx wait for it to end, then get its outcome if it was cached or claim it in turn.
+// A caller whose ctx ends while waiting gets ctx.Err().
+func (c *SubmissionCache) claim(ctx context.Context, txHash string, sendKey string) (hash string, err error, done bool) {
+	for {
+		c.mu.Lock()
+		if hash, err, ok := c.lookup(txHash, sendKey); ok {
+			c.mu.Unlock()
+			return hash, err, true
+		}
+		inflight, ok := c.inflight[txHash]
+		if !ok {
+			c.inflight[txHash] = make(chan struct{})
+			c.mu.Unlock()
+			return "", nil, false
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-inflight:
+		case <-ctx.Done():
+			return "", ctx.Err(), true
+		}
+	}
+}
+
+// finish ends a submission claimed with claim. The outcome is cached when sent is true and it's a success or a
+// permanent failure.
+func (c *SubmissionCache) finish(txHash string, sendKey string, hash string, err error, sent bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sent && (err == nil || isPermanentSendError(err)) {
+		c.store(txHash, sendKey, hash, err)
+	}
+	if inflight, ok := c.inflight[txHash]; ok {
+		delete(c.inflight, txHash)
+		close(inflight)
+	}
+}
+
+// lookup returns the cached outcome of txHash. c.mu must be held.
+func (c *SubmissionCache) lookup(txHash string, sendKey string) (hash string, err error, ok bool) {
+	elem, ok := c.entries[txHash]
+	if !ok {
+		return "", nil, false
+	}
+	s := elem.Value.(*submission)
+	if c.now().Sub(s.submittedAt) > c.retention {
+		c.order.Remove(elem)
+		delete(c.entries, txHash)
+		return "", nil, false
+	}
+	if s.err != nil && s.sendKey != sendKey {
+		return "", nil, false
+	}
+	return s.hash, s.err, true
+}
+
+// store caches the outcome of txHash, evicting the oldest entries beyond the size. c.mu must be held.
+func (c *SubmissionCache) store(txHash string, sendKey string, hash string, err error) {
+	if elem, ok := c.entries[txHash]; ok {
+		c.order.Remove(elem)
+	}
+	c.entries[txHash] = c.order.PushBack(&submission{
+		txHash:      txHash,
+		sendKey:     sendKey,
+		hash:        hash,
+		err:         err,
+		submittedAt: c.now(),
+	})
+	for c.order.Len() > c.size {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*submission).txHash)
+	}
+}
+
+// isPermanentSendError reports whether Lighter rejected the tx itself, so sending it again can't succeed.
+// Errors without an answer from Lighter, rate limits and server-side errors are not permanent.
+func isPermanentSendError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.HTTPStatus != http.StatusRequestTimeout
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sendTxServer answers sendTx requests with respond, recording the price_protection form value of each one
+// ("" when absent).
+type sendTxServer struct {
+	*httptest.Server
+	mu               sync.Mutex
+	priceProtections []string
+	respond          func(w http.ResponseWriter, r *http.Request)
+}
+
+func newSendTxServer(t *testing.T) *sendTxServer {
+	s := &sendTxServer{}
+	s.respond = func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":200,"tx_hash":"hash"}`)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		s.mu.Lock()
+		s.priceProtections = append(s.priceProtections, r.PostForm.Get("price_protection"))
+		s.mu.Unlock()
+		s.respond(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *sendTxServer) posts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.priceProtections...)
+}
+
+func TestPriceProtectionFormValue(t *testing.T) {
+	srv := newSendTxServer(t)
+	httpClient := NewHTTPClient(srv.URL)
+	c := newTestTxClient(t, httpClient)
+	off, on := false, true
+
+	for i, tc := range []struct {
+		clientSetting bool
+		override      *bool
+		want          string
+	}{
+		{true, nil, ""},
+		{true, &off, "false"},
+		{true, nil, ""}, // the override doesn't stick
+		{true, &on, ""},
+		{false, nil, "false"},
+		{false, &on, ""},
+		{false, nil, "false"},
+		{true, nil, ""},
+	} {
+		httpClient.SetFatFingerProtection(tc.clientSetting)
+		if _, err := httpClient.SendRawTxWithOpts(signTestOrder(t, c, int64(i)), SendOpts{PriceProtection: tc.override}); err != nil {
+			t.Fatal(err)
+		}
+		posts := srv.posts()
+		if got := posts[len(posts)-1]; got != tc.want {
+			t.Errorf("send %v (client %v, override %v): price_protection=%q, want %q", i, tc.clientSetting, tc.override, got, tc.want)
+		}
+	}
+}
+
+func TestSubmissionCacheKeepsFailuresPerPriceProtection(t *testing.T) {
+	srv := newSendTxServer(t)
+	srv.respond = func(w http.ResponseWriter, r *http.Request) {
+		if r.PostForm.Get("price_protection") == "" {
+			fmt.Fprint(w, `{"code":21733,"message":"order price flagged as fat finger"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code":200,"tx_hash":"landed"}`)
+	}
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	c.SetSubmissionCache(NewSubmissionCache(16, time.Minute))
+	tx := signTestOrder(t, c, 1)
+
+	if _, err := c.SendTx(context.Background(), tx); err == nil {
+		t.Fatal("price protected send succeeded")
+	}
+	if _, err := c.SendTx(context.Background(), tx); err == nil || len(srv.posts()) != 1 {
+		t.Fatalf("same options: err %v after %v posts, want the cached failure", err, len(srv.posts()))
+	}
+
+	off := false
+	hash, err := c.SendTxWithOpts(context.Background(), tx, SendOpts{PriceProtection: &off})
+	if err != nil || hash != "landed" || len(srv.posts()) != 2 {
+		t.Fatalf("without price protection: %q, %v after %v posts", hash, err, len(srv.posts()))
+	}
+
+	// the success is returned whatever the options, since the tx landed
+	hash, err = c.SendTx(context.Background(), tx)
+	if err != nil || hash != "landed" || len(srv.posts()) != 2 {
+		t.Fatalf("after success: %q, %v after %v posts", hash, err, len(srv.posts()))
+	}
+}
+
+func TestSubmissionCacheResubmitAfterSuccess(t *testing.T) {
+	srv := newSendTxServer(t)
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	c.SetSubmissionCache(NewSubmissionCache(16, time.Minute))
+	tx := signTestOrder(t, c, 1)
+
+	for i := 0; i < 3; i++ {
+		hash, err := c.SendTx(context.Background(), tx)
+		if err != nil || hash != "hash" {
+			t.Fatalf("send %v: %q, %v", i, hash, err)
+		}
+	}
+	if posts := len(srv.posts()); posts != 1 {
+		t.Fatalf("%v posts, want 1", posts)
+	}
+
+	if _, err := c.SendTxWithOpts(context.Background(), tx, SendOpts{BypassCache: true}); err != nil {
+		t.Fatal(err)
+	}
+	if posts := len(srv.posts()); posts != 2 {
+		t.Fatalf("%v posts after bypassing the cache, want 2", posts)
+	}
+}
+
+func TestSubmissionCacheResubmitAfterPermanentFailure(t *testing.T) {
+	srv := newSendTxServer(t)
+	srv.respond = func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":21104,"message":"invalid nonce"}`)
+	}
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	c.SetSubmissionCache(NewSubmissionCache(16, time.Minute))
+	tx := signTestOrder(t, c, 1)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.SendTx(context.Background(), tx)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != CodeInvalidNonce {
+			t.Fatalf("send %v: %v, want the APIError", i, err)
+		}
+	}
+	if posts := len(srv.posts()); posts != 1 {
+		t.Fatalf("%v posts, want 1", posts)
+	}
+}
+
+func TestSubmissionCacheSkipsTransientFailures(t *testing.T) {
+	srv := newSendTxServer(t)
+	srv.respond = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	c.SetSubmissionCache(NewSubmissionCache(16, time.Minute))
+	tx := signTestOrder(t, c, 1)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.SendTx(context.Background(), tx); err == nil {
+			t.Fatalf("send %v succeeded", i)
+		}
+	}
+	if posts := len(srv.posts()); posts != 2 {
+		t.Fatalf("%v posts, want 2", posts)
+	}
+}
+
+func TestSubmissionCacheDeduplicatesConcurrentSends(t *testing.T) {
+	srv := newSendTxServer(t)
+	received, release := make(chan struct{}, 1), make(chan struct{})
+	srv.respond = func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		fmt.Fprint(w, `{"code":200,"tx_hash":"hash"}`)
+	}
+	c := newTestTxClient(t, NewHTTPClient(srv.URL))
+	cache := NewSubmissionCache(16, time.Minute)
+	c.SetSubmissionCache(cache)
+	tx := signTestOrder(t, c, 1)
+
+	const senders = 8
+	var wg sync.WaitGroup
+	hashes, errs := make([]string, senders), make([]error, senders)
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hashes[i], errs[i] = c.SendTx(context.Background(), tx)
+		}()
+	}
+	<-received
+	time.Sleep(50 * time.Millisecond) // let the other senders reach the cache
+	close(release)
+	wg.Wait()
+
+	for i := range hashes {
+		if errs[i] != nil || hashes[i] != "hash" {
+			t.Fatalf("sender %v: %q, %v", i, hashes[i], errs[i])
+		}
+	}
+	if posts := len(srv.posts()); posts != 1 {
+		t.Fatalf("%v posts, want 1", posts)
+	}
+	if len(cache.inflight) != 0 {
+		t.Fatalf("%v submissions still in flight", len(cache.inflight))
+	}
+}
+
+func TestSubmissionCacheWaitHonorsContext(t *testing.T) {
+	cache := NewSubmissionCache(16, time.Minute)
+	if _, _, done := cache.claim(context.Background(), "tx", ""); done {
+		t.Fatal("first claim didn't get the submission")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err, done := cache.claim(ctx, "tx", ""); !done || !errors.Is(err, context.Canceled) {
+		t.Fatalf("waiting claim: done %v, err %v", done, err)
+	}
+	cache.finish("tx", "", "", nil, false)
+	if _, _, done := cache.claim(context.Background(), "tx", ""); done {
+		t.Fatal("claim after an unsent submission didn't get it")
+	}
+}
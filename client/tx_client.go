@@ -1,8 +1,12 @@
 package client
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elliottech/lighter-go/signer"
@@ -10,20 +14,43 @@ import (
 )
 
 const (
-	defaultExpireTime = time.Minute*10 - time.Second // we need to give a second margin, to eliminate millisecond differences
+	DefaultExpireTime = time.Minute*10 - time.Second // we need to give a second margin, to eliminate millisecond differences
 )
 
+// ErrOfflineNonce is returned when a TxClient without HTTPClient nor NonceManager is asked to sign a tx without nonce.
+var ErrOfflineNonce = errors.New("offline client requires explicit nonce")
+
+// ErrClientClosed is returned when a closed TxClient is asked to sign or send.
+var ErrClientClosed = errors.New("client is closed")
+
+// ErrDryRunTx is returned by SendTx for a tx signed with TransactOpts.DryRun.
+var ErrDryRunTx = errors.New("tx was signed as a dry run")
+
 type TxClient struct {
-	apiClient    *HTTPClient
+	requester    L2Requester
+	apiClient    *HTTPClient // the HTTPClient requester is or wraps, nil for other transports
 	chainId      uint32
 	keyManager   signer.KeyManager
 	accountIndex int64
 	apiKeyIndex  uint8
+	closed       atomic.Bool
+
+	interceptorsMu sync.RWMutex
+	interceptors   []Interceptor
+
+	nonceManager    *NonceManager
+	submissionCache *SubmissionCache
+	authToken       authTokenCache
+	dryRuns         dryRunSet
+
+	defaultsMu sync.RWMutex
+	defaults   ClientDefaults
 }
 
 // NewTxClient is linked to a specific (account, apiKey) pair
 // apiKeyPrivateKey should be hex-encoded bytes generated using `hexutil.Encode(TxClient.GetKeyManager().PrvKeyBytes())`
-func NewTxClient(apiClient *HTTPClient, apiKeyPrivateKey string, accountIndex int64, apiKeyIndex uint8, chainId uint32) (*TxClient, error) {
+// apiClient is usually an HTTPClient, nil for an offline client.
+func NewTxClient(apiClient L2Requester, apiKeyPrivateKey string, accountIndex int64, apiKeyIndex uint8, chainId uint32) (*TxClient, error) {
 	// remove 0x from private key, if any, and parse to bytes
 	if len(apiKeyPrivateKey) < 2 {
 		return nil, fmt.Errorf("empty private key")
@@ -41,21 +68,32 @@ func NewTxClient(apiClient *HTTPClient, apiKeyPrivateKey string, accountIndex in
 		return nil, err
 	}
 
+	return NewTxClientWithKeyManager(apiClient, keyManager, accountIndex, apiKeyIndex, chainId), nil
+}
+
+// NewTxClientWithKeyManager is NewTxClient for an already parsed key, which can be shared between clients.
+func NewTxClientWithKeyManager(apiClient L2Requester, keyManager signer.KeyManager, accountIndex int64, apiKeyIndex uint8, chainId uint32) *TxClient {
+	apiClient = normalizeRequester(apiClient)
 	return &TxClient{
-		apiClient:    apiClient,
+		requester:    apiClient,
+		apiClient:    httpClientOf(apiClient),
 		apiKeyIndex:  apiKeyIndex,
 		accountIndex: accountIndex,
 		chainId:      chainId,
 		keyManager:   keyManager,
-	}, nil
+		defaults:     DefaultClientDefaults(),
+	}
 }
 
 func (c *TxClient) FullFillDefaultOps(ops *types.TransactOpts) (*types.TransactOpts, error) {
 	if ops == nil {
 		ops = new(types.TransactOpts)
 	}
+	if err := types.ValidateOpts(ops); err != nil {
+		return nil, err
+	}
 	if ops.ExpiredAt == 0 {
-		ops.ExpiredAt = time.Now().Add(defaultExpireTime).UnixMilli()
+		ops.ExpiredAt = time.Now().Add(DefaultExpireTime).UnixMilli()
 	}
 	if ops.FromAccountIndex == nil {
 		ops.FromAccountIndex = &c.accountIndex
@@ -63,11 +101,27 @@ func (c *TxClient) FullFillDefaultOps(ops *types.TransactOpts) (*types.TransactO
 	if ops.ApiKeyIndex == nil {
 		ops.ApiKeyIndex = &c.apiKeyIndex
 	}
+	ctx := ops.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ops.Nonce == nil && c.nonceManager != nil {
+		next := c.nonceManager.NextCtx
+		if ops.DryRun {
+			// a dry run doesn't reserve the nonce, since the tx won't be sent
+			next = c.nonceManager.Peek
+		}
+		nonce, err := next(ctx, *ops.FromAccountIndex, *ops.ApiKeyIndex)
+		if err != nil {
+			return nil, err
+		}
+		ops.Nonce = &nonce
+	}
 	if ops.Nonce == nil {
-		if c.apiClient == nil {
-			return nil, fmt.Errorf("nonce was not provided & HTTPClient is nil. Either provide the nonce or enable HTTPClient to get the nonce from Lighter")
+		if c.requester == nil {
+			return nil, ErrOfflineNonce
 		}
-		nonce, err := c.apiClient.GetNextNonce(*ops.FromAccountIndex, *ops.ApiKeyIndex)
+		nonce, err := c.requester.GetNextNonceCtx(ctx, *ops.FromAccountIndex, *ops.ApiKeyIndex)
 		if err != nil {
 			return nil, err
 		}
@@ -85,25 +139,73 @@ func (c *TxClient) GetApiKeyIndex() uint8 {
 	return c.apiKeyIndex
 }
 
+func (c *TxClient) GetChainId() uint32 {
+	return c.chainId
+}
+
+// SetNonceManager makes the client take nonces from m instead of asking the server for every tx,
+// and report the outcome of SendTx to it. Pass nil to go back to fetching nonces.
+func (c *TxClient) SetNonceManager(m *NonceManager) {
+	c.nonceManager = m
+}
+
+func (c *TxClient) NonceManager() *NonceManager {
+	return c.nonceManager
+}
+
+// SetSubmissionCache enables idempotent submission in SendTx. Pass nil to disable it.
+func (c *TxClient) SetSubmissionCache(cache *SubmissionCache) {
+	c.submissionCache = cache
+}
+
 func (c *TxClient) GetKeyManager() signer.KeyManager {
 	return c.keyManager
 }
 
 func (c *TxClient) GetAuthToken(deadline time.Time) (string, error) {
-	if time.Until(deadline) > (7 * time.Hour) {
-		return "", fmt.Errorf("deadline should be within 7 hours")
+	return c.GetAuthTokenFor(c.accountIndex, deadline)
+}
+
+// GetAuthTokenFor builds an auth token for another account, signed with this client's key, e.g. for a public pool
+// operator reading the pool's account. Lighter only accepts it if this client's API key index is registered
+// on accountIndex with this client's public key; the token is not checked against that here.
+func (c *TxClient) GetAuthTokenFor(accountIndex int64, deadline time.Time) (string, error) {
+	if c.closed.Load() {
+		return "", ErrClientClosed
+	}
+	if !deadline.After(time.Now()) {
+		return "", fmt.Errorf("deadline should be in the future. deadline: %v", deadline.Unix())
+	}
+	if time.Until(deadline) > maxAuthTokenTTL {
+		return "", fmt.Errorf("deadline should be within %v. deadline: %v", maxAuthTokenTTL, deadline.Unix())
 	}
 
 	return types.ConstructAuthToken(c.keyManager, deadline, &types.TransactOpts{
 		ApiKeyIndex:      &c.apiKeyIndex,
-		FromAccountIndex: &c.accountIndex,
+		FromAccountIndex: &accountIndex,
 	})
 }
 
+// Close disables the client: signing, sending and auth tokens fail with ErrClientClosed afterwards. It doesn't wipe
+// the key, which may be shared with other clients, nor close the HTTPClient.
+func (c *TxClient) Close() {
+	c.closed.Store(true)
+}
+
+// HTTP returns the HTTPClient of the client, nil when it's offline or uses another L2Requester.
 func (c *TxClient) HTTP() *HTTPClient {
 	return c.apiClient
 }
 
+// SetEndpoint repoints the client's HTTPClient to another base URL, keeping its key and account.
+// The HTTPClient may be shared with other TxClients, which are repointed as well.
+func (c *TxClient) SetEndpoint(baseUrl string) error {
+	if c.apiClient == nil {
+		return fmt.Errorf("HTTPClient is nil, can't set endpoint")
+	}
+	return c.apiClient.SetEndpoint(baseUrl)
+}
+
 func (c *TxClient) SwitchAPIKey(apiKey uint8) {
 	c.apiKeyIndex = apiKey
 }
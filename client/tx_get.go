@@ -2,18 +2,21 @@ package client
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/elliottech/lighter-go/orders"
 	"github.com/elliottech/lighter-go/types"
 	"github.com/elliottech/lighter-go/types/txtypes"
 	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
 )
 
-func (c *TxClient) GetChangePubKeyTransaction(tx *types.ChangePubKeyReq, ops *types.TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetChangePubKeyTransaction(tx *types.ChangePubKeyReq, ops *types.TransactOpts) (txInfo *txtypes.L2ChangePubKeyTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructChangePubKeyTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructChangePubKeyTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
@@ -25,168 +28,286 @@ func (c *TxClient) GetChangePubKeyTransaction(tx *types.ChangePubKeyReq, ops *ty
 		return nil, fmt.Errorf("failed to validate signature. error: %v", err)
 	}
 
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetCreateSubAccountTransaction(ops *types.TransactOpts) (*txtypes.L2CreateSubAccountTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetCreateSubAccountTransaction(ops *types.TransactOpts) (txInfo *txtypes.L2CreateSubAccountTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(nil, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructCreateSubAccountTx(c.keyManager, c.chainId, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructCreateSubAccountTx(c.keyManager, c.chainId, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetCreatePublicPoolTransaction(tx *types.CreatePublicPoolTxReq, ops *types.TransactOpts) (*txtypes.L2CreatePublicPoolTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetCreatePublicPoolTransaction(tx *types.CreatePublicPoolTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2CreatePublicPoolTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructCreatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructCreatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetUpdatePublicPoolTransaction(tx *types.UpdatePublicPoolTxReq, ops *types.TransactOpts) (*txtypes.L2UpdatePublicPoolTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetUpdatePublicPoolTransaction(tx *types.UpdatePublicPoolTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2UpdatePublicPoolTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructUpdatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructUpdatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetTransferTransaction(tx *types.TransferTxReq, ops *types.TransactOpts) (*txtypes.L2TransferTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetTransferTransaction(tx *types.TransferTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2TransferTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructTransferTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructTransferTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetWithdrawTransaction(tx *types.WithdrawTxReq, ops *types.TransactOpts) (*txtypes.L2WithdrawTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetWithdrawTransaction(tx *types.WithdrawTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2WithdrawTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructWithdrawTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructWithdrawTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
 
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetCreateOrderTransaction(tx *types.CreateOrderTxReq, ops *types.TransactOpts) (*txtypes.L2CreateOrderTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+// GetCreateOrderTransaction resolves the OrderExpiry of tx with orders.ResolveOrderExpiry before signing it;
+// tx itself is left untouched.
+func (c *TxClient) GetCreateOrderTransaction(tx *types.CreateOrderTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2CreateOrderTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructCreateOrderTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	order, err := c.resolveOrder(tx, time.Now())
 	if err != nil {
 		return nil, err
 	}
+	txInfo, err = types.ConstructCreateOrderTx(c.keyManager, c.chainId, order, ops)
+	if err != nil {
+		return nil, err
+	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetCancelOrderTransaction(tx *types.CancelOrderTxReq, ops *types.TransactOpts) (*txtypes.L2CancelOrderTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+// GetCreateOrderTransactions signs several orders with consecutive nonces, starting at ops.Nonce, or at the next
+// nonce of the API key when it's nil. With a NonceManager and no ops.Nonce every order takes the manager's next nonce,
+// which are consecutive unless the manager is used concurrently; a dry run numbers them from the manager's next
+// nonce without reserving any. If an order can't be signed, no tx is returned, the nonces reserved
+// for the earlier orders are released, and the error names the order.
+func (c *TxClient) GetCreateOrderTransactions(txs []*types.CreateOrderTxReq, ops *types.TransactOpts) ([]*txtypes.L2CreateOrderTxInfo, error) {
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("no orders to sign")
+	}
+	base := types.TransactOpts{}
+	if ops != nil {
+		base = *ops
+	}
+	useNonceManager := base.Nonce == nil && c.nonceManager != nil && !base.DryRun
+
+	txInfos := make([]*txtypes.L2CreateOrderTxInfo, 0, len(txs))
+	for i, tx := range txs {
+		txOps := base
+		if i > 0 {
+			txOps.ExpiredAt = txInfos[0].ExpiredAt
+			if !useNonceManager {
+				nonce := txInfos[0].Nonce + int64(i)
+				txOps.Nonce = &nonce
+			}
+		}
+		txInfo, err := c.GetCreateOrderTransaction(tx, &txOps)
+		if err != nil {
+			if useNonceManager {
+				// latest first, so every nonce is handed back instead of leaving a gap
+				for j := len(txInfos) - 1; j >= 0; j-- {
+					c.nonceManager.Failed(txInfos[j].AccountIndex, txInfos[j].ApiKeyIndex, txInfos[j].Nonce)
+				}
+			}
+			return nil, fmt.Errorf("order %v: %w", i, err)
+		}
+		txInfos = append(txInfos, txInfo)
+	}
+	return txInfos, nil
+}
+
+// GetCreateGroupedOrdersTransaction resolves the OrderExpiry of every order like GetCreateOrderTransaction.
+func (c *TxClient) GetCreateGroupedOrdersTransaction(tx *types.CreateGroupedOrdersTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2CreateGroupedOrdersTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructL2CancelOrderTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	now := time.Now()
+	grouped := &types.CreateGroupedOrdersTxReq{
+		GroupingType: tx.GroupingType,
+		Orders:       make([]*types.CreateOrderTxReq, len(tx.Orders)),
+	}
+	for i, order := range tx.Orders {
+		grouped.Orders[i], err = c.resolveOrder(order, now)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i, err)
+		}
+	}
+	txInfo, err = types.ConstructL2CreateGroupedOrdersTx(c.keyManager, c.chainId, grouped, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetModifyOrderTransaction(tx *types.ModifyOrderTxReq, ops *types.TransactOpts) (*txtypes.L2ModifyOrderTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+// resolveOrder returns a copy of tx with the client's defaults applied to its TimeInForce and OrderExpiry.
+func (c *TxClient) resolveOrder(tx *types.CreateOrderTxReq, now time.Time) (*types.CreateOrderTxReq, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("order is nil")
+	}
+	defaults := c.Defaults()
+	order := *tx
+	if order.TimeInForce == orders.DefaultTimeInForce {
+		order.TimeInForce = defaults.TimeInForce
+	}
+	expiry, err := orders.ResolveOrderExpiryWithPeriod(order.Type, order.TimeInForce, order.OrderExpiry, now, defaults.OrderExpiry)
 	if err != nil {
 		return nil, err
 	}
+	order.OrderExpiry = expiry
+	return &order, nil
+}
 
-	txInfo, err := types.ConstructL2ModifyOrderTx(c.keyManager, c.chainId, tx, ops)
+func (c *TxClient) GetCancelOrderTransaction(tx *types.CancelOrderTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2CancelOrderTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructL2CancelOrderTx(c.keyManager, c.chainId, tx, ops)
+	if err != nil {
+		return nil, err
+	}
+	c.dryRuns.signed(ops, txInfo)
+	return txInfo, nil
+}
+
+func (c *TxClient) GetModifyOrderTransaction(tx *types.ModifyOrderTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2ModifyOrderTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
+	if err != nil {
+		return nil, err
+	}
+	defer reserved.releaseOnError(&err)
 
+	txInfo, err = types.ConstructL2ModifyOrderTx(c.keyManager, c.chainId, tx, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetCancelAllOrdersTransaction(tx *types.CancelAllOrdersTxReq, ops *types.TransactOpts) (*txtypes.L2CancelAllOrdersTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetCancelAllOrdersTransaction(tx *types.CancelAllOrdersTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2CancelAllOrdersTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructL2CancelAllOrdersTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructL2CancelAllOrdersTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetMintSharesTransaction(tx *types.MintSharesTxReq, ops *types.TransactOpts) (*txtypes.L2MintSharesTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetMintSharesTransaction(tx *types.MintSharesTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2MintSharesTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructMintSharesTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructMintSharesTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetBurnSharesTransaction(tx *types.BurnSharesTxReq, ops *types.TransactOpts) (*txtypes.L2BurnSharesTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetBurnSharesTransaction(tx *types.BurnSharesTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2BurnSharesTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructBurnSharesTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructBurnSharesTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetUpdateLeverageTransaction(tx *types.UpdateLeverageTxReq, ops *types.TransactOpts) (*txtypes.L2UpdateLeverageTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
+func (c *TxClient) GetUpdateLeverageTransaction(tx *types.UpdateLeverageTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2UpdateLeverageTxInfo, err error) {
+	ops, reserved, err := c.prepareOps(tx, ops)
 	if err != nil {
 		return nil, err
 	}
-	txInfo, err := types.ConstructUpdateLeverageTx(c.keyManager, c.chainId, tx, ops)
+	defer reserved.releaseOnError(&err)
+	txInfo, err = types.ConstructUpdateLeverageTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
 
-func (c *TxClient) GetUpdateMarginTransaction(tx *types.UpdateMarginTxReq, ops *types.TransactOpts) (*txtypes.L2UpdateMarginTxInfo, error) {
+func (c *TxClient) GetUpdateMarginTransaction(tx *types.UpdateMarginTxReq, ops *types.TransactOpts) (txInfo *txtypes.L2UpdateMarginTxInfo, err error) {
 	if c.keyManager == nil {
 		return nil, fmt.Errorf("key manager is nil")
 	}
 
-	if ops == nil {
-		ops = new(types.TransactOpts)
+	ops, reserved, err := c.prepareOps(tx, ops)
+	if err != nil {
+		return nil, err
 	}
+	defer reserved.releaseOnError(&err)
 
-	txInfo, err := types.ConstructUpdateMarginTx(c.keyManager, c.chainId, tx, ops)
+	txInfo, err = types.ConstructUpdateMarginTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
 	}
+	c.dryRuns.signed(ops, txInfo)
 	return txInfo, nil
 }
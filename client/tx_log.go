@@ -0,0 +1,219 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	logSigHexChars  = 8
+	logHashHexChars = 16
+)
+
+// longHex matches the hex runs which may be key material in free text, e.g. a signature, a private key
+// or the signature of an auth token quoted in an error.
+var longHex = regexp.MustCompile(`[0-9a-fA-F]{40,}`)
+
+var txTypeNames = map[uint8]string{
+	txtypes.TxTypeL2ChangePubKey:        "ChangePubKey",
+	txtypes.TxTypeL2CreateSubAccount:    "CreateSubAccount",
+	txtypes.TxTypeL2CreatePublicPool:    "CreatePublicPool",
+	txtypes.TxTypeL2UpdatePublicPool:    "UpdatePublicPool",
+	txtypes.TxTypeL2Transfer:            "Transfer",
+	txtypes.TxTypeL2Withdraw:            "Withdraw",
+	txtypes.TxTypeL2CreateOrder:         "CreateOrder",
+	txtypes.TxTypeL2CancelOrder:         "CancelOrder",
+	txtypes.TxTypeL2CancelAllOrders:     "CancelAllOrders",
+	txtypes.TxTypeL2ModifyOrder:         "ModifyOrder",
+	txtypes.TxTypeL2MintShares:          "MintShares",
+	txtypes.TxTypeL2BurnShares:          "BurnShares",
+	txtypes.TxTypeL2UpdateLeverage:      "UpdateLeverage",
+	txtypes.TxTypeL2CreateGroupedOrders: "CreateGroupedOrders",
+	txtypes.TxTypeL2UpdateMargin:        "UpdateMargin",
+}
+
+// SafeTxLog formats a tx as a single line of key=value pairs, safe to paste in public places:
+// the signature is truncated to 8 hex chars, the hash to its prefix, and key material
+// (ChangePubKey's public key and L1 signature included) is never printed.
+func SafeTxLog(tx txtypes.TxInfo) string {
+	if tx == nil {
+		return "tx=<nil>"
+	}
+
+	name, ok := txTypeNames[tx.GetTxType()]
+	if !ok {
+		name = fmt.Sprintf("%d", tx.GetTxType())
+	}
+	fields := []string{"tx=" + name}
+	add := func(key string, value any) {
+		fields = append(fields, fmt.Sprintf("%s=%v", key, value))
+	}
+
+	var nonce int64
+	var sig []byte
+	switch t := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		if t.OrderInfo != nil {
+			add("market", t.MarketIndex)
+			add("side", side(t.IsAsk))
+			add("price", t.Price)
+			add("size", t.BaseAmount)
+			add("order_type", t.Type)
+			add("tif", t.TimeInForce)
+			if t.TriggerPrice != txtypes.NilOrderTriggerPrice {
+				add("trigger_price", t.TriggerPrice)
+			}
+		}
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("grouping", t.GroupingType)
+		for i, order := range t.Orders {
+			add(fmt.Sprintf("order%d", i), fmt.Sprintf("%d/%s/%d@%d", order.MarketIndex, side(order.IsAsk), order.BaseAmount, order.Price))
+		}
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2ModifyOrderTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("market", t.MarketIndex)
+		add("index", t.Index)
+		add("price", t.Price)
+		add("size", t.BaseAmount)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2CancelOrderTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("market", t.MarketIndex)
+		add("index", t.Index)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("tif", t.TimeInForce)
+		add("time", t.Time)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2TransferTxInfo:
+		add("account", t.FromAccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("to", t.ToAccountIndex)
+		add("amount", t.USDCAmount)
+		add("fee", t.Fee)
+		add("memo", MemoDisplay(t.Memo))
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2WithdrawTxInfo:
+		add("account", t.FromAccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("amount", t.USDCAmount)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2ChangePubKeyTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2UpdateLeverageTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("market", t.MarketIndex)
+		add("imf", t.InitialMarginFraction)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2UpdateMarginTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("market", t.MarketIndex)
+		add("amount", t.USDCAmount)
+		add("direction", t.Direction)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2CreateSubAccountTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2CreatePublicPoolTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2UpdatePublicPoolTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("pool", t.PublicPoolIndex)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2MintSharesTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("pool", t.PublicPoolIndex)
+		add("shares", t.ShareAmount)
+		nonce, sig = t.Nonce, t.Sig
+	case *txtypes.L2BurnSharesTxInfo:
+		add("account", t.AccountIndex)
+		add("api_key", t.ApiKeyIndex)
+		add("pool", t.PublicPoolIndex)
+		add("shares", t.ShareAmount)
+		nonce, sig = t.Nonce, t.Sig
+	}
+
+	add("nonce", nonce)
+	add("hash", truncateHex(tx.GetTxHash(), logHashHexChars))
+	add("sig", truncateHex(txtypes.EncodeSigHex(sig), logSigHexChars))
+	return strings.Join(fields, " ")
+}
+
+// MemoDisplay renders a transfer memo: "-" when empty, the quoted text when it's printable
+// (trailing zero bytes dropped), and 0x-hex otherwise.
+func MemoDisplay(memo [32]byte) string {
+	trimmed := bytes.TrimRight(memo[:], "\x00")
+	if len(trimmed) == 0 {
+		return "-"
+	}
+	for _, r := range string(trimmed) {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return "0x" + hex.EncodeToString(memo[:])
+		}
+	}
+	return fmt.Sprintf("%q", trimmed)
+}
+
+// NewLoggingInterceptor logs every sent tx with SafeTxLog, together with its result.
+// Long hex runs in the error are truncated, since errors may quote auth tokens or keys.
+func NewLoggingInterceptor(logf func(format string, args ...any)) Interceptor {
+	return &InterceptorFuncs{
+		AfterSend: func(_ context.Context, tx txtypes.TxInfo, hash string, err error) {
+			if err != nil {
+				logf("send failed %s err=%q", SafeTxLog(tx), redactHex(err.Error()))
+				return
+			}
+			logf("sent %s tx_hash=%s", SafeTxLog(tx), truncateHex(hash, logHashHexChars))
+		},
+	}
+}
+
+func side(isAsk uint8) string {
+	if isAsk == 1 {
+		return "sell"
+	}
+	return "buy"
+}
+
+// redactHex truncates the long hex runs of s like the signatures of SafeTxLog.
+func redactHex(s string) string {
+	return longHex.ReplaceAllStringFunc(s, func(run string) string {
+		return truncateHex(run, logSigHexChars)
+	})
+}
+
+func truncateHex(s string, n int) string {
+	if s == "" {
+		return "-"
+	}
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var fullSigHex = regexp.MustCompile(`[0-9a-fA-F]{128,}`)
+
+// loggedTxs returns a tx of every type carrying a full signature, plus a signed order and a ChangePubKey
+// with its public key and L1 signature.
+func loggedTxs(t *testing.T, c *TxClient) []txtypes.TxInfo {
+	t.Helper()
+	sig := make([]byte, txtypes.SignatureLength)
+	for i := range sig {
+		sig[i] = byte(0xa0 + i%16)
+	}
+	var txs []txtypes.TxInfo
+	for txType := uint8(txtypes.TxTypeL2ChangePubKey); txType <= txtypes.TxTypeL2UpdateMargin; txType++ {
+		tx, err := txtypes.NewTxInfo(txType)
+		if err != nil {
+			continue
+		}
+		switch tx := tx.(type) {
+		case *txtypes.L2CreateOrderTxInfo:
+			tx.OrderInfo = &txtypes.OrderInfo{}
+		case *txtypes.L2TransferTxInfo:
+			copy(tx.Memo[:], sig)
+		}
+		tx.SetSignature(sig)
+		txs = append(txs, tx)
+	}
+
+	pubKey := c.GetKeyManager().PubKeyBytes()
+	changePubKey := &txtypes.L2ChangePubKeyTxInfo{
+		PubKey: pubKey[:],
+		L1Sig:  "0x" + strings.Repeat("ab", 65),
+		Sig:    sig,
+	}
+	return append(txs, changePubKey, signTestOrder(t, c, 1))
+}
+
+func TestLoggedTxsHaveNoSecrets(t *testing.T) {
+	c := newTestTxClient(t, nil)
+	authToken, err := c.GetAuthToken(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := hex.EncodeToString(c.GetKeyManager().PrvKeyBytes())
+	pubKey := c.GetKeyManager().PubKeyBytes()
+	secrets := []string{authToken, privateKey, hex.EncodeToString(pubKey[:])}
+
+	var lines []string
+	logger := NewLoggingInterceptor(func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	})
+	sendErr := fmt.Errorf("request with auth=%s failed, key %s: %w", authToken, privateKey, errors.New("boom"))
+	for _, tx := range loggedTxs(t, c) {
+		logger.OnAfterSend(context.Background(), tx, tx.GetTxHash(), nil)
+		logger.OnAfterSend(context.Background(), tx, "", sendErr)
+	}
+
+	if len(lines) != 2*17 {
+		t.Fatalf("%v lines logged", len(lines))
+	}
+	for _, line := range lines {
+		if run := fullSigHex.FindString(line); run != "" {
+			t.Errorf("hex run of %v chars logged: %v", len(run), line)
+		}
+		for _, secret := range secrets {
+			if strings.Contains(line, secret) {
+				t.Errorf("secret %.12s… logged: %v", secret, line)
+			}
+		}
+	}
+}
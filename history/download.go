@@ -0,0 +1,180 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// Page is one response of a paginated endpoint.
+// An empty NextCursor, or one equal to the cursor the page was fetched with, marks the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// PageFetcher returns the page starting at cursor. The empty cursor is the first page.
+type PageFetcher[T any] func(ctx context.Context, cursor string) (*Page[T], error)
+
+// CursorStore persists the cursor of the next page to fetch, so an interrupted download can resume.
+type CursorStore interface {
+	// Load returns "" if nothing was saved for key.
+	Load(key string) (string, error)
+	Save(key string, cursor string) error
+}
+
+type DownloadOpts[T any] struct {
+	// Store and Key are optional. When set, the cursor of the next page is saved after every delivered page.
+	Store CursorStore
+	Key   string
+
+	// From and To bound the downloaded range as [From, To). Zero values leave that side unbounded.
+	// Both need Timestamp.
+	From      time.Time
+	To        time.Time
+	Timestamp func(T) time.Time
+
+	// ID is optional. When set, items of a page whose ID was already delivered in the previous page are dropped.
+	ID func(T) string
+
+	// MinInterval is the minimum delay between two page requests.
+	MinInterval time.Duration
+
+	// RetryAfter is called for every fetch error; returning true retries the same page after the returned delay.
+	// When nil, DefaultRetryAfter is used.
+	RetryAfter func(err error) (time.Duration, bool)
+}
+
+// defaultRateLimitDelay is the wait of DefaultRetryAfter for a 429 without Retry-After header.
+const defaultRateLimitDelay = time.Second
+
+// DefaultRetryAfter retries rate limited pages after the delay Lighter asks for, or a second when it doesn't say.
+// Other errors aren't retried.
+func DefaultRetryAfter(err error) (time.Duration, bool) {
+	var rle *client.RateLimitError
+	if !errors.As(err, &rle) {
+		return 0, false
+	}
+	if rle.RetryAfter > 0 {
+		return rle.RetryAfter, true
+	}
+	return defaultRateLimitDelay, true
+}
+
+// RetryMaintenance wraps a RetryAfter policy so maintenance errors are waited out until the announced end
+// of the window instead of being retried at the usual pace. Other errors go to next, DefaultRetryAfter when nil.
+func RetryMaintenance(next func(err error) (time.Duration, bool)) func(err error) (time.Duration, bool) {
+	return func(err error) (time.Duration, bool) {
+		var me *client.MaintenanceError
+		if errors.As(err, &me) {
+			return me.RetryAfter(time.Now()), true
+		}
+		if next == nil {
+			return DefaultRetryAfter(err)
+		}
+		return next(err)
+	}
+}
+
+// Download walks the pages returned by fetch and sends every item to sink, then closes sink.
+//
+// Items are delivered in the order fetch returns them; From/To filtering assumes that order is ascending by Timestamp,
+// and the download stops at the first item at or after To.
+// The cursor is checkpointed only after a whole page was delivered, so delivery is at-least-once:
+// after an interruption mid-page, resuming re-delivers the items of that page which were already sent.
+// Duplicates across two consecutive pages (e.g. when a cursor is inclusive) are dropped when ID is set.
+//
+// sink is closed on every return, including errors and context cancellation.
+func Download[T any](ctx context.Context, fetch PageFetcher[T], sink chan<- T, opts DownloadOpts[T]) error {
+	defer close(sink)
+
+	if (!opts.From.IsZero() || !opts.To.IsZero()) && opts.Timestamp == nil {
+		return fmt.Errorf("Timestamp is required when From or To is set")
+	}
+	retryAfter := opts.RetryAfter
+	if retryAfter == nil {
+		retryAfter = DefaultRetryAfter
+	}
+
+	cursor := ""
+	if opts.Store != nil {
+		saved, err := opts.Store.Load(opts.Key)
+		if err != nil {
+			return fmt.Errorf("failed to load cursor. err: %w", err)
+		}
+		cursor = saved
+	}
+
+	var lastRequest time.Time
+	var prevIDs map[string]struct{}
+	for {
+		if wait := opts.MinInterval - time.Since(lastRequest); wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+		lastRequest = time.Now()
+
+		page, err := fetch(ctx, cursor)
+		if err != nil {
+			if delay, retry := retryAfter(err); retry {
+				if err := sleep(ctx, delay); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		ids := make(map[string]struct{}, len(page.Items))
+		for _, item := range page.Items {
+			if opts.ID != nil {
+				id := opts.ID(item)
+				ids[id] = struct{}{}
+				if _, ok := prevIDs[id]; ok {
+					continue
+				}
+			}
+			if opts.Timestamp != nil {
+				ts := opts.Timestamp(item)
+				if !opts.From.IsZero() && ts.Before(opts.From) {
+					continue
+				}
+				if !opts.To.IsZero() && !ts.Before(opts.To) {
+					return nil
+				}
+			}
+
+			select {
+			case sink <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		prevIDs = ids
+
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return nil
+		}
+		cursor = page.NextCursor
+		if opts.Store != nil {
+			if err := opts.Store.Save(opts.Key, cursor); err != nil {
+				return fmt.Errorf("failed to save cursor. err: %w", err)
+			}
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,181 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+type item struct {
+	id int
+	ts int64
+}
+
+var itemOpts = DownloadOpts[item]{
+	Timestamp: func(it item) time.Time { return time.UnixMilli(it.ts) },
+	ID:        func(it item) string { return strconv.Itoa(it.id) },
+}
+
+// pagesFetcher serves pages in order; the cursor is the index of the page.
+func pagesFetcher(pages ...[]item) PageFetcher[item] {
+	return func(ctx context.Context, cursor string) (*Page[item], error) {
+		i := 0
+		if cursor != "" {
+			i, _ = strconv.Atoi(cursor)
+		}
+		page := &Page[item]{Items: pages[i]}
+		if i+1 < len(pages) {
+			page.NextCursor = strconv.Itoa(i + 1)
+		}
+		return page, nil
+	}
+}
+
+func collect(t *testing.T, fetch PageFetcher[item], opts DownloadOpts[item]) ([]int, error) {
+	t.Helper()
+	sink := make(chan item)
+	errc := make(chan error, 1)
+	go func() { errc <- Download(context.Background(), fetch, sink, opts) }()
+	var ids []int
+	for it := range sink {
+		ids = append(ids, it.id)
+	}
+	return ids, <-errc
+}
+
+func TestDownloadOrderAndDeduplication(t *testing.T) {
+	// inclusive cursors: every page starts with the last item of the previous one
+	fetch := pagesFetcher(
+		[]item{{1, 10}, {2, 20}, {3, 30}},
+		[]item{{3, 30}, {4, 40}, {5, 50}},
+		[]item{{5, 50}, {6, 60}},
+	)
+
+	ids, err := collect(t, fetch, itemOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+
+	noID := itemOpts
+	noID.ID = nil
+	ids, err = collect(t, fetch, noID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3, 3, 4, 5, 5, 6}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("without ID: got %v, want %v", ids, want)
+	}
+
+	bounded := itemOpts
+	bounded.From, bounded.To = time.UnixMilli(20), time.UnixMilli(50)
+	ids, err = collect(t, fetch, bounded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 3, 4}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("within [20, 50): got %v, want %v", ids, want)
+	}
+}
+
+func TestDownloadWaitsOutRateLimitByDefault(t *testing.T) {
+	const retryAfter = 20 * time.Millisecond
+	calls := 0
+	fetch := func(ctx context.Context, cursor string) (*Page[item], error) {
+		calls++
+		if calls == 1 {
+			return nil, &client.RateLimitError{RetryAfter: retryAfter}
+		}
+		return &Page[item]{Items: []item{{1, 10}}}, nil
+	}
+
+	start := time.Now()
+	ids, err := collect(t, fetch, itemOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || calls != 2 {
+		t.Fatalf("got %v after %v calls", ids, calls)
+	}
+	if elapsed := time.Since(start); elapsed < retryAfter {
+		t.Fatalf("retried after %v, want at least %v", elapsed, retryAfter)
+	}
+
+	errOther := errors.New("other")
+	_, err = collect(t, func(context.Context, string) (*Page[item], error) { return nil, errOther }, itemOpts)
+	if !errors.Is(err, errOther) {
+		t.Fatalf("got %v, want the fetch error", err)
+	}
+}
+
+func TestTimeWindowFetcherWalksRangeInOrder(t *testing.T) {
+	from, to := time.UnixMilli(1000), time.UnixMilli(3500)
+	var windows [][2]int64
+	fetch := timeWindowFetcher(from, to, time.Second, func(ctx context.Context, start, end int64) ([]item, error) {
+		windows = append(windows, [2]int64{start, end})
+		return []item{{int(start), start}}, nil
+	})
+
+	ids, err := collect(t, fetch, DownloadOpts[item]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]int64{{1000, 2000}, {2000, 3000}, {3000, 3500}}; !reflect.DeepEqual(windows, want) {
+		t.Fatalf("windows %v, want %v", windows, want)
+	}
+	if want := []int{1000, 2000, 3000}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+}
+
+func TestTimeWindowFetchersRejectUnboundedRanges(t *testing.T) {
+	c := client.NewHTTPClient("http://localhost")
+	now := time.Now()
+	for _, tc := range []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"zero from", time.Time{}, now},
+		{"zero to", now.Add(-time.Hour), time.Time{}},
+		{"empty", now, now},
+		{"reversed", now, now.Add(-time.Hour)},
+	} {
+		if _, err := Candles(c, 1, "1m", tc.from, tc.to); err == nil {
+			t.Errorf("Candles accepted a %v range", tc.name)
+		}
+		if _, err := Fundings(c, 1, "1h", tc.from, tc.to); err == nil {
+			t.Errorf("Fundings accepted a %v range", tc.name)
+		}
+	}
+}
+
+func TestAccountTradesHonorsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			fmt.Fprint(w, `{"code":200,"trades":[]}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := AccountTrades(client.NewHTTPClient(srv.URL), 1, "auth")(ctx, ""); err == nil {
+		t.Fatal("request outlived its context")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("cancelled request took %v", elapsed)
+	}
+}
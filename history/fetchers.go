@@ -0,0 +1,135 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+const pageLimit = 100
+
+var resolutions = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// AccountTrades pages through the trades of the account, oldest first.
+func AccountTrades(c *client.HTTPClient, accountIndex int64, auth string) PageFetcher[*client.Trade] {
+	return func(ctx context.Context, cursor string) (*Page[*client.Trade], error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res, err := c.GetAccountTradesCtx(ctx, accountIndex, cursor, pageLimit, auth)
+		if err != nil {
+			return nil, err
+		}
+		return &Page[*client.Trade]{Items: res.Trades, NextCursor: res.NextCursor}, nil
+	}
+}
+
+// TradeOpts fills the Timestamp and ID functions for AccountTrades downloads.
+func TradeOpts(opts DownloadOpts[*client.Trade]) DownloadOpts[*client.Trade] {
+	opts.Timestamp = func(t *client.Trade) time.Time { return time.UnixMilli(t.Timestamp) }
+	opts.ID = func(t *client.Trade) string { return strconv.FormatInt(t.TradeId, 10) }
+	return opts
+}
+
+// Candles walks [from, to) in windows of pageLimit candles. The cursor is the window start in milliseconds.
+// Both bounds are required.
+func Candles(c *client.HTTPClient, marketIndex uint8, resolution string, from, to time.Time) (PageFetcher[*client.Candlestick], error) {
+	step, err := windowStep(resolution, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return timeWindowFetcher(from, to, step*pageLimit, func(ctx context.Context, start, end int64) ([]*client.Candlestick, error) {
+		res, err := c.GetCandlesticksCtx(ctx, marketIndex, resolution, start, end, pageLimit)
+		if err != nil {
+			return nil, err
+		}
+		return res.Candlesticks, nil
+	}), nil
+}
+
+// CandleOpts fills the Timestamp and ID functions for Candles downloads.
+func CandleOpts(opts DownloadOpts[*client.Candlestick]) DownloadOpts[*client.Candlestick] {
+	opts.Timestamp = func(c *client.Candlestick) time.Time { return time.UnixMilli(c.Timestamp) }
+	opts.ID = func(c *client.Candlestick) string { return strconv.FormatInt(c.Timestamp, 10) }
+	return opts
+}
+
+// Fundings walks [from, to) in windows of pageLimit funding entries. The cursor is the window start in milliseconds.
+// Both bounds are required.
+func Fundings(c *client.HTTPClient, marketIndex uint8, resolution string, from, to time.Time) (PageFetcher[*client.Funding], error) {
+	step, err := windowStep(resolution, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return timeWindowFetcher(from, to, step*pageLimit, func(ctx context.Context, start, end int64) ([]*client.Funding, error) {
+		res, err := c.GetFundingsCtx(ctx, marketIndex, resolution, start, end, pageLimit)
+		if err != nil {
+			return nil, err
+		}
+		return res.Fundings, nil
+	}), nil
+}
+
+// FundingOpts fills the Timestamp and ID functions for Fundings downloads.
+func FundingOpts(opts DownloadOpts[*client.Funding]) DownloadOpts[*client.Funding] {
+	opts.Timestamp = func(f *client.Funding) time.Time { return time.UnixMilli(f.Timestamp) }
+	opts.ID = func(f *client.Funding) string { return strconv.FormatInt(f.Timestamp, 10) }
+	return opts
+}
+
+// windowStep returns the duration of one entry of resolution, after checking [from, to) is a bounded, non-empty range.
+func windowStep(resolution string, from, to time.Time) (time.Duration, error) {
+	step, ok := resolutions[resolution]
+	if !ok {
+		return 0, fmt.Errorf("unknown resolution %q", resolution)
+	}
+	if from.IsZero() || to.IsZero() {
+		return 0, fmt.Errorf("from and to are required")
+	}
+	if !from.Before(to) {
+		return 0, fmt.Errorf("from %v is not before to %v", from, to)
+	}
+	return step, nil
+}
+
+// timeWindowFetcher turns an endpoint queried by [start, end) timestamps into a PageFetcher.
+func timeWindowFetcher[T any](from, to time.Time, window time.Duration, get func(ctx context.Context, start, end int64) ([]T, error)) PageFetcher[T] {
+	return func(ctx context.Context, cursor string) (*Page[T], error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		start := from.UnixMilli()
+		if cursor != "" {
+			var err error
+			start, err = strconv.ParseInt(cursor, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor %q. err: %w", cursor, err)
+			}
+		}
+		end := min(start+window.Milliseconds(), to.UnixMilli())
+
+		items, err := get(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		page := &Page[T]{Items: items}
+		if end < to.UnixMilli() {
+			page.NextCursor = strconv.FormatInt(end, 10)
+		}
+		return page, nil
+	}
+}
@@ -0,0 +1,103 @@
+package keys
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/elliottech/lighter-go/signer"
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	EthSignatureLength = 65
+
+	mnemonicDomain   = "lighter-go/api-key/bip39/v1"
+	masterSeedDomain = "lighter-go/api-key/master-seed/v1"
+
+	MinMasterSeedLength = 32
+)
+
+// ParseEthSignature decodes a 0x-prefixed or bare hex Ethereum signature (r || s || v).
+func ParseEthSignature(s string) ([]byte, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth signature hex. err: %v", err)
+	}
+	if len(sig) != EthSignatureLength {
+		return nil, fmt.Errorf("invalid eth signature length. expected: %d got: %d", EthSignatureLength, len(sig))
+	}
+	return sig, nil
+}
+
+// FromEthSignature deterministically derives an API key from a signature made by the user's Ethereum wallet,
+// so the key can be recovered by signing the same message again.
+//
+// The recovery id is normalized to 27/28 first, so wallets encoding it as 0/1 derive the same key. The 0x-prefixed
+// hex of the Keccak-256 hash of the signature is then the seed of curve.SampleScalar, as in the other SDKs.
+// The derivation must never change, or keys couldn't be recovered anymore.
+func FromEthSignature(sig []byte) (signer.KeyManager, error) {
+	if len(sig) != EthSignatureLength {
+		return nil, fmt.Errorf("invalid eth signature length. expected: %d got: %d", EthSignatureLength, len(sig))
+	}
+	normalized := slices.Clone(sig)
+	switch v := normalized[64]; v {
+	case 0, 1:
+		normalized[64] = v + 27
+	case 27, 28:
+	default:
+		return nil, fmt.Errorf("invalid eth signature recovery id %d", v)
+	}
+	seed := hexutil.Encode(crypto.Keccak256(normalized))
+	key := curve.SampleScalar(&seed)
+	return signer.NewKeyManager(key.ToLittleEndianBytes())
+}
+
+// deriveKey reduces the SHA-512 hash of domain || material into a private key.
+func deriveKey(domain string, material []byte) (signer.KeyManager, error) {
+	h := sha512.New()
+	h.Write([]byte(domain))
+	h.Write(material)
+	n := new(big.Int).SetBytes(h.Sum(nil))
+	n.Mod(n, curve.ORDER)
+	if n.Sign() == 0 {
+		return nil, fmt.Errorf("derived a zero private key")
+	}
+
+	b := n.FillBytes(make([]byte, 40))
+	slices.Reverse(b) // little-endian
+	return signer.NewKeyManager(b)
+}
+
+// FromMnemonic deterministically derives the API key of slot index from a BIP-39 mnemonic, so one phrase can back
+// every API key index of an account. The mnemonic checksum is validated, and no passphrase is used.
+// The derivation is versioned by its domain string and must never change.
+func FromMnemonic(mnemonic string, index uint32) (signer.KeyManager, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic. err: %v", err)
+	}
+	return deriveKey(mnemonicDomain, binary.BigEndian.AppendUint32(seed, index))
+}
+
+// DeriveApiKey deterministically derives the key of an API key slot of an account from a master secret, so the keys
+// of every slot can be backed up as that one value. The master seed should have at least 256 bits of entropy.
+// The derivation is versioned by its domain string and must never change.
+func DeriveApiKey(masterSeed []byte, accountIndex int64, apiKeyIndex uint8) (signer.KeyManager, error) {
+	if len(masterSeed) < MinMasterSeedLength {
+		return nil, fmt.Errorf("master seed is too short. expected at least %d bytes got: %d", MinMasterSeedLength, len(masterSeed))
+	}
+	if accountIndex < 0 {
+		return nil, fmt.Errorf("invalid account index %d", accountIndex)
+	}
+	material := slices.Concat(masterSeed, binary.BigEndian.AppendUint64(nil, uint64(accountIndex)), []byte{apiKeyIndex})
+	defer clear(material)
+	return deriveKey(masterSeedDomain, material)
+}
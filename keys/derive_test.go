@@ -0,0 +1,164 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/elliottech/lighter-go/signer"
+)
+
+type goldenKey struct {
+	privateKey string // little-endian hex, as GenerateAPIKey returns it without 0x
+	publicKey  string
+}
+
+func checkGoldenKey(t *testing.T, name string, keyManager signer.KeyManager, err error, want goldenKey) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+	if got := hex.EncodeToString(keyManager.PrvKeyBytes()); got != want.privateKey {
+		t.Errorf("%v: private key %v, want %v", name, got, want.privateKey)
+	}
+	if got := FromKeyManager(keyManager).Hex(); got != want.publicKey {
+		t.Errorf("%v: public key %v, want %v", name, got, want.publicKey)
+	}
+}
+
+func TestFromEthSignatureGolden(t *testing.T) {
+	rs1 := strings.Repeat("11", 32) + strings.Repeat("22", 32)
+	rs2 := strings.Repeat("ab", 32) + strings.Repeat("cd", 32)
+	key1 := goldenKey{
+		privateKey: "acd4cfec0fcdaa88bc343ea36511de4e113e878ffbb2564fe10ff2dcb70e3c1e1b77550a92d57a7b",
+		publicKey:  "0x8976ff925dc9a5a567c412f3ddddab0a32546ea889dd1c935f97c4e1299c9a776edc414e4334bd21",
+	}
+	key2 := goldenKey{
+		privateKey: "27fa891662798c623d9d2d08228bbd88e70ceb9e16423b2ab7bd4e88c70a5432b9d34207f5d2426d",
+		publicKey:  "0x979fd02c14d747f38e2ecdb61fbaea3a8df38dcab75fd4c804830aae8eda4fcecceb7a4df71ec934",
+	}
+
+	for _, tc := range []struct {
+		signature string
+		want      goldenKey
+	}{
+		{"0x" + rs1 + "1b", key1},
+		{rs1 + "00", key1}, // v = 0 is v = 27
+		{"0x" + rs2 + "1c", key2},
+		{"0x" + rs2 + "01", key2}, // v = 1 is v = 28
+	} {
+		sig, err := ParseEthSignature(tc.signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyManager, err := FromEthSignature(sig)
+		checkGoldenKey(t, tc.signature, keyManager, err, tc.want)
+	}
+}
+
+func TestFromEthSignatureRejectsMalformed(t *testing.T) {
+	rs := strings.Repeat("11", 64)
+	for _, signature := range []string{
+		"",
+		"0x" + rs,                // no recovery id
+		"0x" + rs + "1b00",       // too long
+		"0x" + rs + "1",          // odd length
+		"0x" + rs[:126] + "zz1b", // not hex
+	} {
+		if _, err := ParseEthSignature(signature); err == nil {
+			t.Errorf("ParseEthSignature(%q) succeeded", signature)
+		}
+	}
+
+	sig, err := ParseEthSignature(rs + "1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromEthSignature(sig); err == nil {
+		t.Error("recovery id 29 accepted")
+	}
+	if _, err := FromEthSignature(sig[:64]); err == nil {
+		t.Error("64-byte signature accepted")
+	}
+}
+
+// testMnemonic is the all-zero entropy BIP-39 test mnemonic.
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestFromMnemonicGolden(t *testing.T) {
+	for _, tc := range []struct {
+		index uint32
+		want  goldenKey
+	}{
+		{0, goldenKey{
+			privateKey: "80d85e5ca3d9011ead799a1e27cf3c5d1a29a6d10e40a87e8be7d70e9f130002dd4eaa33e09ad913",
+			publicKey:  "0x81414ad732daa5b9dc03809d38c6dde4279cc2cb951242f3a97b47d7187ba5f1dd84009fa7e35b1b",
+		}},
+		{1, goldenKey{
+			privateKey: "85306390da5578d50c37be34e28c52f8bfb59ccf84ddd9ad613ac2df5c3cfb509b60e15d1ea2227b",
+			publicKey:  "0x0a2e17099039e48493ccb7b2bb3c489ed6772520799967a50ade709ec865cc4a0bb9354713547590",
+		}},
+		{255, goldenKey{
+			privateKey: "014eec465f8643e85e3fe0ca80efdf8b5d33a024013e697681a54db5daf72a2a4be745bdcc3afb05",
+			publicKey:  "0x5b84e1d2e4a049a9708e66d510513b9a4d4107084f6bb3d6204945eb8c5be250befd7ac621a1850b",
+		}},
+	} {
+		keyManager, err := FromMnemonic(testMnemonic, tc.index)
+		checkGoldenKey(t, fmt.Sprintf("index %v", tc.index), keyManager, err, tc.want)
+	}
+}
+
+func TestFromMnemonicRejectsInvalid(t *testing.T) {
+	for _, mnemonic := range []string{
+		"",
+		strings.Repeat("abandon ", 11) + "abandon", // bad checksum
+		strings.Repeat("abandon ", 11) + "notaword",
+		strings.Repeat("abandon ", 10) + "about", // 11 words
+	} {
+		if _, err := FromMnemonic(mnemonic, 0); err == nil {
+			t.Errorf("FromMnemonic(%q) succeeded", mnemonic)
+		}
+	}
+}
+
+func TestDeriveApiKeyGolden(t *testing.T) {
+	masterSeed := make([]byte, MinMasterSeedLength)
+	for i := range masterSeed {
+		masterSeed[i] = byte(i)
+	}
+	for _, tc := range []struct {
+		accountIndex int64
+		apiKeyIndex  uint8
+		want         goldenKey
+	}{
+		{1, 0, goldenKey{
+			privateKey: "1ab50971ca2a6b466b74d2ced7555fe2499ef06f2c8e3bcf3fbcb891cac339b1cc38a1afe2d8e574",
+			publicKey:  "0xfe2d44611e72addc3dd38031507bcf5a332fb98be2e7d1f95dd545819a376dfa7b7ea0091231ba46",
+		}},
+		{1, 1, goldenKey{
+			privateKey: "34a65c52f0836e14517424ee8c623d032a5a597d0e5e656f1e6c01271f8d506bb84a227050bc2f1a",
+			publicKey:  "0xfe72307e8b268818c9f75401cfa8166d932e86c8e2353925489f43ee265c3284691b3c6fdb811f10",
+		}},
+		{1, 255, goldenKey{
+			privateKey: "dcfe19bc2a5c8bde31ba33954393e15f60604fd9ee2a003404815dbe3f64ad895508727fa939b22d",
+			publicKey:  "0xc2ee93929a6a6df40fcf65040f3b17802bbed0917cb01be75a485612351a0b22c67cf5cad0bce444",
+		}},
+		{2, 0, goldenKey{
+			privateKey: "0dd6ecdba1002d624e240c9522eab362d50e9b0c922715ffae83a94632ef301e4db229d6c15ea334",
+			publicKey:  "0xc3bdc1e047107e6f28b0a88c2113ce7fb46d1218e284a487fea9a73c8ee0ceb37525ffc882c86212",
+		}},
+	} {
+		keyManager, err := DeriveApiKey(masterSeed, tc.accountIndex, tc.apiKeyIndex)
+		checkGoldenKey(t, fmt.Sprintf("account %v api key %v", tc.accountIndex, tc.apiKeyIndex), keyManager, err, tc.want)
+	}
+}
+
+func TestDeriveApiKeyRejectsInvalid(t *testing.T) {
+	if _, err := DeriveApiKey(make([]byte, MinMasterSeedLength-1), 1, 0); err == nil {
+		t.Error("short master seed accepted")
+	}
+	if _, err := DeriveApiKey(make([]byte, MinMasterSeedLength), -1, 0); err == nil {
+		t.Error("negative account index accepted")
+	}
+}
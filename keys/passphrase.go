@@ -0,0 +1,94 @@
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrEmptyPassphrase = errors.New("passphrase should not be empty")
+	// ErrDecrypt doesn't tell a wrong passphrase from a tampered blob, on purpose.
+	ErrDecrypt = errors.New("failed to decrypt: wrong passphrase or corrupted data")
+)
+
+const (
+	sealVersion    byte = 1
+	sealSaltSize        = 16
+	sealKeySize         = 32
+	sealScryptN         = 1 << 15
+	sealScryptR         = 8
+	sealScryptP         = 1
+	sealHeaderSize      = 1 + sealSaltSize
+)
+
+// Seal encrypts data with a key derived from the passphrase (scrypt, then AES-256-GCM) and returns it as base64.
+// The version and salt are authenticated along with the data, so any change to the blob is detected by Open.
+func Seal(data []byte, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrEmptyPassphrase
+	}
+	header := make([]byte, sealHeaderSize)
+	header[0] = sealVersion
+	if _, err := rand.Read(header[1:]); err != nil {
+		return "", err
+	}
+	gcm, err := sealCipher(passphrase, header[1:])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	blob := append(header, nonce...)
+	blob = gcm.Seal(blob, nonce, data, header)
+	return base64.StdEncoding.EncodeToString(blob), nil
+}
+
+// Open decrypts a blob made by Seal. It fails with ErrDecrypt for a wrong passphrase or a modified blob.
+func Open(sealed string, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	blob, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64", ErrDecrypt)
+	}
+	if len(blob) < sealHeaderSize || blob[0] != sealVersion {
+		return nil, fmt.Errorf("%w: unknown format", ErrDecrypt)
+	}
+	header := blob[:sealHeaderSize]
+	gcm, err := sealCipher(passphrase, header[1:])
+	if err != nil {
+		return nil, err
+	}
+	rest := blob[sealHeaderSize:]
+	if len(rest) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, fmt.Errorf("%w: truncated", ErrDecrypt)
+	}
+
+	data, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], header)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return data, nil
+}
+
+func sealCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, sealScryptN, sealScryptR, sealScryptP, sealKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,50 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/elliottech/lighter-go/signer"
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+)
+
+const PrivateKeyLength = 40
+
+// ParsePrivateKey decodes a hex-encoded private key, with or without 0x prefix, and checks it's a
+// non-zero canonical scalar of the curve.
+func ParsePrivateKey(s string) (signer.KeyManager, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("private key is not valid hex. err: %v", err)
+	}
+	defer clear(b)
+	return PrivateKeyFromBytes(b)
+}
+
+// PrivateKeyFromBytes is ParsePrivateKey for the raw little-endian bytes of a key, with the same checks.
+// The KeyManager doesn't keep b, so the caller may wipe it afterwards.
+func PrivateKeyFromBytes(b []byte) (signer.KeyManager, error) {
+	if len(b) != PrivateKeyLength {
+		return nil, fmt.Errorf("invalid private key length. expected: %d got: %d", PrivateKeyLength, len(b))
+	}
+
+	be := slices.Clone(b)
+	defer clear(be)
+	slices.Reverse(be)
+	n := new(big.Int).SetBytes(be)
+	if n.Sign() == 0 {
+		return nil, fmt.Errorf("private key is zero")
+	}
+	if n.Cmp(curve.ORDER) >= 0 {
+		return nil, fmt.Errorf("private key is out of range for the curve order")
+	}
+	return signer.NewKeyManager(b)
+}
+
+// Generate returns a new random key.
+func Generate() (signer.KeyManager, error) {
+	return signer.NewKeyManager(curve.SampleScalar(nil).ToLittleEndianBytes())
+}
@@ -0,0 +1,97 @@
+package keys
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/elliottech/lighter-go/signer"
+	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
+)
+
+const (
+	PubKeyLength = 40
+
+	// goldilocksModulus is the order of the field the public key limbs belong to.
+	goldilocksModulus uint64 = 0xFFFFFFFF00000001
+)
+
+// PubKey is a Schnorr public key, in its little-endian byte encoding.
+type PubKey [PubKeyLength]byte
+
+func FromKeyManager(km signer.KeyManager) PubKey {
+	return PubKey(km.PubKeyBytes())
+}
+
+// FromBytes parses the little-endian encoding of a public key, as returned by Bytes.
+func FromBytes(b []byte) (PubKey, error) {
+	var pk PubKey
+	if len(b) != PubKeyLength {
+		return pk, fmt.Errorf("invalid public key length. expected: %d got: %d", PubKeyLength, len(b))
+	}
+	if _, err := gFp5.FromCanonicalLittleEndianBytes(b); err != nil {
+		return pk, fmt.Errorf("invalid public key. err: %v", err)
+	}
+	copy(pk[:], b)
+	return pk, nil
+}
+
+// ParseHex parses the 0x-prefixed hex encoding of a public key, as returned by Hex.
+func ParseHex(s string) (PubKey, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return PubKey{}, fmt.Errorf("public key %q is missing its 0x prefix", s)
+	}
+	return ParseAPIHex(s[2:])
+}
+
+// ParseAPIHex parses the prefix-less hex encoding used by the API, as returned by APIHex.
+func ParseAPIHex(s string) (PubKey, error) {
+	if strings.HasPrefix(s, "0x") {
+		return PubKey{}, fmt.Errorf("public key %q should not have a 0x prefix", s)
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return PubKey{}, fmt.Errorf("invalid public key hex. err: %v", err)
+	}
+	return FromBytes(b)
+}
+
+// FromElements builds a public key from its field element limbs, as returned by Elements.
+func FromElements(limbs [5]uint64) (PubKey, error) {
+	for i, limb := range limbs {
+		if limb >= goldilocksModulus {
+			return PubKey{}, fmt.Errorf("public key limb %d is not a canonical field element", i)
+		}
+	}
+	var pk PubKey
+	copy(pk[:], gFp5.FromUint64Array(limbs).ToLittleEndianBytes())
+	return pk, nil
+}
+
+func (pk PubKey) Bytes() []byte {
+	return append([]byte(nil), pk[:]...)
+}
+
+// Hex returns the 0x-prefixed lowercase hex encoding.
+func (pk PubKey) Hex() string {
+	return "0x" + pk.APIHex()
+}
+
+// APIHex returns the lowercase hex encoding without prefix, as used by the API.
+func (pk PubKey) APIHex() string {
+	return hex.EncodeToString(pk[:])
+}
+
+// Elements returns the 5 little-endian limbs of the public key's quintic extension field element.
+func (pk PubKey) Elements() [5]uint64 {
+	var limbs [5]uint64
+	for i := range limbs {
+		limbs[i] = binary.LittleEndian.Uint64(pk[8*i : 8*i+8])
+	}
+	return limbs
+}
+
+func (pk PubKey) String() string {
+	return pk.Hex()
+}
@@ -0,0 +1,84 @@
+package keys
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testPubKey(t *testing.T) PubKey {
+	t.Helper()
+	keyManager, err := DeriveApiKey(make([]byte, MinMasterSeedLength), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return FromKeyManager(keyManager)
+}
+
+func TestPubKeyRoundTrips(t *testing.T) {
+	pk := testPubKey(t)
+	if !strings.HasPrefix(pk.Hex(), "0x") || pk.Hex()[2:] != pk.APIHex() || pk.String() != pk.Hex() {
+		t.Fatalf("Hex %v, APIHex %v, String %v", pk.Hex(), pk.APIHex(), pk.String())
+	}
+
+	for name, parse := range map[string]func() (PubKey, error){
+		"hex":      func() (PubKey, error) { return ParseHex(pk.Hex()) },
+		"API hex":  func() (PubKey, error) { return ParseAPIHex(pk.APIHex()) },
+		"bytes":    func() (PubKey, error) { return FromBytes(pk.Bytes()) },
+		"elements": func() (PubKey, error) { return FromElements(pk.Elements()) },
+	} {
+		got, err := parse()
+		if err != nil || got != pk {
+			t.Errorf("%v: got %v, %v, want %v", name, got, err, pk)
+		}
+	}
+
+	b := pk.Bytes()
+	b[0] ^= 1
+	if bytes.Equal(b, pk[:]) {
+		t.Fatal("Bytes returned the key's own array")
+	}
+}
+
+func TestPubKeyBadInputs(t *testing.T) {
+	pk := testPubKey(t)
+	nonCanonical := strings.Repeat("ff", PubKeyLength)
+
+	for name, s := range map[string]string{
+		"no prefix":     pk.APIHex(),
+		"short":         pk.Hex()[:len(pk.Hex())-2],
+		"long":          pk.Hex() + "00",
+		"non-hex":       "0x" + strings.Repeat("zz", PubKeyLength),
+		"non-canonical": "0x" + nonCanonical,
+		"empty":         "",
+	} {
+		if got, err := ParseHex(s); err == nil {
+			t.Errorf("ParseHex %v: accepted as %v", name, got)
+		}
+	}
+	for name, s := range map[string]string{
+		"prefix":        pk.Hex(),
+		"odd":           pk.APIHex()[1:],
+		"non-canonical": nonCanonical,
+		"empty":         "",
+	} {
+		if got, err := ParseAPIHex(s); err == nil {
+			t.Errorf("ParseAPIHex %v: accepted as %v", name, got)
+		}
+	}
+	for name, b := range map[string][]byte{
+		"short": pk[:PubKeyLength-1],
+		"long":  append(pk.Bytes(), 0),
+		"nil":   nil,
+	} {
+		if got, err := FromBytes(b); err == nil {
+			t.Errorf("FromBytes %v: accepted as %v", name, got)
+		}
+	}
+
+	limbs := pk.Elements()
+	limbs[3] = goldilocksModulus
+	if got, err := FromElements(limbs); err == nil {
+		t.Errorf("FromElements with a non-canonical limb: accepted as %v", got)
+	}
+}
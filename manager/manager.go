@@ -0,0 +1,250 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const defaultConcurrency = 4
+
+var ErrClosed = errors.New("manager is closed")
+
+// KeySource returns the hex-encoded private key of an API key index.
+type KeySource func(apiKeyIndex uint8) (string, error)
+
+// StaticKeys is a KeySource backed by a map.
+func StaticKeys(keys map[uint8]string) KeySource {
+	return func(apiKeyIndex uint8) (string, error) {
+		key, ok := keys[apiKeyIndex]
+		if !ok {
+			return "", fmt.Errorf("no private key for api key %v", apiKeyIndex)
+		}
+		return key, nil
+	}
+}
+
+type AccountConfig struct {
+	AccountIndex int64
+	ChainId      uint32
+	Keys         KeySource
+	// ApiKeyIndexes lists the API keys to create clients for. The first one is the account's default client.
+	ApiKeyIndexes []uint8
+}
+
+type clientKey struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+}
+
+// Manager owns the TxClients of several accounts, all sharing one HTTPClient.
+type Manager struct {
+	concurrency int
+
+	mu       sync.RWMutex
+	closed   bool
+	accounts []int64
+	defaults map[int64]*client.TxClient
+	clients  map[clientKey]*client.TxClient
+
+	closeOnce sync.Once
+}
+
+// New creates the clients of the accounts, sending through requester, usually an HTTPClient. The clients own
+// their keys: Close wipes them.
+func New(requester client.L2Requester, accounts []AccountConfig) (*Manager, error) {
+	m := &Manager{
+		concurrency: defaultConcurrency,
+		defaults:    make(map[int64]*client.TxClient),
+		clients:     make(map[clientKey]*client.TxClient),
+	}
+
+	for _, account := range accounts {
+		if _, ok := m.defaults[account.AccountIndex]; ok {
+			return nil, fmt.Errorf("account %v is configured twice", account.AccountIndex)
+		}
+		if len(account.ApiKeyIndexes) == 0 {
+			return nil, fmt.Errorf("no api keys configured for account %v", account.AccountIndex)
+		}
+		if account.Keys == nil {
+			return nil, fmt.Errorf("no key source configured for account %v", account.AccountIndex)
+		}
+
+		for _, apiKeyIndex := range account.ApiKeyIndexes {
+			privateKey, err := account.Keys(apiKeyIndex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get private key for account %v api key %v. err: %w", account.AccountIndex, apiKeyIndex, err)
+			}
+			txClient, err := client.NewTxClient(requester, privateKey, account.AccountIndex, apiKeyIndex, account.ChainId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create client for account %v api key %v. err: %w", account.AccountIndex, apiKeyIndex, err)
+			}
+			m.clients[clientKey{account.AccountIndex, apiKeyIndex}] = txClient
+			if _, ok := m.defaults[account.AccountIndex]; !ok {
+				m.defaults[account.AccountIndex] = txClient
+			}
+		}
+		m.accounts = append(m.accounts, account.AccountIndex)
+	}
+
+	return m, nil
+}
+
+// SetConcurrency bounds the number of accounts Broadcast works on at the same time.
+func (m *Manager) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.concurrency = n
+}
+
+// Client returns the default client of the account, or nil if the account is unknown or the manager is closed.
+func (m *Manager) Client(accountIndex int64) *client.TxClient {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.defaults[accountIndex]
+}
+
+// ClientFor returns the client of a specific API key of the account, or nil if it's not configured.
+func (m *Manager) ClientFor(accountIndex int64, apiKeyIndex uint8) *client.TxClient {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.clients[clientKey{accountIndex, apiKeyIndex}]
+}
+
+// Accounts returns the managed account indexes, in configuration order.
+func (m *Manager) Accounts() []int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]int64(nil), m.accounts...)
+}
+
+// BroadcastError maps each failed account to its error.
+type BroadcastError struct {
+	Errors map[int64]error
+}
+
+func (e *BroadcastError) Error() string {
+	accounts := make([]int64, 0, len(e.Errors))
+	for accountIndex := range e.Errors {
+		accounts = append(accounts, accountIndex)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i] < accounts[j] })
+
+	parts := make([]string, 0, len(accounts))
+	for _, accountIndex := range accounts {
+		parts = append(parts, fmt.Sprintf("account %v: %v", accountIndex, e.Errors[accountIndex]))
+	}
+	return fmt.Sprintf("%d account(s) failed: %s", len(accounts), strings.Join(parts, "; "))
+}
+
+func (e *BroadcastError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Broadcast runs fn on the default client of every account, with bounded concurrency.
+// It waits for all accounts and returns a *BroadcastError listing the ones that failed, or nil.
+func (m *Manager) Broadcast(fn func(*client.TxClient) error) error {
+	m.mu.RLock()
+	if m.closed {
+		m.mu.RUnlock()
+		return ErrClosed
+	}
+	accounts := append([]int64(nil), m.accounts...)
+	clients := make([]*client.TxClient, len(accounts))
+	for i, accountIndex := range accounts {
+		clients[i] = m.defaults[accountIndex]
+	}
+	concurrency := m.concurrency
+	m.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		errM sync.Mutex
+		errs = make(map[int64]error)
+		sem  = make(chan struct{}, concurrency)
+	)
+	for i := range accounts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(accountIndex int64, txClient *client.TxClient) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					errM.Lock()
+					errs[accountIndex] = fmt.Errorf("panic: %v", r)
+					errM.Unlock()
+				}
+			}()
+
+			if err := fn(txClient); err != nil {
+				errM.Lock()
+				errs[accountIndex] = err
+				errM.Unlock()
+			}
+		}(accounts[i], clients[i])
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &BroadcastError{Errors: errs}
+	}
+	return nil
+}
+
+// CancelAllEverywhere immediately cancels all open orders of every account.
+func (m *Manager) CancelAllEverywhere(ctx context.Context) error {
+	return m.Broadcast(func(c *client.TxClient) error {
+		tx, err := c.GetCancelAllOrdersTransaction(&types.CancelAllOrdersTxReq{
+			TimeInForce: txtypes.ImmediateCancelAll,
+			Time:        txtypes.NilOrderExpiry,
+		}, &types.TransactOpts{Ctx: ctx})
+		if err != nil {
+			return err
+		}
+		_, err = c.SendTx(ctx, tx)
+		return err
+	})
+}
+
+// Close releases every client and the shared HTTP resources. The clients handed out by Client and ClientFor are
+// closed and their keys wiped, so they can't sign anymore. It's safe to call more than once.
+func (m *Manager) Close() error {
+	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		clients := m.clients
+		m.closed = true
+		m.defaults = make(map[int64]*client.TxClient)
+		m.clients = make(map[clientKey]*client.TxClient)
+		m.accounts = nil
+		m.mu.Unlock()
+
+		httpClients := make(map[*client.HTTPClient]bool)
+		for _, c := range clients {
+			c.Close()
+			if wiper, ok := c.GetKeyManager().(signer.Wiper); ok {
+				wiper.Wipe()
+			}
+			if httpClient := c.HTTP(); httpClient != nil && !httpClients[httpClient] {
+				httpClients[httpClient] = true
+				httpClient.CloseIdleConnections()
+			}
+		}
+	})
+	return nil
+}
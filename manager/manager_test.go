@@ -0,0 +1,121 @@
+package manager
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/client/clienttest"
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var testAccounts = []int64{1, 2, 3}
+
+func newTestManager(t *testing.T, requester client.L2Requester) *Manager {
+	t.Helper()
+	configs := make([]AccountConfig, 0, len(testAccounts))
+	for _, accountIndex := range testAccounts {
+		keyManager, err := keys.DeriveApiKey(make([]byte, keys.MinMasterSeedLength), accountIndex, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configs = append(configs, AccountConfig{
+			AccountIndex:  accountIndex,
+			ChainId:       304,
+			Keys:          StaticKeys(map[uint8]string{0: hex.EncodeToString(keyManager.PrvKeyBytes())}),
+			ApiKeyIndexes: []uint8{0},
+		})
+	}
+	m, err := New(requester, configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestCancelAllEverywhereWithOneFailure(t *testing.T) {
+	errRejected := errors.New("rejected")
+	requester := &clienttest.FakeRequester{
+		SendRawTxFunc: func(ctx context.Context, tx txtypes.TxInfo, opts client.SendOpts) (string, error) {
+			if accountIndex, _, _, _ := client.TxNonce(tx); accountIndex == 2 {
+				return "", errRejected
+			}
+			return tx.GetTxHash(), nil
+		},
+	}
+	m := newTestManager(t, requester)
+
+	err := m.CancelAllEverywhere(context.Background())
+	var broadcastErr *BroadcastError
+	if !errors.As(err, &broadcastErr) {
+		t.Fatalf("got %v, want a BroadcastError", err)
+	}
+	if len(broadcastErr.Errors) != 1 || !errors.Is(broadcastErr.Errors[2], errRejected) {
+		t.Fatalf("got errors %v, want account 2 only", broadcastErr.Errors)
+	}
+	if !errors.Is(err, errRejected) {
+		t.Fatal("BroadcastError doesn't unwrap to the account's error")
+	}
+
+	cancelled := make(map[int64]bool)
+	for _, tx := range requester.Sent() {
+		if tx.GetTxType() != txtypes.TxTypeL2CancelAllOrders {
+			t.Fatalf("sent a tx of type %v", tx.GetTxType())
+		}
+		accountIndex, _, _, _ := client.TxNonce(tx)
+		cancelled[accountIndex] = true
+	}
+	for _, accountIndex := range testAccounts {
+		if !cancelled[accountIndex] {
+			t.Errorf("no cancel-all sent for account %v", accountIndex)
+		}
+	}
+}
+
+func TestCancelAllEverywhereHonorsContext(t *testing.T) {
+	requester := &clienttest.FakeRequester{
+		NextNonceFunc: func(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+			if err := ctx.Err(); err != nil {
+				return -1, fmt.Errorf("nonce of account %v: %w", accountIndex, err)
+			}
+			return 0, nil
+		},
+	}
+	m := newTestManager(t, requester)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := m.CancelAllEverywhere(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if sent := len(requester.Sent()); sent != 0 {
+		t.Fatalf("%v txs sent with a cancelled context", sent)
+	}
+}
+
+func TestCloseDisablesClients(t *testing.T) {
+	m := newTestManager(t, &clienttest.FakeRequester{})
+	c := m.Client(1)
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetCancelAllOrdersTransaction(nil, nil); !errors.Is(err, client.ErrClientClosed) {
+		t.Fatalf("closed client signed: %v", err)
+	}
+	if m.Client(1) != nil {
+		t.Fatal("closed manager still hands out clients")
+	}
+	if err := m.Broadcast(func(*client.TxClient) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Broadcast after Close: %v", err)
+	}
+}
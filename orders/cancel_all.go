@@ -0,0 +1,27 @@
+package orders
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var (
+	minCancelAllDelay = time.Duration(txtypes.MinOrderCancelAllPeriod) * time.Millisecond
+	maxCancelAllDelay = time.Duration(txtypes.MaxOrderCancelAllPeriod) * time.Millisecond
+)
+
+// ScheduledCancelAll returns the CancelAllOrders request cancelling every order delay after now.
+// A zero delay cancels them immediately; otherwise delay must be between txtypes.MinOrderCancelAllPeriod
+// and txtypes.MaxOrderCancelAllPeriod.
+func ScheduledCancelAll(delay time.Duration, now time.Time) (*types.CancelAllOrdersTxReq, error) {
+	if delay == 0 {
+		return &types.CancelAllOrdersTxReq{TimeInForce: txtypes.ImmediateCancelAll, Time: txtypes.NilOrderExpiry}, nil
+	}
+	if delay < minCancelAllDelay || delay > maxCancelAllDelay {
+		return nil, fmt.Errorf("%w: delay should be 0 or between %v and %v, got %v", txtypes.ErrCancelAllTimeIsNotInRange, minCancelAllDelay, maxCancelAllDelay, delay)
+	}
+	return &types.CancelAllOrdersTxReq{TimeInForce: txtypes.ScheduledCancelAll, Time: now.Add(delay).UnixMilli()}, nil
+}
@@ -0,0 +1,87 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	// DefaultExpiry asks for the default expiry of the order: DefaultExpiryPeriod from now when the order
+	// needs one, the nil sentinel otherwise.
+	DefaultExpiry int64 = -1
+
+	DefaultExpiryPeriod = 28 * 24 * time.Hour
+
+	// DefaultTimeInForce asks for the default TimeInForce of the client signing the order.
+	DefaultTimeInForce uint8 = 255
+)
+
+var (
+	ErrExpiryNotAllowed = errors.New("order expiry must not be set for immediate orders")
+	ErrExpiryInPast     = errors.New("order expiry is in the past")
+	ErrExpiryTooFar     = fmt.Errorf("order expiry is more than %v from now", time.Duration(txtypes.MaxOrderExpiryPeriod)*time.Millisecond)
+)
+
+// ResolveExpiry returns the OrderExpiry (in milliseconds) of a limit order with the given TimeInForce:
+//   - ImmediateOrCancel orders carry txtypes.NilOrderExpiry; any other requested value is rejected.
+//   - GoodTillTime and PostOnly orders need a real expiry: DefaultExpiryPeriod from now when requested is
+//     DefaultExpiry or txtypes.NilOrderExpiry, otherwise requested, which must be in the future and no further
+//     than txtypes.MaxOrderExpiryPeriod.
+func ResolveExpiry(tif uint8, requested int64, now time.Time) (int64, error) {
+	return resolveExpiry(tif, requested, now, DefaultExpiryPeriod)
+}
+
+func resolveExpiry(tif uint8, requested int64, now time.Time, period time.Duration) (int64, error) {
+	switch tif {
+	case txtypes.ImmediateOrCancel:
+		return immediateExpiry(requested)
+	case txtypes.GoodTillTime, txtypes.PostOnly:
+		return timedExpiry(requested, now, period)
+	default:
+		return 0, txtypes.ErrOrderTimeInForceInvalid
+	}
+}
+
+// ResolveOrderExpiry is ResolveExpiry for any order type. Market orders never expire, while conditional
+// (stop loss, take profit) and TWAP orders always need an expiry, whatever their TimeInForce.
+func ResolveOrderExpiry(orderType uint8, tif uint8, requested int64, now time.Time) (int64, error) {
+	return ResolveOrderExpiryWithPeriod(orderType, tif, requested, now, DefaultExpiryPeriod)
+}
+
+// ResolveOrderExpiryWithPeriod is ResolveOrderExpiry with period used instead of DefaultExpiryPeriod.
+func ResolveOrderExpiryWithPeriod(orderType uint8, tif uint8, requested int64, now time.Time, period time.Duration) (int64, error) {
+	switch orderType {
+	case txtypes.MarketOrder:
+		return immediateExpiry(requested)
+	case txtypes.LimitOrder:
+		return resolveExpiry(tif, requested, now, period)
+	case txtypes.StopLossOrder, txtypes.StopLossLimitOrder, txtypes.TakeProfitOrder, txtypes.TakeProfitLimitOrder, txtypes.TWAPOrder:
+		return timedExpiry(requested, now, period)
+	default:
+		return 0, txtypes.ErrOrderTypeInvalid
+	}
+}
+
+func immediateExpiry(requested int64) (int64, error) {
+	if requested != DefaultExpiry && requested != txtypes.NilOrderExpiry {
+		return 0, ErrExpiryNotAllowed
+	}
+	return txtypes.NilOrderExpiry, nil
+}
+
+func timedExpiry(requested int64, now time.Time, period time.Duration) (int64, error) {
+	if requested == DefaultExpiry || requested == txtypes.NilOrderExpiry {
+		return now.Add(period).UnixMilli(), nil
+	}
+	nowMs := now.UnixMilli()
+	if requested <= nowMs {
+		return 0, ErrExpiryInPast
+	}
+	if requested-nowMs > txtypes.MaxOrderExpiryPeriod {
+		return 0, ErrExpiryTooFar
+	}
+	return requested, nil
+}
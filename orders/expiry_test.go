@@ -0,0 +1,112 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var (
+	now       = time.UnixMilli(1_700_000_000_000)
+	past      = now.Add(-time.Hour).UnixMilli()
+	future    = now.Add(time.Hour).UnixMilli()
+	farFuture = now.Add(31 * 24 * time.Hour).UnixMilli()
+)
+
+type expiryCase struct {
+	name      string
+	requested int64
+	want      int64
+	err       error
+}
+
+// immediateCases are the expiries of an order which must not expire.
+var immediateCases = []expiryCase{
+	{"unset", DefaultExpiry, txtypes.NilOrderExpiry, nil},
+	{"nil", txtypes.NilOrderExpiry, txtypes.NilOrderExpiry, nil},
+	{"past", past, 0, ErrExpiryNotAllowed},
+	{"future", future, 0, ErrExpiryNotAllowed},
+	{"far future", farFuture, 0, ErrExpiryNotAllowed},
+}
+
+// timedCases are the expiries of an order which must expire, with a default period of period.
+func timedCases(period time.Duration) []expiryCase {
+	return []expiryCase{
+		{"unset", DefaultExpiry, now.Add(period).UnixMilli(), nil},
+		{"nil", txtypes.NilOrderExpiry, now.Add(period).UnixMilli(), nil},
+		{"past", past, 0, ErrExpiryInPast},
+		{"now", now.UnixMilli(), 0, ErrExpiryInPast},
+		{"future", future, future, nil},
+		{"at the max period", now.UnixMilli() + txtypes.MaxOrderExpiryPeriod, now.UnixMilli() + txtypes.MaxOrderExpiryPeriod, nil},
+		{"far future", farFuture, 0, ErrExpiryTooFar},
+	}
+}
+
+func checkExpiry(t *testing.T, name string, tc expiryCase, got int64, err error) {
+	t.Helper()
+	if tc.err != nil {
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%v, %v: got %v, %v, want %v", name, tc.name, got, err, tc.err)
+		}
+		return
+	}
+	if err != nil || got != tc.want {
+		t.Errorf("%v, %v: got %v, %v, want %v", name, tc.name, got, err, tc.want)
+	}
+}
+
+func TestResolveExpiry(t *testing.T) {
+	for _, tif := range []struct {
+		name  string
+		tif   uint8
+		cases []expiryCase
+	}{
+		{"ImmediateOrCancel", txtypes.ImmediateOrCancel, immediateCases},
+		{"GoodTillTime", txtypes.GoodTillTime, timedCases(DefaultExpiryPeriod)},
+		{"PostOnly", txtypes.PostOnly, timedCases(DefaultExpiryPeriod)},
+	} {
+		for _, tc := range tif.cases {
+			got, err := ResolveExpiry(tif.tif, tc.requested, now)
+			checkExpiry(t, tif.name, tc, got, err)
+		}
+	}
+
+	if _, err := ResolveExpiry(3, DefaultExpiry, now); !errors.Is(err, txtypes.ErrOrderTimeInForceInvalid) {
+		t.Errorf("unknown TimeInForce: got %v", err)
+	}
+}
+
+func TestResolveOrderExpiryWithPeriod(t *testing.T) {
+	const period = 2 * time.Hour
+	for _, order := range []struct {
+		name      string
+		orderType uint8
+		tif       uint8
+		cases     []expiryCase
+	}{
+		{"market", txtypes.MarketOrder, txtypes.ImmediateOrCancel, immediateCases},
+		{"IOC limit", txtypes.LimitOrder, txtypes.ImmediateOrCancel, immediateCases},
+		{"GTT limit", txtypes.LimitOrder, txtypes.GoodTillTime, timedCases(period)},
+		{"post-only limit", txtypes.LimitOrder, txtypes.PostOnly, timedCases(period)},
+		// conditional and TWAP orders always expire, even with an immediate TimeInForce
+		{"stop loss", txtypes.StopLossOrder, txtypes.ImmediateOrCancel, timedCases(period)},
+		{"stop loss limit", txtypes.StopLossLimitOrder, txtypes.GoodTillTime, timedCases(period)},
+		{"take profit", txtypes.TakeProfitOrder, txtypes.ImmediateOrCancel, timedCases(period)},
+		{"take profit limit", txtypes.TakeProfitLimitOrder, txtypes.PostOnly, timedCases(period)},
+		{"TWAP", txtypes.TWAPOrder, txtypes.GoodTillTime, timedCases(period)},
+	} {
+		for _, tc := range order.cases {
+			got, err := ResolveOrderExpiryWithPeriod(order.orderType, order.tif, tc.requested, now, period)
+			checkExpiry(t, order.name, tc, got, err)
+		}
+	}
+
+	if _, err := ResolveOrderExpiryWithPeriod(99, txtypes.GoodTillTime, DefaultExpiry, now, period); !errors.Is(err, txtypes.ErrOrderTypeInvalid) {
+		t.Errorf("unknown order type: got %v", err)
+	}
+	if got, err := ResolveOrderExpiry(txtypes.LimitOrder, txtypes.GoodTillTime, DefaultExpiry, now); err != nil || got != now.Add(DefaultExpiryPeriod).UnixMilli() {
+		t.Errorf("ResolveOrderExpiry: got %v, %v, want the default period", got, err)
+	}
+}
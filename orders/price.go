@@ -0,0 +1,24 @@
+package orders
+
+import "github.com/elliottech/lighter-go/types/txtypes"
+
+// NoLimitPrice returns the Price of a market order accepting any fill on the given side.
+func NoLimitPrice(isAsk uint8) uint32 {
+	if isAsk == 1 {
+		return txtypes.MarketSellNoLimitPrice
+	}
+	return txtypes.MarketBuyNoLimitPrice
+}
+
+// SlippagePrice returns the Price of a market order filling at most slippageBps (in basis points) away from
+// referencePrice: above it for buys, below it for sells. The result is clamped to the valid price range.
+func SlippagePrice(isAsk uint8, referencePrice uint32, slippageBps uint32) uint32 {
+	delta := uint64(referencePrice) * uint64(slippageBps) / 10_000
+	if isAsk == 1 {
+		if delta >= uint64(referencePrice) {
+			return txtypes.MinOrderPrice
+		}
+		return max(referencePrice-uint32(delta), txtypes.MinOrderPrice)
+	}
+	return uint32(min(uint64(referencePrice)+delta, uint64(txtypes.MaxOrderPrice)))
+}
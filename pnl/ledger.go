@@ -0,0 +1,198 @@
+package pnl
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Fill is a single execution against the account.
+// Prices and amounts are integers: Size is in the market's base units (SizeDecimals),
+// Price is in USDC units (6 decimals) per one whole base unit and Fee is in USDC units.
+// Lighter charges every fee in USDC, so a negative Fee is a maker rebate.
+type Fill struct {
+	MarketIndex uint8
+	IsBuy       bool
+	Size        int64
+	Price       int64
+	Fee         int64
+	Timestamp   int64
+}
+
+type FillResult struct {
+	RealizedPnL int64 // includes the fee of the fill
+	Fee         int64
+}
+
+// Position is the open position on a market, tracked with a weighted-average cost basis.
+// Size is signed: positive for long, negative for short.
+// EntryQuote is the USDC cost of the open size, always non-negative.
+type Position struct {
+	MarketIndex  uint8
+	SizeDecimals uint8
+
+	Size        int64
+	EntryQuote  int64
+	RealizedPnL int64
+	Fees        int64
+}
+
+func (p *Position) quote(size, price int64) int64 {
+	return mulDiv(size, price, pow10(p.SizeDecimals))
+}
+
+// AvgEntryPrice returns the average entry price in USDC units per whole base unit, or 0 if the position is flat.
+func (p *Position) AvgEntryPrice() int64 {
+	if p.Size == 0 {
+		return 0
+	}
+	return mulDiv(p.EntryQuote, pow10(p.SizeDecimals), abs(p.Size))
+}
+
+// UnrealizedPnL values the open size at markPrice (USDC units per whole base unit).
+func (p *Position) UnrealizedPnL(markPrice int64) int64 {
+	if p.Size == 0 {
+		return 0
+	}
+	markQuote := p.quote(abs(p.Size), markPrice)
+	if p.Size > 0 {
+		return markQuote - p.EntryQuote
+	}
+	return p.EntryQuote - markQuote
+}
+
+// Apply updates the position with a fill and returns the PnL it realized.
+// A fill larger than the open opposite position closes it and opens the remainder on the other side,
+// with the fill's quote value split pro rata between the two legs.
+// Integer divisions round toward zero.
+func (p *Position) Apply(f *Fill) (FillResult, error) {
+	if f.Size <= 0 {
+		return FillResult{}, fmt.Errorf("fill size should be positive. got %v", f.Size)
+	}
+	if f.Price <= 0 {
+		return FillResult{}, fmt.Errorf("fill price should be positive. got %v", f.Price)
+	}
+
+	delta := f.Size
+	if !f.IsBuy {
+		delta = -f.Size
+	}
+	fillQuote := p.quote(f.Size, f.Price)
+
+	realized := int64(0)
+	if p.Size == 0 || (p.Size > 0) == (delta > 0) {
+		// opening or increasing
+		p.Size += delta
+		p.EntryQuote += fillQuote
+	} else {
+		closeSize := min(f.Size, abs(p.Size))
+		closedCost := mulDiv(p.EntryQuote, closeSize, abs(p.Size))
+		closeQuote := mulDiv(fillQuote, closeSize, f.Size)
+
+		if p.Size > 0 {
+			realized = closeQuote - closedCost
+			p.Size -= closeSize
+		} else {
+			realized = closedCost - closeQuote
+			p.Size += closeSize
+		}
+		p.EntryQuote -= closedCost
+
+		// flip: the remainder opens a position on the other side
+		if remaining := f.Size - closeSize; remaining > 0 {
+			p.Size = remaining
+			if !f.IsBuy {
+				p.Size = -remaining
+			}
+			p.EntryQuote = fillQuote - closeQuote
+		}
+	}
+
+	realized -= f.Fee
+	p.RealizedPnL += realized
+	p.Fees += f.Fee
+
+	return FillResult{RealizedPnL: realized, Fee: f.Fee}, nil
+}
+
+// Ledger keeps one Position per market.
+type Ledger struct {
+	sizeDecimals map[uint8]uint8
+	positions    map[uint8]*Position
+}
+
+// NewLedger needs the size decimals of every market it will see fills for.
+func NewLedger(sizeDecimals map[uint8]uint8) *Ledger {
+	return &Ledger{
+		sizeDecimals: sizeDecimals,
+		positions:    make(map[uint8]*Position),
+	}
+}
+
+func (l *Ledger) Apply(f *Fill) (FillResult, error) {
+	pos, ok := l.positions[f.MarketIndex]
+	if !ok {
+		decimals, ok := l.sizeDecimals[f.MarketIndex]
+		if !ok {
+			return FillResult{}, fmt.Errorf("unknown size decimals for market %v", f.MarketIndex)
+		}
+		pos = &Position{MarketIndex: f.MarketIndex, SizeDecimals: decimals}
+		l.positions[f.MarketIndex] = pos
+	}
+	return pos.Apply(f)
+}
+
+// Position returns the position for the market, or nil if no fill was applied for it.
+func (l *Ledger) Position(marketIndex uint8) *Position {
+	return l.positions[marketIndex]
+}
+
+func (l *Ledger) Positions() map[uint8]*Position {
+	return l.positions
+}
+
+// RealizedPnL is the realized PnL summed over all markets.
+func (l *Ledger) RealizedPnL() int64 {
+	total := int64(0)
+	for _, pos := range l.positions {
+		total += pos.RealizedPnL
+	}
+	return total
+}
+
+// UnrealizedPnL is the unrealized PnL summed over all markets with an open position.
+// Every open market needs a mark price.
+func (l *Ledger) UnrealizedPnL(markPrices map[uint8]int64) (int64, error) {
+	total := int64(0)
+	for marketIndex, pos := range l.positions {
+		if pos.Size == 0 {
+			continue
+		}
+		mark, ok := markPrices[marketIndex]
+		if !ok {
+			return 0, fmt.Errorf("missing mark price for market %v", marketIndex)
+		}
+		total += pos.UnrealizedPnL(mark)
+	}
+	return total, nil
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func pow10(decimals uint8) int64 {
+	res := int64(1)
+	for i := uint8(0); i < decimals; i++ {
+		res *= 10
+	}
+	return res
+}
+
+// mulDiv computes a*b/c without overflowing the intermediate product.
+func mulDiv(a, b, c int64) int64 {
+	res := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	return res.Quo(res, big.NewInt(c)).Int64()
+}
@@ -0,0 +1,113 @@
+package pnl
+
+import "testing"
+
+const usdc = 1_000_000
+
+type wantPosition struct {
+	size, entryQuote, avgEntryPrice, realized, fees int64
+}
+
+func checkPosition(t *testing.T, name string, pos *Position, want wantPosition) {
+	t.Helper()
+	got := wantPosition{pos.Size, pos.EntryQuote, pos.AvgEntryPrice(), pos.RealizedPnL, pos.Fees}
+	if got != want {
+		t.Fatalf("%v: got %+v, want %+v", name, got, want)
+	}
+}
+
+// applyAll applies the fills to a position with 2 size decimals and checks the PnL each one realized.
+func applyAll(t *testing.T, fills []*Fill, realized []int64) *Position {
+	t.Helper()
+	pos := &Position{MarketIndex: 1, SizeDecimals: 2}
+	for i, fill := range fills {
+		res, err := pos.Apply(fill)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.RealizedPnL != realized[i] || res.Fee != fill.Fee {
+			t.Fatalf("fill %v: got %+v, want realized %v", i, res, realized[i])
+		}
+	}
+	return pos
+}
+
+func TestPartialClose(t *testing.T) {
+	pos := applyAll(t, []*Fill{
+		{IsBuy: true, Size: 200, Price: 100 * usdc, Fee: 20_000}, // 2 @ 100: cost 200, fee 0.02
+		{IsBuy: false, Size: 50, Price: 110 * usdc, Fee: 5_500},  // 0.5 @ 110: +5 on a cost of 50, fee 0.0055
+	}, []int64{-20_000, 4_994_500})
+
+	checkPosition(t, "after the partial close", pos, wantPosition{
+		size:          150,
+		entryQuote:    150 * usdc,
+		avgEntryPrice: 100 * usdc,
+		realized:      4_974_500,
+		fees:          25_500,
+	})
+	// 1.5 marked at 120 is worth 180
+	if got := pos.UnrealizedPnL(120 * usdc); got != 30*usdc {
+		t.Fatalf("unrealized %v, want %v", got, 30*usdc)
+	}
+}
+
+func TestFlipWithinOneFill(t *testing.T) {
+	pos := applyAll(t, []*Fill{
+		{IsBuy: true, Size: 100, Price: 50 * usdc},
+		// 3 @ 60 for 180: 1 closes the long for 60 (+10), 2 open a short for 120; fee 0.018
+		{IsBuy: false, Size: 300, Price: 60 * usdc, Fee: 18_000},
+	}, []int64{0, 9_982_000})
+
+	checkPosition(t, "after the flip", pos, wantPosition{
+		size:          -200,
+		entryQuote:    120 * usdc,
+		avgEntryPrice: 60 * usdc,
+		realized:      9_982_000,
+		fees:          18_000,
+	})
+	// the short of 2 marked at 55 costs 110 to close
+	if got := pos.UnrealizedPnL(55 * usdc); got != 10*usdc {
+		t.Fatalf("unrealized %v, want %v", got, 10*usdc)
+	}
+}
+
+func TestFeesAndRebates(t *testing.T) {
+	pos := applyAll(t, []*Fill{
+		{IsBuy: false, Size: 100, Price: 10 * usdc, Fee: -1_000}, // maker rebate of 0.001
+		{IsBuy: true, Size: 100, Price: 9 * usdc, Fee: 2_700},    // closes for +1, taker fee 0.0027
+	}, []int64{1_000, 997_300})
+
+	checkPosition(t, "after closing", pos, wantPosition{
+		size:          0,
+		entryQuote:    0,
+		avgEntryPrice: 0,
+		realized:      998_300,
+		fees:          1_700,
+	})
+	if got := pos.UnrealizedPnL(0); got != 0 {
+		t.Fatalf("flat position has unrealized %v", got)
+	}
+}
+
+func TestLedgerUnrealizedNeedsMarkPrices(t *testing.T) {
+	ledger := NewLedger(map[uint8]uint8{1: 2, 2: 2})
+	for _, fill := range []*Fill{
+		{MarketIndex: 1, IsBuy: true, Size: 100, Price: 10 * usdc},
+		{MarketIndex: 2, IsBuy: true, Size: 100, Price: 10 * usdc},
+		{MarketIndex: 2, IsBuy: false, Size: 100, Price: 12 * usdc},
+	} {
+		if _, err := ledger.Apply(fill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// market 2 is flat and needs no mark price
+	if got, err := ledger.UnrealizedPnL(map[uint8]int64{1: 11 * usdc}); err != nil || got != usdc {
+		t.Fatalf("got %v, %v, want %v", got, err, usdc)
+	}
+	if _, err := ledger.UnrealizedPnL(map[uint8]int64{2: 11 * usdc}); err == nil {
+		t.Fatal("missing mark price of an open market accepted")
+	}
+	if got := ledger.RealizedPnL(); got != 2*usdc {
+		t.Fatalf("realized %v, want %v", got, 2*usdc)
+	}
+}
@@ -0,0 +1,155 @@
+package pnl
+
+import (
+	"fmt"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	usdcDecimals   = 6
+	tradesPageSize = 100
+)
+
+type MarketConfig struct {
+	SizeDecimals uint8
+	MarkPrice    int64 // USDC units per whole base unit
+}
+
+type MarketSummary struct {
+	MarketIndex   uint8
+	Size          int64
+	AvgEntryPrice int64
+	RealizedPnL   int64
+	UnrealizedPnL int64
+	Fees          int64
+}
+
+type AccountSummary struct {
+	Markets       map[uint8]*MarketSummary
+	RealizedPnL   int64
+	UnrealizedPnL int64
+}
+
+// FillsFromTrade converts a trade returned by the API into the fills of accountIndex: one, or two for a self-trade,
+// where the account is on both sides. The maker leg comes first.
+func FillsFromTrade(trade *client.Trade, accountIndex int64, sizeDecimals uint8) ([]*Fill, error) {
+	if accountIndex != trade.BidAccountId && accountIndex != trade.AskAccountId {
+		return nil, fmt.Errorf("account %v is not part of trade %v", accountIndex, trade.TradeId)
+	}
+
+	size, err := trade.Size.Units(sizeDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size for trade %v. err: %w", trade.TradeId, err)
+	}
+	price, err := trade.Price.Units(usdcDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price for trade %v. err: %w", trade.TradeId, err)
+	}
+	quote := mulDiv(size, price, pow10(sizeDecimals))
+
+	legs := []struct {
+		isBuy   bool
+		feeRate int64
+	}{
+		{isBuy: !trade.IsMakerAsk, feeRate: trade.MakerFee},
+		{isBuy: trade.IsMakerAsk, feeRate: trade.TakerFee},
+	}
+	fills := make([]*Fill, 0, len(legs))
+	for _, leg := range legs {
+		legAccount := trade.AskAccountId
+		if leg.isBuy {
+			legAccount = trade.BidAccountId
+		}
+		if legAccount != accountIndex {
+			continue
+		}
+		fills = append(fills, &Fill{
+			MarketIndex: trade.MarketId,
+			IsBuy:       leg.isBuy,
+			Size:        size,
+			Price:       price,
+			Fee:         mulDiv(quote, leg.feeRate, txtypes.FeeTick),
+			Timestamp:   trade.Timestamp,
+		})
+	}
+	return fills, nil
+}
+
+// FetchFills pages through all trades of the account and converts them to fills, oldest first.
+// Trades on markets missing from sizeDecimals are skipped.
+func FetchFills(c *client.HTTPClient, accountIndex int64, auth string, sizeDecimals map[uint8]uint8) ([]*Fill, error) {
+	fills := make([]*Fill, 0)
+	cursor := ""
+	for {
+		page, err := c.GetAccountTrades(accountIndex, cursor, tradesPageSize, auth)
+		if err != nil {
+			return nil, err
+		}
+		for _, trade := range page.Trades {
+			decimals, ok := sizeDecimals[trade.MarketId]
+			if !ok {
+				continue
+			}
+			tradeFills, err := FillsFromTrade(trade, accountIndex, decimals)
+			if err != nil {
+				return nil, err
+			}
+			fills = append(fills, tradeFills...)
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return fills, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Summarize replays all fills of the account and returns the per-market and account-level PnL.
+// Every market with an open position needs a MarkPrice.
+func Summarize(c *client.HTTPClient, accountIndex int64, auth string, markets map[uint8]MarketConfig) (*AccountSummary, error) {
+	sizeDecimals := make(map[uint8]uint8, len(markets))
+	for marketIndex, market := range markets {
+		sizeDecimals[marketIndex] = market.SizeDecimals
+	}
+
+	fills, err := FetchFills(c, accountIndex, auth, sizeDecimals)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := NewLedger(sizeDecimals)
+	for _, fill := range fills {
+		if _, err := ledger.Apply(fill); err != nil {
+			return nil, err
+		}
+	}
+
+	markPrices := make(map[uint8]int64, len(markets))
+	for marketIndex, market := range markets {
+		if market.MarkPrice > 0 {
+			markPrices[marketIndex] = market.MarkPrice
+		}
+	}
+	unrealized, err := ledger.UnrealizedPnL(markPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AccountSummary{
+		Markets:       make(map[uint8]*MarketSummary),
+		RealizedPnL:   ledger.RealizedPnL(),
+		UnrealizedPnL: unrealized,
+	}
+	for marketIndex, pos := range ledger.Positions() {
+		summary.Markets[marketIndex] = &MarketSummary{
+			MarketIndex:   marketIndex,
+			Size:          pos.Size,
+			AvgEntryPrice: pos.AvgEntryPrice(),
+			RealizedPnL:   pos.RealizedPnL,
+			UnrealizedPnL: pos.UnrealizedPnL(markPrices[marketIndex]),
+			Fees:          pos.Fees,
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,103 @@
+package pnl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+func TestFillsFromTrade(t *testing.T) {
+	trade := &client.Trade{
+		TradeId:      1,
+		MarketId:     3,
+		Size:         "2.5",
+		Price:        "3000.5",
+		AskAccountId: 8,
+		BidAccountId: 7,
+		IsMakerAsk:   true,
+		TakerFee:     200, // 0.02%
+		MakerFee:     -20, // 0.002% rebate
+		Timestamp:    1000,
+	}
+	// 2.5 @ 3000.5 is 7501.25 USDC
+	buy := &Fill{MarketIndex: 3, IsBuy: true, Size: 250, Price: 3_000_500_000, Fee: 1_500_250, Timestamp: 1000}
+	sell := &Fill{MarketIndex: 3, IsBuy: false, Size: 250, Price: 3_000_500_000, Fee: -150_025, Timestamp: 1000}
+
+	for _, tc := range []struct {
+		name         string
+		bid, ask     int64
+		accountIndex int64
+		want         []*Fill
+	}{
+		{"taker bid", 7, 8, 7, []*Fill{buy}},
+		{"maker ask", 7, 8, 8, []*Fill{sell}},
+		{"self-trade", 7, 7, 7, []*Fill{sell, buy}}, // maker leg first
+	} {
+		trade.BidAccountId, trade.AskAccountId = tc.bid, tc.ask
+		fills, err := FillsFromTrade(trade, tc.accountIndex, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fills, tc.want) {
+			t.Fatalf("%v: got %+v, want %+v", tc.name, fills, tc.want)
+		}
+	}
+
+	trade.BidAccountId, trade.AskAccountId = 7, 8
+	if _, err := FillsFromTrade(trade, 9, 2); err == nil {
+		t.Fatal("trade of other accounts converted")
+	}
+}
+
+// tradesServer serves the trades of account 7 on market 1 in a single page: a taker buy of 2 @ 100,
+// then a self-trade of 1 @ 110 where the bid is the maker.
+func tradesServer(t *testing.T) *client.HTTPClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":200,"trades":[
+			{"trade_id":1,"market_id":1,"size":"2.00","price":"100","bid_account_id":7,"ask_account_id":8,"is_maker_ask":true,"taker_fee":100,"maker_fee":0,"timestamp":1},
+			{"trade_id":2,"market_id":1,"size":"1.00","price":"110","bid_account_id":7,"ask_account_id":7,"is_maker_ask":false,"taker_fee":100,"maker_fee":0,"timestamp":2}
+		]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return client.NewHTTPClient(srv.URL)
+}
+
+func TestSummarize(t *testing.T) {
+	c := tradesServer(t)
+	summary, err := Summarize(c, 7, "auth", map[uint8]MarketConfig{
+		1: {SizeDecimals: 2, MarkPrice: 120 * usdc},
+		2: {SizeDecimals: 2}, // no position: no mark price needed
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// buy 2 @ 100 for 200, fee 0.02
+	// self-trade: buy 1 @ 110 (maker, no fee): long 3 for 310
+	//             sell 1 @ 110 (taker, fee 0.011): closes a third of the cost, 103.333333, for 110
+	want := &MarketSummary{
+		MarketIndex:   1,
+		Size:          200,
+		AvgEntryPrice: 103_333_333,
+		RealizedPnL:   -20_000 + 6_666_667 - 11_000,
+		UnrealizedPnL: 240*usdc - 206_666_667, // 2 marked at 120
+		Fees:          31_000,
+	}
+	if got := summary.Markets[1]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if len(summary.Markets) != 1 || summary.RealizedPnL != want.RealizedPnL || summary.UnrealizedPnL != want.UnrealizedPnL {
+		t.Fatalf("account summary %+v", summary)
+	}
+}
+
+func TestSummarizeNeedsMarkPrices(t *testing.T) {
+	c := tradesServer(t)
+	if _, err := Summarize(c, 7, "auth", map[uint8]MarketConfig{1: {SizeDecimals: 2}}); err == nil {
+		t.Fatal("open position without a mark price valued")
+	}
+}
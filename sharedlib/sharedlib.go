@@ -1,15 +1,32 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/orders"
+	"github.com/elliottech/lighter-go/signer"
 	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
 	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
-	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
@@ -28,24 +45,518 @@ typedef struct {
 */
 import "C"
 
+// debugMode makes the exports log their calls and the requests of their clients, and add stack traces to
+// recovered panics. It's toggled by SetDebug.
+var debugMode atomic.Bool
+
+var debugLog = log.New(os.Stderr, "lighter-signer: ", log.LstdFlags|log.Lmicroseconds)
+
+// redacted replaces the arguments which may hold secrets (keys, signatures, signed txs, auth tokens) in the logged calls.
+const redacted = "<redacted>"
+
+// panicErr turns a recovered panic into the error of an export, with the stack trace in debug mode.
+func panicErr(r any) error {
+	if debugMode.Load() {
+		return fmt.Errorf("%v\n%s", r, debug.Stack())
+	}
+	return fmt.Errorf("%v", r)
+}
+
+// traceCall logs an export call with its arguments and duration in debug mode. Use as defer traceCall(name, args...)().
+func traceCall(name string, args ...any) func() {
+	if !debugMode.Load() {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		debugLog.Printf("%v%v took %v", name, formatArgs(args), time.Since(start))
+	}
+}
+
+func formatArgs(args []any) string {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		if str, ok := arg.(string); ok && str != redacted {
+			formatted[i] = strconv.Quote(str)
+		} else {
+			formatted[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+// logRequest logs the requests of the registered clients in debug mode.
+func logRequest(r client.RequestLog) {
+	if !debugMode.Load() {
+		return
+	}
+	if r.Err != nil {
+		debugLog.Printf("%v %v failed after %v: %v", r.Method, r.URL, r.Duration, r.Err)
+		return
+	}
+	debugLog.Printf("%v %v %v in %v", r.Method, r.URL, r.Status, r.Duration)
+}
+
+// version is the SDK version reported by GetSignerInfo, set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// stateMu guards the client registry below. Exports resolve their client once, at entry, and use it for the rest of
+// the call, so a concurrent SwitchAPIKey or CreateClient can't change the key a call signs with midway.
+var stateMu sync.RWMutex
+
 var (
 	txClient        *client.TxClient
-	backupTxClients map[uint8]*client.TxClient
+	backupTxClients map[clientKey]*client.TxClient
+
+	// generatedKeys holds the keys created by GenerateAPIKey, by their hex-encoded public key, until they're
+	// released with ForgetGeneratedKey or a client using them is destroyed. DestroyAllClients clears it.
+	generatedKeys = make(map[string]signer.KeyManager)
+
+	// pendingRotations holds the key rotations started by RotateApiKey, by client, until ConfirmRotateApiKey
+	// submits them or the client is destroyed.
+	pendingRotations = make(map[clientKey]*client.KeyRotation)
+
+	// readOnlyClient serves the query exports when there is no active client. It's set by CreateReadOnlyClient,
+	// together with the chain id it was created for.
+	readOnlyClient  *client.HTTPClient
+	readOnlyChainId uint32
 )
 
+var errReadOnly = errors.New("read-only client cannot sign, call CreateClient() with a private key first")
+
+// errNoClient is the error of the exports needing a client with a key when none was created.
+func errNoClient() error {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if readOnlyClient != nil {
+		return errReadOnly
+	}
+	return fmt.Errorf("client is not created, call CreateClient() first")
+}
+
+var errNoEndpoint = errors.New("endpoint not configured, call CreateClient() or CreateReadOnlyClient() first")
+
+// queryClient returns the HTTP client of the query exports: the active client's, or else the read-only client.
+func queryClient() (*client.HTTPClient, error) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if txClient != nil && txClient.HTTP() != nil {
+		return txClient.HTTP(), nil
+	}
+	if readOnlyClient != nil {
+		return readOnlyClient, nil
+	}
+	return nil, errNoEndpoint
+}
+
+// queryAccountIndex resolves the account of a query export, -1 being the active client's.
+func queryAccountIndex(accountIndex int64) (int64, error) {
+	if accountIndex != -1 {
+		return accountIndex, nil
+	}
+	c := activeClient()
+	if c == nil {
+		return -1, fmt.Errorf("no active client, pass an account index")
+	}
+	return c.GetAccountIndex(), nil
+}
+
+// queryAuthToken returns a cached auth token of the account, made by any of its registered clients.
+func queryAuthToken(accountIndex int64) (string, error) {
+	clients, active := snapshotClients()
+	if active != nil && active.GetAccountIndex() == accountIndex {
+		return active.GetValidAuthToken(time.Minute)
+	}
+	for _, c := range clients {
+		if c.GetAccountIndex() == accountIndex {
+			return c.GetValidAuthToken(time.Minute)
+		}
+	}
+	return "", fmt.Errorf("an auth token is required, create a client for account %v first", accountIndex)
+}
+
+type clientKey struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+}
+
+// activeClient returns the active client, nil if there is none.
+func activeClient() *client.TxClient {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return txClient
+}
+
+// activeAccountIndex returns the account of the active client, -1 if there is none.
+func activeAccountIndex() int64 {
+	c := activeClient()
+	if c == nil {
+		return -1
+	}
+	return c.GetAccountIndex()
+}
+
+// snapshotClients returns the registered clients and the active one, as of the call.
+func snapshotClients() ([]*client.TxClient, *client.TxClient) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	clients := make([]*client.TxClient, 0, len(backupTxClients))
+	for _, c := range backupTxClients {
+		clients = append(clients, c)
+	}
+	return clients, txClient
+}
+
+// readOnly returns the read-only client and its chain id, nil if there is none.
+func readOnly() (*client.HTTPClient, uint32) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return readOnlyClient, readOnlyChainId
+}
+
+// resolveClient returns the registered client of (accountIndex, apiKeyIndex). -1 for either uses the active client's.
+func resolveClient(apiKeyIndex int, accountIndex int64) *client.TxClient {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if txClient == nil && (apiKeyIndex == -1 || accountIndex == -1) {
+		return nil
+	}
+	if apiKeyIndex == -1 {
+		apiKeyIndex = int(txClient.GetApiKeyIndex())
+	}
+	if accountIndex == -1 {
+		accountIndex = txClient.GetAccountIndex()
+	}
+	return backupTxClients[clientKey{accountIndex, uint8(apiKeyIndex)}]
+}
+
+// parseTx decodes a tx JSON as returned by the Sign* exports, which may carry MessageToSign and TypedDataToSign next
+// to the tx fields.
+func parseTx(txType uint8, txInfoStr string) (txtypes.TxInfo, error) {
+	obj := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(txInfoStr), &obj); err != nil {
+		return nil, fmt.Errorf("tx info should be a JSON object. err: %v", err)
+	}
+	delete(obj, "MessageToSign")
+	delete(obj, "TypedDataToSign")
+	txInfoBytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return txtypes.ParseTxInfo(txType, txInfoBytes)
+}
+
+// orderInput is an order of the JSON arrays taken by SignCreateOrders and SignCreateGroupedOrders. Required fields
+// are pointers so a missing one can be told apart from a zero value.
+type orderInput struct {
+	MarketIndex      *uint8  `json:"marketIndex"`
+	ClientOrderIndex int64   `json:"clientOrderIndex"`
+	BaseAmount       int64   `json:"baseAmount"`
+	Price            *uint32 `json:"price"`
+	IsAsk            *uint8  `json:"isAsk"`
+	Type             *uint8  `json:"type"`
+	TimeInForce      *uint8  `json:"timeInForce"`
+	ReduceOnly       uint8   `json:"reduceOnly"`
+	TriggerPrice     uint32  `json:"triggerPrice"`
+	OrderExpiry      *int64  `json:"orderExpiry"`
+}
+
+// parseOrders decodes a JSON array of orders. Errors name the offending order and field.
+func parseOrders(ordersStr string) ([]*types.CreateOrderTxReq, error) {
+	var rawOrders []json.RawMessage
+	if err := json.Unmarshal([]byte(ordersStr), &rawOrders); err != nil {
+		return nil, fmt.Errorf("orders should be a JSON array of objects. err: %v", err)
+	}
+
+	reqs := make([]*types.CreateOrderTxReq, 0, len(rawOrders))
+	for i, rawOrder := range rawOrders {
+		var order orderInput
+		decoder := json.NewDecoder(strings.NewReader(string(rawOrder)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&order); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return nil, fmt.Errorf("order %v: field %v should be a %v", i, typeErr.Field, typeErr.Type)
+			}
+			return nil, fmt.Errorf("order %v: %v", i, err)
+		}
+
+		missing := ""
+		switch {
+		case order.MarketIndex == nil:
+			missing = "marketIndex"
+		case order.Price == nil:
+			missing = "price"
+		case order.IsAsk == nil:
+			missing = "isAsk"
+		case order.Type == nil:
+			missing = "type"
+		case order.TimeInForce == nil:
+			missing = "timeInForce"
+		}
+		if missing != "" {
+			return nil, fmt.Errorf("order %v: missing field %v", i, missing)
+		}
+
+		orderExpiry := orders.DefaultExpiry
+		if order.OrderExpiry != nil {
+			orderExpiry = *order.OrderExpiry
+		}
+		reqs = append(reqs, &types.CreateOrderTxReq{
+			MarketIndex:      *order.MarketIndex,
+			ClientOrderIndex: order.ClientOrderIndex,
+			BaseAmount:       order.BaseAmount,
+			Price:            *order.Price,
+			IsAsk:            *order.IsAsk,
+			Type:             *order.Type,
+			TimeInForce:      *order.TimeInForce,
+			ReduceOnly:       order.ReduceOnly,
+			TriggerPrice:     order.TriggerPrice,
+			OrderExpiry:      orderExpiry,
+		})
+	}
+	return reqs, nil
+}
+
+// parseGroupedOrders decodes the orders of a grouped order tx, checking their count against the grouping type.
+func parseGroupedOrders(groupingType uint8, ordersStr string) ([]*types.CreateOrderTxReq, error) {
+	var count int
+	switch groupingType {
+	case txtypes.GroupingType_OneTriggersTheOther, txtypes.GroupingType_OneCancelsTheOther:
+		count = 2
+	case txtypes.GroupingType_OneTriggersAOneCancelsTheOther:
+		count = 3
+	default:
+		return nil, fmt.Errorf("invalid grouping type %v", groupingType)
+	}
+
+	reqs, err := parseOrders(ordersStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) != count {
+		return nil, fmt.Errorf("grouping type %v takes %v orders, got %v", groupingType, count, len(reqs))
+	}
+	for i, req := range reqs {
+		// Lighter doesn't take client order indexes on any leg of a grouped order
+		if req.ClientOrderIndex != txtypes.NilClientOrderIndex {
+			return nil, fmt.Errorf("order %v: clientOrderIndex should be nil (%v) for grouped orders. err: %w", i, txtypes.NilClientOrderIndex, txtypes.ErrClientOrderIndexNotNil)
+		}
+	}
+	return reqs, nil
+}
+
+// activateClient registers c and makes it the active client, atomically replacing the client of the same key if any.
+func activateClient(c *client.TxClient) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	registerClient(c)
+	txClient = c
+}
+
+// registerClient adds c to the registry. stateMu must be held.
+func registerClient(c *client.TxClient) {
+	if backupTxClients == nil {
+		backupTxClients = make(map[clientKey]*client.TxClient)
+	}
+	backupTxClients[clientKey{c.GetAccountIndex(), c.GetApiKeyIndex()}] = c
+	if c.HTTP() != nil {
+		c.HTTP().OnRequest(logRequest)
+	}
+}
+
+// verifyNewClient checks that the server of a new client signs for its chain id, so a wrong chain id fails at creation
+// rather than with rejected txs. Offline clients and callers passing a non-zero skip aren't checked.
+func verifyNewClient(c *client.TxClient, skip C.int) error {
+	if skip != 0 || c.HTTP() == nil {
+		return nil
+	}
+	return c.VerifyChainId(context.Background())
+}
+
+// newHTTPClient creates the HTTP client of the exports taking a url: a comma-separated list of base URLs,
+// the primary one then the fallbacks.
+func newHTTPClient(urls string) *client.HTTPClient {
+	endpoints := splitEndpoints(urls)
+	if len(endpoints) == 0 {
+		return nil
+	}
+	return client.NewHTTPClient(endpoints[0], client.WithFallbackEndpoints(endpoints[1:]...))
+}
+
+func splitEndpoints(urls string) []string {
+	var endpoints []string
+	for _, u := range strings.Split(urls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			endpoints = append(endpoints, u)
+		}
+	}
+	return endpoints
+}
+
 func wrapErr(err error) (ret *C.char) {
 	return C.CString(fmt.Sprintf("%v", err))
 }
 
+// checkBool rejects a flag argument other than 0 or 1.
+func checkBool(arg string, value int64) error {
+	if value != 0 && value != 1 {
+		return fmt.Errorf("%v should be 0 or 1, got %v", arg, value)
+	}
+	return nil
+}
+
+// sendOptsOf builds the options of the Send* exports from their price protection argument: -1 for the client's
+// fat finger protection setting, 0 to disable it for this tx, 1 to enable it.
+func sendOptsOf(priceProtection int64) (client.SendOpts, error) {
+	if priceProtection == -1 {
+		return client.SendOpts{}, nil
+	}
+	if err := checkBool("priceProtection", priceProtection); err != nil {
+		return client.SendOpts{}, fmt.Errorf("%v, or -1 for the client's setting", err)
+	}
+	enabled := priceProtection == 1
+	return client.SendOpts{PriceProtection: &enabled}, nil
+}
+
+// checkEnum rejects an argument outside of the values of its enum, e.g. txtypes.Constants().OrderTypes,
+// and lists them in the error.
+func checkEnum(arg string, value int64, enum map[string]int64) error {
+	for _, v := range enum {
+		if v == value {
+			return nil
+		}
+	}
+	allowed := make([]string, 0, len(enum))
+	for name, v := range enum {
+		allowed = append(allowed, fmt.Sprintf("%v (%v)", name, v))
+	}
+	sort.Strings(allowed)
+	return fmt.Errorf("%v should be one of %v, got %v", arg, strings.Join(allowed, ", "), value)
+}
+
+// checkOrderArgs validates the flag and enum arguments of the create order exports before they are truncated
+// to uint8. A timeInForce of -1 picks the default.
+func checkOrderArgs(isAsk, orderType, timeInForce, reduceOnly int64) error {
+	constants := txtypes.Constants()
+	if err := checkBool("isAsk", isAsk); err != nil {
+		return err
+	}
+	if err := checkEnum("orderType", orderType, constants.OrderTypes); err != nil {
+		return err
+	}
+	if timeInForce != -1 {
+		if err := checkEnum("timeInForce", timeInForce, constants.TimeInForces); err != nil {
+			return err
+		}
+	}
+	return checkBool("reduceOnly", reduceOnly)
+}
+
 //export GenerateAPIKey
 func GenerateAPIKey(cSeed *C.char) (ret C.ApiKeyResponse) {
+	defer traceCall("GenerateAPIKey", redacted)()
+	var err error
+	var privateKeyStr string
+	var publicKeyStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.ApiKeyResponse{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.ApiKeyResponse{
+				privateKey: C.CString(privateKeyStr),
+				publicKey:  C.CString(publicKeyStr),
+			}
+		}
+	}()
+
+	privateKeyStr, publicKeyStr, err = generateAPIKey(C.GoString(cSeed))
+	return
+}
+
+// generateAPIKey is GenerateAPIKey: it creates a key from seed, random when empty, and remembers it.
+func generateAPIKey(seed string) (privateKeyStr, publicKeyStr string, err error) {
+	seedP := &seed
+	if seed == "" {
+		seedP = nil
+	}
+
+	key := curve.SampleScalar(seedP)
+
+	keyManager, err := signer.NewKeyManager(key.ToLittleEndianBytes())
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKeyStr = keys.FromKeyManager(keyManager).Hex()
+	privateKeyStr = hexutil.Encode(key.ToLittleEndianBytes())
+	rememberGeneratedKey(publicKeyStr, keyManager)
+	return privateKeyStr, publicKeyStr, nil
+}
+
+// rememberGeneratedKey keeps a generated key for CreateClientFromGeneratedKey.
+func rememberGeneratedKey(publicKey string, keyManager signer.KeyManager) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	generatedKeys[publicKey] = keyManager
+}
+
+//export GenerateAPIKeyFromEthSignature
+func GenerateAPIKeyFromEthSignature(cSignature *C.char) (ret C.ApiKeyResponse) {
+	defer traceCall("GenerateAPIKeyFromEthSignature", redacted)()
+	var err error
+	var privateKeyStr string
+	var publicKeyStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.ApiKeyResponse{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.ApiKeyResponse{
+				privateKey: C.CString(privateKeyStr),
+				publicKey:  C.CString(publicKeyStr),
+			}
+		}
+	}()
+
+	sig, err := keys.ParseEthSignature(C.GoString(cSignature))
+	if err != nil {
+		return
+	}
+
+	keyManager, err := keys.FromEthSignature(sig)
+	if err != nil {
+		return
+	}
+
+	publicKeyStr = keys.FromKeyManager(keyManager).Hex()
+	privateKeyStr = hexutil.Encode(keyManager.PrvKeyBytes())
+	rememberGeneratedKey(publicKeyStr, keyManager)
+
+	return
+}
+
+//export GenerateAPIKeyFromMnemonic
+func GenerateAPIKeyFromMnemonic(cMnemonic *C.char, cIndex C.int) (ret C.ApiKeyResponse) {
+	defer traceCall("GenerateAPIKeyFromMnemonic", redacted, cIndex)()
 	var err error
 	var privateKeyStr string
 	var publicKeyStr string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.ApiKeyResponse{
@@ -59,195 +570,3138 @@ func GenerateAPIKey(cSeed *C.char) (ret C.ApiKeyResponse) {
 		}
 	}()
 
-	seed := C.GoString(cSeed)
-	seedP := &seed
-	if seed == "" {
-		seedP = nil
+	index := int(cIndex)
+	if index < 0 || index > int(txtypes.MaxApiKeyIndex) {
+		err = fmt.Errorf("invalid index %d, expected 0 to %d", index, txtypes.MaxApiKeyIndex)
+		return
+	}
+
+	keyManager, err := keys.FromMnemonic(C.GoString(cMnemonic), uint32(index))
+	if err != nil {
+		return
+	}
+
+	publicKeyStr = keys.FromKeyManager(keyManager).Hex()
+	privateKeyStr = hexutil.Encode(keyManager.PrvKeyBytes())
+	rememberGeneratedKey(publicKeyStr, keyManager)
+
+	return
+}
+
+//export DeriveApiKeys
+func DeriveApiKeys(cMasterSeed *C.char, cAccountIndex C.longlong, cFromIndex C.int, cCount C.int) (ret C.StrOrErr) {
+	defer traceCall("DeriveApiKeys", redacted, cAccountIndex, cFromIndex, cCount)()
+	var err error
+	var keysStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(keysStr),
+			}
+		}
+	}()
+
+	keysStr, err = deriveApiKeys(C.GoString(cMasterSeed), int64(cAccountIndex), int(cFromIndex), int(cCount))
+	return
+}
+
+// derivedKey is an entry of the DeriveApiKeys array. Like the other generated keys, the derived ones can be passed
+// to CreateClientFromGeneratedKey.
+type derivedKey struct {
+	ApiKeyIndex int    `json:"apiKeyIndex"`
+	PrivateKey  string `json:"privateKey"`
+	PublicKey   string `json:"publicKey"`
+}
+
+// deriveApiKeys is DeriveApiKeys: it derives the keys of count API key indexes from fromIndex, and remembers them.
+func deriveApiKeys(masterSeedHex string, accountIndex int64, fromIndex, count int) (string, error) {
+	if fromIndex < 0 || count < 1 || fromIndex+count-1 > int(txtypes.MaxApiKeyIndex) {
+		return "", fmt.Errorf("invalid api key indexes %d to %d, expected 0 to %d", fromIndex, fromIndex+count-1, txtypes.MaxApiKeyIndex)
+	}
+
+	masterSeed, err := hex.DecodeString(strings.TrimPrefix(masterSeedHex, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("master seed is not valid hex. err: %v", err)
+	}
+	defer clear(masterSeed)
+
+	derived := make([]derivedKey, 0, count)
+	for apiKeyIndex := fromIndex; apiKeyIndex < fromIndex+count; apiKeyIndex++ {
+		keyManager, err := keys.DeriveApiKey(masterSeed, accountIndex, uint8(apiKeyIndex))
+		if err != nil {
+			return "", err
+		}
+		publicKeyStr := keys.FromKeyManager(keyManager).Hex()
+		rememberGeneratedKey(publicKeyStr, keyManager)
+		derived = append(derived, derivedKey{
+			ApiKeyIndex: apiKeyIndex,
+			PrivateKey:  hexutil.Encode(keyManager.PrvKeyBytes()),
+			PublicKey:   publicKeyStr,
+		})
+	}
+
+	keysBytes, err := json.Marshal(derived)
+	if err != nil {
+		return "", err
+	}
+	return string(keysBytes), nil
+}
+
+//export ValidatePrivateKey
+func ValidatePrivateKey(cPrivateKey *C.char) (ret C.StrOrErr) {
+	defer traceCall("ValidatePrivateKey", redacted)()
+	var err error
+	var publicKeyStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(publicKeyStr),
+			}
+		}
+	}()
+
+	keyManager, err := keys.ParsePrivateKey(C.GoString(cPrivateKey))
+	if err != nil {
+		return
+	}
+
+	publicKeyStr = keys.FromKeyManager(keyManager).Hex()
+	return
+}
+
+//export CreateClientFromGeneratedKey
+func CreateClientFromGeneratedKey(cPublicKey *C.char, cUrl *C.char, cChainId C.int, cApiKeyIndex C.int, cAccountIndex C.longlong, cSkipChainIdCheck C.int) (ret *C.char) {
+	defer traceCall("CreateClientFromGeneratedKey", C.GoString(cPublicKey), C.GoString(cUrl), cChainId, cApiKeyIndex, cAccountIndex, cSkipChainIdCheck)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	publicKey := C.GoString(cPublicKey)
+	if !strings.HasPrefix(publicKey, "0x") {
+		publicKey = "0x" + publicKey
+	}
+	url := C.GoString(cUrl)
+	chainId := uint32(cChainId)
+	apiKeyIndex := uint8(cApiKeyIndex)
+	accountIndex := int64(cAccountIndex)
+
+	if accountIndex <= 0 {
+		err = fmt.Errorf("invalid account index")
+		return
+	}
+
+	stateMu.RLock()
+	keyManager, ok := generatedKeys[strings.ToLower(publicKey)]
+	stateMu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("no generated key with public key %v, call GenerateAPIKey() first", publicKey)
+		return
+	}
+
+	httpClient := newHTTPClient(url)
+	c := client.NewTxClientWithKeyManager(httpClient, keyManager, accountIndex, apiKeyIndex, chainId)
+	if err = verifyNewClient(c, cSkipChainIdCheck); err != nil {
+		return
+	}
+	activateClient(c)
+
+	return nil
+}
+
+//export ForgetGeneratedKey
+func ForgetGeneratedKey(cPublicKey *C.char) {
+	defer traceCall("ForgetGeneratedKey", C.GoString(cPublicKey))()
+	publicKey := C.GoString(cPublicKey)
+	if !strings.HasPrefix(publicKey, "0x") {
+		publicKey = "0x" + publicKey
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	delete(generatedKeys, strings.ToLower(publicKey))
+}
+
+//export CreateClient
+func CreateClient(cUrl *C.char, cPrivateKey *C.char, cChainId C.int, cApiKeyIndex C.int, cAccountIndex C.longlong, cOffline C.int, cSkipChainIdCheck C.int) (ret *C.char) {
+	defer traceCall("CreateClient", C.GoString(cUrl), redacted, cChainId, cApiKeyIndex, cAccountIndex, cOffline, cSkipChainIdCheck)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	url := C.GoString(cUrl)
+	privateKey := C.GoString(cPrivateKey)
+	chainId := uint32(cChainId)
+	apiKeyIndex := uint8(cApiKeyIndex)
+	accountIndex := int64(cAccountIndex)
+
+	if accountIndex <= 0 {
+		err = fmt.Errorf("invalid account index")
+		return
+	}
+
+	// an offline client has no HTTPClient: it only signs, and needs an explicit nonce for every tx
+	offline := cOffline != 0
+	if offline && url != "" {
+		err = fmt.Errorf("offline client doesn't take a url")
+		return
+	}
+	if !offline && url == "" {
+		err = fmt.Errorf("empty url, create an offline client to sign without Lighter")
+		return
+	}
+
+	var httpClient *client.HTTPClient
+	if !offline {
+		httpClient = newHTTPClient(url)
+	}
+	// on error the registered clients are left as they were
+	c, err := client.NewTxClient(httpClient, privateKey, accountIndex, apiKeyIndex, chainId)
+	if err != nil {
+		err = fmt.Errorf("error occurred when creating TxClient. err: %v", err)
+		return
+	}
+	if err = verifyNewClient(c, cSkipChainIdCheck); err != nil {
+		return
+	}
+	activateClient(c)
+
+	return nil
+}
+
+//export CreateClientFromKeyBytes
+func CreateClientFromKeyBytes(cUrl *C.char, cPrivateKey *C.uchar, cPrivateKeyLen C.int, cChainId C.int, cApiKeyIndex C.int, cAccountIndex C.longlong, cOffline C.int, cSkipChainIdCheck C.int) (ret *C.char) {
+	defer traceCall("CreateClientFromKeyBytes", C.GoString(cUrl), redacted, cPrivateKeyLen, cChainId, cApiKeyIndex, cAccountIndex, cOffline, cSkipChainIdCheck)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	url := C.GoString(cUrl)
+	chainId := uint32(cChainId)
+	apiKeyIndex := uint8(cApiKeyIndex)
+	accountIndex := int64(cAccountIndex)
+
+	if accountIndex <= 0 {
+		err = fmt.Errorf("invalid account index")
+		return
+	}
+	if cPrivateKey == nil || cPrivateKeyLen < 0 {
+		err = fmt.Errorf("invalid private key length. expected: %d got: %d", keys.PrivateKeyLength, max(int(cPrivateKeyLen), 0))
+		return
+	}
+
+	offline := cOffline != 0
+	if offline && url != "" {
+		err = fmt.Errorf("offline client doesn't take a url")
+		return
+	}
+	if !offline && url == "" {
+		err = fmt.Errorf("empty url, create an offline client to sign without Lighter")
+		return
+	}
+
+	// the key is copied into Go memory, which is erased once the KeyManager is built; the caller owns
+	// (and may wipe) its own buffer
+	privateKey := C.GoBytes(unsafe.Pointer(cPrivateKey), cPrivateKeyLen)
+	keyManager, err := keys.PrivateKeyFromBytes(privateKey)
+	clear(privateKey)
+	if err != nil {
+		return
+	}
+
+	var httpClient *client.HTTPClient
+	if !offline {
+		httpClient = newHTTPClient(url)
+	}
+	c := client.NewTxClientWithKeyManager(httpClient, keyManager, accountIndex, apiKeyIndex, chainId)
+	if err = verifyNewClient(c, cSkipChainIdCheck); err != nil {
+		return
+	}
+	activateClient(c)
+
+	return nil
+}
+
+//export CreateReadOnlyClient
+func CreateReadOnlyClient(cUrl *C.char, cChainId C.int) (ret *C.char) {
+	defer traceCall("CreateReadOnlyClient", C.GoString(cUrl), cChainId)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	url := C.GoString(cUrl)
+	if url == "" {
+		err = fmt.Errorf("empty url")
+		return
+	}
+	httpClient := newHTTPClient(url)
+	httpClient.OnRequest(logRequest)
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	readOnlyClient = httpClient
+	readOnlyChainId = uint32(cChainId)
+	return nil
+}
+
+//export GetAccount
+func GetAccount(cAccountIndex C.longlong) (ret C.StrOrErr) {
+	defer traceCall("GetAccount", cAccountIndex)()
+	var err error
+	var resultStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(resultStr),
+			}
+		}
+	}()
+
+	httpClient, err := queryClient()
+	if err != nil {
+		return
+	}
+	accountIndex, err := queryAccountIndex(int64(cAccountIndex))
+	if err != nil {
+		return
+	}
+
+	result, err := httpClient.GetAccount(accountIndex)
+	if err != nil {
+		err = fmt.Errorf("failed to get account. err: %w", err)
+		return
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	resultStr = string(resultBytes)
+	return
+}
+
+//export GetPositions
+func GetPositions(cAccountIndex C.longlong) (ret C.StrOrErr) {
+	defer traceCall("GetPositions", cAccountIndex)()
+	var err error
+	var resultStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(resultStr),
+			}
+		}
+	}()
+
+	httpClient, err := queryClient()
+	if err != nil {
+		return
+	}
+	accountIndex, err := queryAccountIndex(int64(cAccountIndex))
+	if err != nil {
+		return
+	}
+
+	account, err := httpClient.GetAccount(accountIndex)
+	if err != nil {
+		err = fmt.Errorf("failed to get account. err: %w", err)
+		return
+	}
+	result := account.Positions
+	if result == nil {
+		result = []*client.Position{}
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	resultStr = string(resultBytes)
+	return
+}
+
+//export GetActiveOrders
+func GetActiveOrders(cAccountIndex C.longlong, cMarketIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("GetActiveOrders", cAccountIndex, cMarketIndex)()
+	var err error
+	var resultStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(resultStr),
+			}
+		}
+	}()
+
+	httpClient, err := queryClient()
+	if err != nil {
+		return
+	}
+	accountIndex, err := queryAccountIndex(int64(cAccountIndex))
+	if err != nil {
+		return
+	}
+
+	auth, err := queryAuthToken(accountIndex)
+	if err != nil {
+		return
+	}
+	active, err := httpClient.GetActiveOrders(accountIndex, uint8(cMarketIndex), auth)
+	if err != nil {
+		err = fmt.Errorf("failed to get active orders. err: %w", err)
+		return
+	}
+	result := active.Orders
+	if result == nil {
+		result = []*client.Order{}
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	resultStr = string(resultBytes)
+	return
+}
+
+//export CheckClient
+func CheckClient(cApiKeyIndex C.int, cAccountIndex C.longlong) (ret *C.char) {
+	defer traceCall("CheckClient", cApiKeyIndex, cAccountIndex)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	apiKeyIndex := uint8(cApiKeyIndex)
+	accountIndex := int64(cAccountIndex)
+
+	client := resolveClient(int(apiKeyIndex), accountIndex)
+	ok := client != nil
+	if roClient, _ := readOnly(); !ok && roClient != nil {
+		err = fmt.Errorf("client is read-only, it has no api key to check")
+		return
+	}
+	if !ok {
+		err = fmt.Errorf("api key not registered")
+		return
+	}
+
+	if client.GetApiKeyIndex() != apiKeyIndex {
+		err = fmt.Errorf("apiKeyIndex does not match. expected %v but got %v", client.GetApiKeyIndex(), apiKeyIndex)
+		return
+	}
+	if client.GetAccountIndex() != accountIndex {
+		err = fmt.Errorf("accountIndex does not match. expected %v but got %v", client.GetAccountIndex(), accountIndex)
+		return
+	}
+
+	if client.HTTP() == nil {
+		err = fmt.Errorf("client is offline, can't check it against Lighter")
+		return
+	}
+
+	// check that the API key registered on Lighter matches this one
+	key, err := client.HTTP().GetApiKey(accountIndex, apiKeyIndex)
+	if err != nil {
+		err = fmt.Errorf("failed to get Api Keys. err: %v", err)
+		return
+	}
+
+	pubKeyStr := keys.FromKeyManager(client.GetKeyManager()).APIHex()
+
+	ak := key.ApiKeys[0]
+	if ak.PublicKey != pubKeyStr {
+		err = fmt.Errorf("private key does not match the one on Lighter. ownPubKey: %s response: %+v", pubKeyStr, ak)
+		return
+	}
+
+	return
+}
+
+//export CheckAllClients
+func CheckAllClients() (ret C.StrOrErr) {
+	defer traceCall("CheckAllClients")()
+	var err error
+	var resultsStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(resultsStr),
+			}
+		}
+	}()
+
+	type checkResult struct {
+		AccountIndex int64  `json:"accountIndex"`
+		ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+		Ok           bool   `json:"ok"`
+		Error        string `json:"error,omitempty"`
+	}
+
+	registered, _ := snapshotClients()
+	byAccount := make(map[int64][]*client.TxClient)
+	for _, c := range registered {
+		byAccount[c.GetAccountIndex()] = append(byAccount[c.GetAccountIndex()], c)
+	}
+
+	results := make([]checkResult, 0, len(registered))
+	for accountIndex, clients := range byAccount {
+		// the keys of an account are fetched once, with the HTTP client of any of its clients
+		var serverKeys *client.AccountApiKeys
+		var fetchErr error
+		if clients[0].HTTP() == nil {
+			fetchErr = fmt.Errorf("client has no HTTP client")
+		} else {
+			serverKeys, fetchErr = clients[0].HTTP().GetApiKeys(accountIndex)
+		}
+
+		for _, c := range clients {
+			result := checkResult{AccountIndex: accountIndex, ApiKeyIndex: c.GetApiKeyIndex()}
+			if fetchErr != nil {
+				result.Error = fmt.Sprintf("failed to get Api Keys. err: %v", fetchErr)
+				results = append(results, result)
+				continue
+			}
+
+			pubKeyStr := keys.FromKeyManager(c.GetKeyManager()).APIHex()
+			result.Error = fmt.Sprintf("api key %v is not registered on Lighter", c.GetApiKeyIndex())
+			for _, ak := range serverKeys.ApiKeys {
+				if ak.ApiKeyIndex != c.GetApiKeyIndex() {
+					continue
+				}
+				if ak.PublicKey == pubKeyStr {
+					result.Ok, result.Error = true, ""
+				} else {
+					result.Error = fmt.Sprintf("private key does not match the one on Lighter. ownPubKey: %s response: %+v", pubKeyStr, ak)
+				}
+				break
+			}
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].AccountIndex != results[j].AccountIndex {
+			return results[i].AccountIndex < results[j].AccountIndex
+		}
+		return results[i].ApiKeyIndex < results[j].ApiKeyIndex
+	})
+
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		return
+	}
+
+	resultsStr = string(resultsBytes)
+	return
+}
+
+//export GetApiKey
+func GetApiKey(cAccountIndex C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("GetApiKey", cAccountIndex, cApiKeyIndex)()
+	var err error
+	var keysStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(keysStr),
+			}
+		}
+	}()
+
+	type apiKey struct {
+		AccountIndex int64  `json:"accountIndex"`
+		ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+		Nonce        int64  `json:"nonce"`
+		PublicKey    string `json:"publicKey"`
+	}
+
+	httpClient, err := queryClient()
+	if err != nil {
+		return
+	}
+
+	if cApiKeyIndex < 0 || cApiKeyIndex > 255 {
+		err = fmt.Errorf("invalid api key index %v", cApiKeyIndex)
+		return
+	}
+
+	// -1 is the account of the active client; an api key index of 255 asks for every key of the account
+	accountIndex, err := queryAccountIndex(int64(cAccountIndex))
+	if err != nil {
+		return
+	}
+	serverKeys, err := httpClient.GetApiKey(accountIndex, uint8(cApiKeyIndex))
+	if err != nil {
+		err = fmt.Errorf("failed to get Api Keys. err: %v", err)
+		return
+	}
+
+	res := make([]apiKey, 0, len(serverKeys.ApiKeys))
+	for _, ak := range serverKeys.ApiKeys {
+		res = append(res, apiKey{AccountIndex: ak.AccountIndex, ApiKeyIndex: ak.ApiKeyIndex, Nonce: ak.Nonce, PublicKey: ak.PublicKey})
+	}
+	keysBytes, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+
+	keysStr = string(keysBytes)
+	return
+}
+
+// defaultKeyRegistrationTimeout bounds how long SubmitKeyRegistration waits for the key to be live.
+const defaultKeyRegistrationTimeout = time.Minute
+
+//export EnsureKeyRegistered
+func EnsureKeyRegistered(cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("EnsureKeyRegistered", cApiKeyIndex)()
+	var err error
+	var resultStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(resultStr),
+			}
+		}
+	}()
+
+	// when the key isn't registered, TxInfo is the ChangePubKey tx registering it, to be passed to
+	// SubmitKeyRegistration with the L1 signature of MessageToSign
+	type registration struct {
+		Registered    bool   `json:"registered"`
+		Mismatch      string `json:"mismatch,omitempty"`
+		TxInfo        string `json:"txInfo,omitempty"`
+		MessageToSign string `json:"messageToSign,omitempty"`
+	}
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	res := registration{}
+	mismatch := c.CheckKeyRegistered()
+	switch {
+	case mismatch == nil:
+		res.Registered = true
+	case errors.Is(mismatch, client.ErrKeyMismatch):
+		var tx *txtypes.L2ChangePubKeyTxInfo
+		tx, err = c.GetKeyRegistrationTransaction(nil)
+		if err != nil {
+			return
+		}
+		res.Mismatch = mismatch.Error()
+		res.MessageToSign = tx.GetL1SignatureBody()
+		res.TxInfo, err = tx.GetTxInfo()
+		if err != nil {
+			return
+		}
+	default:
+		err = mismatch
+		return
+	}
+
+	resultBytes, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+
+	resultStr = string(resultBytes)
+	return
+}
+
+//export SubmitKeyRegistration
+func SubmitKeyRegistration(cTxInfo *C.char, cL1Signature *C.char, cTimeoutMs C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SubmitKeyRegistration", redacted, redacted, cTimeoutMs, cApiKeyIndex)()
+	var err error
+	var txHash string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txHash),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	if c.HTTP() == nil {
+		err = fmt.Errorf("client is offline, can't register its key")
+		return
+	}
+
+	parsed, err := txtypes.ParseTxInfo(txtypes.TxTypeL2ChangePubKey, []byte(C.GoString(cTxInfo)))
+	if err != nil {
+		return
+	}
+	tx := parsed.(*txtypes.L2ChangePubKeyTxInfo)
+
+	timeout := defaultKeyRegistrationTimeout
+	if cTimeoutMs > 0 {
+		timeout = time.Duration(cTimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	txHash, err = c.SubmitKeyRegistration(ctx, tx, C.GoString(cL1Signature), client.DefaultKeyRegistrationPollInterval)
+	return
+}
+
+//export RotateApiKey
+func RotateApiKey(cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("RotateApiKey", cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var resultStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(resultStr),
+			}
+		}
+	}()
+
+	// the new key must be stored by the caller before ConfirmRotateApiKey submits TxInfo
+	type rotation struct {
+		PrivateKey    string `json:"privateKey"`
+		PublicKey     string `json:"publicKey"`
+		TxInfo        string `json:"txInfo"`
+		MessageToSign string `json:"messageToSign"`
+	}
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	nonce := int64(cNonce)
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	pending, err := c.RotateApiKey(ops)
+	if err != nil {
+		return
+	}
+	txInfo, err := pending.Tx.GetTxInfo()
+	if err != nil {
+		return
+	}
+	res := rotation{
+		PrivateKey:    hexutil.Encode(pending.KeyManager.PrvKeyBytes()),
+		PublicKey:     keys.FromKeyManager(pending.KeyManager).Hex(),
+		TxInfo:        txInfo,
+		MessageToSign: pending.Tx.GetL1SignatureBody(),
+	}
+	resultBytes, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+
+	// a new rotation replaces the pending one of the client
+	key := clientKey{c.GetAccountIndex(), c.GetApiKeyIndex()}
+	stateMu.Lock()
+	dropRotation(key)
+	pendingRotations[key] = pending
+	stateMu.Unlock()
+
+	resultStr = string(resultBytes)
+	return
+}
+
+//export ConfirmRotateApiKey
+func ConfirmRotateApiKey(cL1Signature *C.char, cTimeoutMs C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("ConfirmRotateApiKey", redacted, cTimeoutMs, cApiKeyIndex)()
+	var err error
+	var txHash string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txHash),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	if c.HTTP() == nil {
+		err = fmt.Errorf("client is offline, can't rotate its key")
+		return
+	}
+	key := clientKey{c.GetAccountIndex(), c.GetApiKeyIndex()}
+	stateMu.RLock()
+	pending, ok := pendingRotations[key]
+	stateMu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("no pending key rotation, call RotateApiKey() first")
+		return
+	}
+
+	timeout := defaultKeyRegistrationTimeout
+	if cTimeoutMs > 0 {
+		timeout = time.Duration(cTimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rotated, txHash, err := c.ConfirmRotation(ctx, pending, C.GoString(cL1Signature))
+	if err != nil {
+		return
+	}
+
+	// the client is swapped only once the new key is live; the old key can't sign anymore
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	delete(pendingRotations, key)
+	registerClient(rotated)
+	if txClient == c {
+		txClient = rotated
+	}
+	c.InvalidateAuthToken()
+	wipeKey(c)
+	return
+}
+
+//export SignChangePubKey
+func SignChangePubKey(cPubKey *C.char, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignChangePubKey", C.GoString(cPubKey), cExpiredAt, cNonce, cApiKeyIndex)()
+	// Note: The ChangePubKey TX needs to be signed by the API key that's being changed to as well.
+	//       Because of that, there's no reason to add the params for apiKeyIndex & accountIndex, because this
+	//       version of the SDK doesn't have support for multiple signers.
+	//       Even if it'd had, the flow would look something like this:
+	//       - first you select which client you're sending the TX from
+	//       - then we use the ApiKeyIndex & AccountIndex from that client
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	nonce := int64(cNonce)
+
+	// handle PubKey
+	pubKeyStr := C.GoString(cPubKey)
+	pubKeyBytes, err := hexutil.Decode(pubKeyStr)
+	if err != nil {
+		return
+	}
+	if len(pubKeyBytes) != 40 {
+		err = fmt.Errorf("invalid pub key length. expected 40 but got %v", len(pubKeyBytes))
+		return
+	}
+	var pubKey [40]byte
+	copy(pubKey[:], pubKeyBytes)
+
+	txInfo := &types.ChangePubKeyReq{
+		PubKey: pubKey,
+	}
+	ops := &types.TransactOpts{}
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetChangePubKeyTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoStr, err = changePubKeyToSign(c, tx)
+	return
+}
+
+// changePubKeyToSign returns the JSON of a ChangePubKey tx with the L1 messages to sign added: MessageToSign,
+// and TypedDataToSign when typed data is enabled for the chain.
+func changePubKeyToSign(c *client.TxClient, tx *txtypes.L2ChangePubKeyTxInfo) (string, error) {
+	// === manually add MessageToSign to the response:
+	// - marshal the tx
+	// - unmarshal it into a generic map
+	// - add the new field
+	// - marshal it again
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+	obj := make(map[string]interface{})
+	err = json.Unmarshal(txInfoBytes, &obj)
+	if err != nil {
+		return "", err
+	}
+	obj["MessageToSign"] = tx.GetL1SignatureBody()
+	if typedData := tx.GetL1TypedData(c.GetChainId()); typedData != nil {
+		obj["TypedDataToSign"] = typedData
+	}
+	txInfoBytes, err = json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(txInfoBytes), nil
+}
+
+//export FinalizeChangePubKey
+func FinalizeChangePubKey(cTxInfo *C.char, cL1Signature *C.char, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("FinalizeChangePubKey", redacted, redacted, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	txInfoStr, err = finalizeChangePubKey(c, C.GoString(cTxInfo), C.GoString(cL1Signature))
+	return
+}
+
+// finalizeChangePubKey checks the L1 signature of a ChangePubKey tx and returns the JSON of the tx carrying it.
+func finalizeChangePubKey(c *client.TxClient, txInfo, l1Sig string) (string, error) {
+	// the output of SignChangePubKey is accepted as is, without its MessageToSign and TypedDataToSign
+	obj := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(txInfo), &obj); err != nil {
+		return "", err
+	}
+	delete(obj, "MessageToSign")
+	delete(obj, "TypedDataToSign")
+	txInfoBytes, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := txtypes.ParseTxInfo(txtypes.TxTypeL2ChangePubKey, txInfoBytes)
+	if err != nil {
+		return "", err
+	}
+	tx := parsed.(*txtypes.L2ChangePubKeyTxInfo)
+	if tx.AccountIndex != c.GetAccountIndex() {
+		return "", fmt.Errorf("ChangePubKey tx is for account %v, the client is for account %v", tx.AccountIndex, c.GetAccountIndex())
+	}
+
+	if err := c.CheckL1Signature(tx.GetL1SignatureBody(), l1Sig); err != nil {
+		return "", err
+	}
+	tx.L1Sig = l1Sig
+	return tx.GetTxInfo()
+}
+
+//export SignCreateOrder
+func SignCreateOrder(cMarketIndex C.int, cClientOrderIndex C.longlong, cBaseAmount C.longlong, cPrice C.int, cIsAsk C.int, cOrderType C.int, cTimeInForce C.int, cReduceOnly C.int, cTriggerPrice C.int, cOrderExpiry C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCreateOrder", cMarketIndex, cClientOrderIndex, cBaseAmount, cPrice, cIsAsk, cOrderType, cTimeInForce, cReduceOnly, cTriggerPrice, cOrderExpiry, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	err = checkOrderArgs(int64(cIsAsk), int64(cOrderType), int64(cTimeInForce), int64(cReduceOnly))
+	if err != nil {
+		return
+	}
+
+	marketIndex := uint8(cMarketIndex)
+	clientOrderIndex := int64(cClientOrderIndex)
+	baseAmount := int64(cBaseAmount)
+	price := uint32(cPrice)
+	isAsk := uint8(cIsAsk)
+	orderType := uint8(cOrderType)
+	timeInForce := uint8(cTimeInForce)
+	if cTimeInForce == -1 {
+		timeInForce = orders.DefaultTimeInForce
+	}
+	reduceOnly := uint8(cReduceOnly)
+	triggerPrice := uint32(cTriggerPrice)
+	orderExpiry := int64(cOrderExpiry)
+	nonce := int64(cNonce)
+
+	txInfo := &types.CreateOrderTxReq{
+		MarketIndex:      marketIndex,
+		ClientOrderIndex: clientOrderIndex,
+		BaseAmount:       baseAmount,
+		Price:            price,
+		IsAsk:            isAsk,
+		Type:             orderType,
+		TimeInForce:      timeInForce,
+		ReduceOnly:       reduceOnly,
+		TriggerPrice:     triggerPrice,
+		OrderExpiry:      orderExpiry,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCreateOrderTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignCreateOrders
+func SignCreateOrders(cOrders *C.char, cExpiredAt C.longlong, cStartingNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCreateOrders", C.GoString(cOrders), cExpiredAt, cStartingNonce, cApiKeyIndex)()
+	var err error
+	var txInfosStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfosStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	startingNonce := int64(cStartingNonce)
+
+	orderReqs, err := parseOrders(C.GoString(cOrders))
+	if err != nil {
+		return
+	}
+
+	ops := new(types.TransactOpts)
+	if startingNonce != -1 {
+		ops.Nonce = &startingNonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	txs, err := c.GetCreateOrderTransactions(orderReqs, ops)
+	if err != nil {
+		return
+	}
+
+	txInfosBytes, err := json.Marshal(txs)
+	if err != nil {
+		return
+	}
+
+	txInfosStr = string(txInfosBytes)
+	return
+}
+
+//export SignCreateGroupedOrders
+func SignCreateGroupedOrders(cGroupingType C.int, cOrders *C.char, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCreateGroupedOrders", cGroupingType, C.GoString(cOrders), cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	groupingType := uint8(cGroupingType)
+	nonce := int64(cNonce)
+
+	orderReqs, err := parseGroupedOrders(groupingType, C.GoString(cOrders))
+	if err != nil {
+		return
+	}
+
+	txInfo := &types.CreateGroupedOrdersTxReq{
+		GroupingType: groupingType,
+		Orders:       orderReqs,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCreateGroupedOrdersTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignCancelOrder
+func SignCancelOrder(cMarketIndex C.int, cOrderIndex C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCancelOrder", cMarketIndex, cOrderIndex, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	marketIndex := uint8(cMarketIndex)
+	orderIndex := int64(cOrderIndex)
+	nonce := int64(cNonce)
+
+	txInfo := &types.CancelOrderTxReq{
+		MarketIndex: marketIndex,
+		Index:       orderIndex,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCancelOrderTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignWithdraw
+func SignWithdraw(cUSDCAmount C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignWithdraw", cUSDCAmount, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	usdcAmount := uint64(cUSDCAmount)
+	nonce := int64(cNonce)
+
+	txInfo := types.WithdrawTxReq{
+		USDCAmount: usdcAmount,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetWithdrawTransaction(&txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignCreateSubAccount
+func SignCreateSubAccount(cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCreateSubAccount", cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	nonce := int64(cNonce)
+
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCreateSubAccountTransaction(ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignCancelAllOrders
+func SignCancelAllOrders(cTimeInForce C.int, cTime C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCancelAllOrders", cTimeInForce, cTime, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	timeInForce := uint8(cTimeInForce)
+	t := int64(cTime)
+	nonce := int64(cNonce)
+
+	txInfo := &types.CancelAllOrdersTxReq{
+		TimeInForce: timeInForce,
+		Time:        t,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCancelAllOrdersTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignScheduledCancelAll
+func SignScheduledCancelAll(cDelaySeconds C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignScheduledCancelAll", cDelaySeconds, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	txInfo, err := orders.ScheduledCancelAll(time.Duration(cDelaySeconds)*time.Second, time.Now())
+	if err != nil {
+		return
+	}
+	nonce := int64(cNonce)
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCancelAllOrdersTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	// add the time the orders get cancelled at, in milliseconds, 0 for an immediate cancel
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+	obj := make(map[string]interface{})
+	err = json.Unmarshal(txInfoBytes, &obj)
+	if err != nil {
+		return
+	}
+	obj["ScheduledAt"] = txInfo.Time
+	txInfoBytes, err = json.Marshal(obj)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+// modifyOrderReq builds the modify order request of SignModifyOrder and SendModifyOrder. An orderType of -1 means
+// the order type is unknown, and the trigger price isn't checked against it.
+func modifyOrderReq(marketIndex uint8, index, baseAmount int64, price, triggerPrice uint32, orderType int, orderExpiry int64) (*types.ModifyOrderTxReq, error) {
+	// The modify order tx doesn't carry an expiry, so the expiry of a resting order can't be changed in place.
+	if orderExpiry != -1 {
+		return nil, fmt.Errorf("orderExpiry: a modify order tx can't change the order expiry, cancel the order and create a new one")
+	}
+	req := &types.ModifyOrderTxReq{
+		MarketIndex:  marketIndex,
+		Index:        index,
+		BaseAmount:   baseAmount,
+		Price:        price,
+		TriggerPrice: triggerPrice,
+	}
+	if orderType != -1 {
+		t := uint8(orderType)
+		req.OrderType = &t
+	}
+	return req, nil
+}
+
+//export SignModifyOrder
+func SignModifyOrder(cMarketIndex C.int, cIndex C.longlong, cBaseAmount C.longlong, cPrice C.longlong, cTriggerPrice C.longlong, cOrderType C.int, cOrderExpiry C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignModifyOrder", cMarketIndex, cIndex, cBaseAmount, cPrice, cTriggerPrice, cOrderType, cOrderExpiry, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	nonce := int64(cNonce)
+
+	txInfo, err := modifyOrderReq(uint8(cMarketIndex), int64(cIndex), int64(cBaseAmount), uint32(cPrice), uint32(cTriggerPrice), int(cOrderType), int64(cOrderExpiry))
+	if err != nil {
+		return
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetModifyOrderTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignTransfer
+func SignTransfer(cToAccountIndex C.longlong, cUSDCAmount C.longlong, cFee C.longlong, cMemo *C.char, cHashLongMemo C.int, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignTransfer", cToAccountIndex, cUSDCAmount, cFee, C.GoString(cMemo), cHashLongMemo, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	txInfoStr, err = signTransfer(c, int64(cToAccountIndex), int64(cUSDCAmount), int64(cFee), C.GoString(cMemo), cHashLongMemo != 0, int64(cExpiredAt), int64(cNonce))
+	return
+}
+
+//export SignTransferToAddress
+func SignTransferToAddress(cToAddress *C.char, cUSDCAmount C.longlong, cFee C.longlong, cMemo *C.char, cHashLongMemo C.int, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignTransferToAddress", C.GoString(cToAddress), cUSDCAmount, cFee, C.GoString(cMemo), cHashLongMemo, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	if c.HTTP() == nil {
+		err = fmt.Errorf("resolving an L1 address requires an online client")
+		return
+	}
+
+	toAccountIndex, err := c.HTTP().ResolveAccountIndex(C.GoString(cToAddress))
+	if err != nil {
+		return
+	}
+
+	txInfoStr, err = signTransfer(c, toAccountIndex, int64(cUSDCAmount), int64(cFee), C.GoString(cMemo), cHashLongMemo != 0, int64(cExpiredAt), int64(cNonce))
+	return
+}
+
+// signTransfer signs a transfer and adds the L1 message to sign, its typed data form if enabled for the chain, and the
+// hex of the signed memo to the tx info.
+// The memo is parsed by types.ParseMemo: 32 raw bytes, 64 hex chars, or a shorter UTF-8 note.
+func signTransfer(c *client.TxClient, toAccountIndex, usdcAmount, fee int64, memoStr string, hashLongMemo bool, expiredAt, nonce int64) (string, error) {
+	memo, err := types.ParseMemo(memoStr, hashLongMemo)
+	if err != nil {
+		return "", err
+	}
+
+	txInfo := &types.TransferTxReq{
+		ToAccountIndex: toAccountIndex,
+		USDCAmount:     usdcAmount,
+		Fee:            fee,
+		Memo:           memo,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if expiredAt != -1 {
+		ops.ExpiredAt = expiredAt
+	}
+
+	tx, err := c.GetTransferTransaction(txInfo, ops)
+	if err != nil {
+		return "", err
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+
+	obj := make(map[string]interface{})
+	err = json.Unmarshal(txInfoBytes, &obj)
+	if err != nil {
+		return "", err
+	}
+	obj["MessageToSign"] = tx.GetL1SignatureBody()
+	if typedData := tx.GetL1TypedData(c.GetChainId()); typedData != nil {
+		obj["TypedDataToSign"] = typedData
+	}
+	obj["MemoHex"] = hex.EncodeToString(memo[:])
+	txInfoBytes, err = json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	return string(txInfoBytes), nil
+}
+
+//export SignCreatePublicPool
+func SignCreatePublicPool(cOperatorFee C.longlong, cInitialTotalShares C.longlong, cMinOperatorShareRate C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignCreatePublicPool", cOperatorFee, cInitialTotalShares, cMinOperatorShareRate, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	operatorFee := int64(cOperatorFee)
+	initialTotalShares := int64(cInitialTotalShares)
+	minOperatorShareRate := int64(cMinOperatorShareRate)
+	nonce := int64(cNonce)
+
+	txInfo := &types.CreatePublicPoolTxReq{
+		OperatorFee:          operatorFee,
+		InitialTotalShares:   initialTotalShares,
+		MinOperatorShareRate: minOperatorShareRate,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetCreatePublicPoolTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignUpdatePublicPool
+func SignUpdatePublicPool(cPublicPoolIndex C.longlong, cStatus C.int, cOperatorFee C.longlong, cMinOperatorShareRate C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignUpdatePublicPool", cPublicPoolIndex, cStatus, cOperatorFee, cMinOperatorShareRate, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	publicPoolIndex := int64(cPublicPoolIndex)
+	status := uint8(cStatus)
+	operatorFee := int64(cOperatorFee)
+	minOperatorShareRate := int64(cMinOperatorShareRate)
+	nonce := int64(cNonce)
+
+	txInfo := &types.UpdatePublicPoolTxReq{
+		PublicPoolIndex:      publicPoolIndex,
+		Status:               status,
+		OperatorFee:          operatorFee,
+		MinOperatorShareRate: minOperatorShareRate,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetUpdatePublicPoolTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignMintShares
+func SignMintShares(cPublicPoolIndex C.longlong, cShareAmount C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignMintShares", cPublicPoolIndex, cShareAmount, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	publicPoolIndex := int64(cPublicPoolIndex)
+	shareAmount := int64(cShareAmount)
+	nonce := int64(cNonce)
+
+	txInfo := &types.MintSharesTxReq{
+		PublicPoolIndex: publicPoolIndex,
+		ShareAmount:     shareAmount,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetMintSharesTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignBurnShares
+func SignBurnShares(cPublicPoolIndex C.longlong, cShareAmount C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignBurnShares", cPublicPoolIndex, cShareAmount, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	publicPoolIndex := int64(cPublicPoolIndex)
+	shareAmount := int64(cShareAmount)
+	nonce := int64(cNonce)
+
+	txInfo := &types.BurnSharesTxReq{
+		PublicPoolIndex: publicPoolIndex,
+		ShareAmount:     shareAmount,
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetBurnSharesTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export SignUpdateLeverage
+func SignUpdateLeverage(cMarketIndex C.int, cInitialMarginFraction C.int, cMarginMode C.int, cValidateAgainstMarket C.int, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignUpdateLeverage", cMarketIndex, cInitialMarginFraction, cMarginMode, cValidateAgainstMarket, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(txInfoStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	err = checkEnum("marginMode", int64(cMarginMode), txtypes.Constants().MarginModes)
+	if err != nil {
+		return
+	}
+
+	marketIndex := uint8(cMarketIndex)
+	initialMarginFraction := uint16(cInitialMarginFraction)
+	nonce := int64(cNonce)
+	marginMode := uint8(cMarginMode)
+
+	// The market range is only checked on request, and never by offline clients.
+	if cValidateAgainstMarket != 0 && c.HTTP() != nil {
+		err = c.HTTP().CheckInitialMarginFraction(marketIndex, initialMarginFraction)
+		if err != nil {
+			return
+		}
+	}
+
+	txInfo := &types.UpdateLeverageTxReq{
+		MarketIndex:           marketIndex,
+		InitialMarginFraction: initialMarginFraction,
+		MarginMode:            uint8(marginMode),
+	}
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
+
+	tx, err := c.GetUpdateLeverageTransaction(txInfo, ops)
+	if err != nil {
+		return
+	}
+
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	txInfoStr = string(txInfoBytes)
+	return
+}
+
+//export CreateAuthToken
+func CreateAuthToken(cDeadline C.longlong, cApiKeyIndex C.int, cAccountIndex C.longlong) (ret C.StrOrErr) {
+	defer traceCall("CreateAuthToken", cDeadline, cApiKeyIndex, cAccountIndex)()
+	var err error
+	var authToken string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(authToken),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), int64(cAccountIndex))
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	deadline := int64(cDeadline)
+	if deadline == 0 {
+		deadline = time.Now().Add(c.Defaults().AuthTokenTTL).Unix()
+	}
+
+	authToken, err = c.GetAuthToken(time.Unix(deadline, 0))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+//export GetValidAuthToken
+func GetValidAuthToken(cMinValiditySeconds C.longlong, cApiKeyIndex C.int, cAccountIndex C.longlong) (ret C.StrOrErr) {
+	defer traceCall("GetValidAuthToken", cMinValiditySeconds, cApiKeyIndex, cAccountIndex)()
+	var err error
+	var authToken string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(authToken),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), int64(cAccountIndex))
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	authToken, err = c.GetValidAuthToken(time.Duration(cMinValiditySeconds) * time.Second)
+	return
+}
+
+//export CreateAuthTokenFor
+func CreateAuthTokenFor(cAccountIndex C.longlong, cDeadline C.longlong) (ret C.StrOrErr) {
+	defer traceCall("CreateAuthTokenFor", cAccountIndex, cDeadline)()
+	var err error
+	var authToken string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(authToken),
+			}
+		}
+	}()
+
+	c := activeClient()
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	deadline := int64(cDeadline)
+	if deadline == 0 {
+		deadline = time.Now().Add(c.Defaults().AuthTokenTTL).Unix()
+	}
+
+	authToken, err = c.GetAuthTokenFor(int64(cAccountIndex), time.Unix(deadline, 0))
+	return
+}
+
+//export GetConstants
+func GetConstants() (ret C.StrOrErr) {
+	defer traceCall("GetConstants")()
+	var err error
+	var constantsStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(constantsStr),
+			}
+		}
+	}()
+
+	constantsBytes, err := json.Marshal(struct {
+		txtypes.ExportedConstants
+		DefaultExpireTimeMs int64 `json:"default_expire_time_ms"`
+	}{
+		ExportedConstants:   txtypes.Constants(),
+		DefaultExpireTimeMs: client.DefaultExpireTime.Milliseconds(),
+	})
+	if err != nil {
+		return
+	}
+
+	constantsStr = string(constantsBytes)
+	return
+}
+
+//export RawRequest
+func RawRequest(cMethod *C.char, cPath *C.char, cParams *C.char, cSkipResultCode C.int) (ret C.StrOrErr) {
+	defer traceCall("RawRequest", C.GoString(cMethod), C.GoString(cPath), redacted, cSkipResultCode)()
+	var err error
+	var respStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(respStr),
+			}
+		}
+	}()
+
+	c := activeClient()
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
+		return
+	}
+
+	method := strings.ToUpper(C.GoString(cMethod))
+	path := C.GoString(cPath)
+	params := map[string]any{}
+	if paramsStr := C.GoString(cParams); paramsStr != "" {
+		decoder := json.NewDecoder(strings.NewReader(paramsStr))
+		decoder.UseNumber() // keeps large integers intact
+		if err = decoder.Decode(&params); err != nil {
+			err = fmt.Errorf("params should be a JSON object. err: %v", err)
+			return
+		}
+	}
+
+	ctx := context.Background()
+	if cSkipResultCode != 0 {
+		ctx = client.WithRequestOpts(ctx, client.RequestOpts{SkipResultCode: true})
+	}
+
+	var resp json.RawMessage
+	switch method {
+	case http.MethodGet:
+		resp, err = c.HTTP().RawGet(ctx, path, params)
+	case http.MethodPost:
+		form := url.Values{}
+		for k, v := range params {
+			form.Set(k, fmt.Sprintf("%v", v))
+		}
+		resp, err = c.HTTP().RawPost(ctx, path, form, nil)
+	default:
+		err = fmt.Errorf("unsupported method %q, expected GET or POST", method)
+	}
+	if err != nil {
+		return
+	}
+
+	respStr = string(resp)
+	return
+}
+
+//export SetEndpoint
+func SetEndpoint(cUrl *C.char, cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("SetEndpoint", C.GoString(cUrl), cApiKeyIndex)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	// -1 targets the active client, other API keys are looked up in the active client's account
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	if c.HTTP() == nil {
+		err = fmt.Errorf("HTTPClient is nil, can't set endpoint")
+		return
+	}
+	err = c.HTTP().SetEndpoints(splitEndpoints(C.GoString(cUrl)))
+	return
+}
+
+//export DestroyClient
+func DestroyClient(cApiKeyIndex C.int, cAccountIndex C.longlong) (ret *C.char) {
+	defer traceCall("DestroyClient", cApiKeyIndex, cAccountIndex)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	key := clientKey{int64(cAccountIndex), uint8(cApiKeyIndex)}
+	c, ok := backupTxClients[key]
+	if !ok {
+		err = fmt.Errorf("no client initialized for api key")
+		return
+	}
+
+	delete(backupTxClients, key)
+	if txClient == c {
+		txClient = nil
+	}
+	c.InvalidateAuthToken()
+	forgetGeneratedKeyOf(c)
+	wipeKey(c)
+	dropRotation(key)
+
+	return
+}
+
+//export DestroyAllClients
+func DestroyAllClients() {
+	defer traceCall("DestroyAllClients")()
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	destroyAllClients()
+}
+
+// destroyAllClients is DestroyAllClients. stateMu must be held.
+func destroyAllClients() {
+	clients := backupTxClients
+	backupTxClients = nil
+	txClient = nil
+	readOnlyClient = nil
+	for _, c := range clients {
+		c.InvalidateAuthToken()
+		wipeKey(c)
+	}
+	for key := range pendingRotations {
+		dropRotation(key)
+	}
+	generated := generatedKeys
+	generatedKeys = make(map[string]signer.KeyManager)
+	for _, keyManager := range generated {
+		if wiper, ok := keyManager.(signer.Wiper); ok {
+			wiper.Wipe()
+		}
+	}
+}
+
+// forgetGeneratedKeyOf drops the key of a destroyed client from the generated keys registry. stateMu must be held.
+func forgetGeneratedKeyOf(c *client.TxClient) {
+	keyManager := c.GetKeyManager()
+	for publicKey, generated := range generatedKeys {
+		if generated == keyManager {
+			delete(generatedKeys, publicKey)
+		}
+	}
+}
+
+// dropRotation forgets the pending key rotation of a client, erasing its new key. stateMu must be held.
+func dropRotation(key clientKey) {
+	rotation, ok := pendingRotations[key]
+	if !ok {
+		return
+	}
+	delete(pendingRotations, key)
+	if wiper, ok := rotation.KeyManager.(signer.Wiper); ok {
+		wiper.Wipe()
+	}
+}
+
+// wipeKey erases the private key of a destroyed client, unless it's still held by a
+// registered client or by the generated keys registry. stateMu must be held.
+func wipeKey(c *client.TxClient) {
+	keyManager := c.GetKeyManager()
+	for _, other := range backupTxClients {
+		if other.GetKeyManager() == keyManager {
+			return
+		}
+	}
+	for _, generated := range generatedKeys {
+		if generated == keyManager {
+			return
+		}
+	}
+	if wiper, ok := keyManager.(signer.Wiper); ok {
+		wiper.Wipe()
+	}
+}
+
+// clientState is what ExportClientState saves of a registered client: enough to create it again.
+// HTTP settings (timeouts, headers, channel name) and client defaults aren't saved.
+type clientState struct {
+	Url          string `json:"url"` // comma-separated endpoints, empty for an offline client
+	PrivateKey   string `json:"privateKey"`
+	ChainId      uint32 `json:"chainId"`
+	AccountIndex int64  `json:"accountIndex"`
+	ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+	Active       bool   `json:"active"`
+}
+
+//export ExportClientState
+func ExportClientState(cPassphrase *C.char) (ret C.StrOrErr) {
+	defer traceCall("ExportClientState", redacted)()
+	var err error
+	var blob string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(blob),
+			}
+		}
+	}()
+
+	passphrase := C.GoString(cPassphrase)
+	if passphrase == "" {
+		err = fmt.Errorf("refusing to export the client state without a passphrase")
+		return
+	}
+
+	registered, active := snapshotClients()
+	states := make([]clientState, 0, len(registered))
+	for _, c := range registered {
+		state := clientState{
+			PrivateKey:   hexutil.Encode(c.GetKeyManager().PrvKeyBytes()),
+			ChainId:      c.GetChainId(),
+			AccountIndex: c.GetAccountIndex(),
+			ApiKeyIndex:  c.GetApiKeyIndex(),
+			Active:       c == active,
+		}
+		if c.HTTP() != nil {
+			state.Url = strings.Join(c.HTTP().Endpoints(), ",")
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].AccountIndex != states[j].AccountIndex {
+			return states[i].AccountIndex < states[j].AccountIndex
+		}
+		return states[i].ApiKeyIndex < states[j].ApiKeyIndex
+	})
+
+	statesBytes, err := json.Marshal(states)
+	if err != nil {
+		return
+	}
+	blob, err = keys.Seal(statesBytes, passphrase)
+	return
+}
+
+//export ImportClientState
+func ImportClientState(cBlob *C.char, cPassphrase *C.char) (ret *C.char) {
+	defer traceCall("ImportClientState", redacted, redacted)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	statesBytes, err := keys.Open(C.GoString(cBlob), C.GoString(cPassphrase))
+	if err != nil {
+		return
+	}
+	var states []clientState
+	if err = json.Unmarshal(statesBytes, &states); err != nil {
+		err = fmt.Errorf("invalid client state. err: %v", err)
+		return
+	}
+
+	// every client is created before the registered ones are replaced, so a failed import changes nothing
+	clients := make([]*client.TxClient, 0, len(states))
+	var active *client.TxClient
+	for _, state := range states {
+		var httpClient *client.HTTPClient
+		if state.Url != "" {
+			httpClient = newHTTPClient(state.Url)
+		}
+		var c *client.TxClient
+		c, err = client.NewTxClient(httpClient, state.PrivateKey, state.AccountIndex, state.ApiKeyIndex, state.ChainId)
+		if err != nil {
+			err = fmt.Errorf("failed to create client of account %v api key %v", state.AccountIndex, state.ApiKeyIndex)
+			return
+		}
+		clients = append(clients, c)
+		if state.Active {
+			active = c
+		}
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	destroyAllClients()
+	for _, c := range clients {
+		registerClient(c)
+	}
+	txClient = active
+	return
+}
+
+//export ListClients
+func ListClients() (ret C.StrOrErr) {
+	defer traceCall("ListClients")()
+	var err error
+	var clientsStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(clientsStr),
+			}
+		}
+	}()
+
+	type clientInfo struct {
+		AccountIndex int64  `json:"accountIndex"`
+		ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+		PublicKey    string `json:"publicKey"`
+		IsActive     bool   `json:"isActive"`
+	}
+	registered, active := snapshotClients()
+	clients := make([]clientInfo, 0, len(registered))
+	for _, c := range registered {
+		clients = append(clients, clientInfo{
+			AccountIndex: c.GetAccountIndex(),
+			ApiKeyIndex:  c.GetApiKeyIndex(),
+			PublicKey:    keys.FromKeyManager(c.GetKeyManager()).APIHex(),
+			IsActive:     c == active,
+		})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].AccountIndex != clients[j].AccountIndex {
+			return clients[i].AccountIndex < clients[j].AccountIndex
+		}
+		return clients[i].ApiKeyIndex < clients[j].ApiKeyIndex
+	})
+
+	clientsBytes, err := json.Marshal(clients)
+	if err != nil {
+		return
+	}
+
+	clientsStr = string(clientsBytes)
+	return
+}
+
+//export GetPublicKey
+func GetPublicKey(cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("GetPublicKey", cApiKeyIndex)()
+	var err error
+	var publicKeyStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(publicKeyStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	publicKeyBytes, err := json.Marshal(struct {
+		PublicKey    string `json:"publicKey"`
+		AccountIndex int64  `json:"accountIndex"`
+		ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+	}{
+		PublicKey:    keys.FromKeyManager(c.GetKeyManager()).Hex(),
+		AccountIndex: c.GetAccountIndex(),
+		ApiKeyIndex:  c.GetApiKeyIndex(),
+	})
+	if err != nil {
+		return
+	}
+
+	publicKeyStr = string(publicKeyBytes)
+	return
+}
+
+//export SetClientDefaults
+func SetClientDefaults(cOrderExpiryMs C.longlong, cAuthTokenTTLSeconds C.longlong, cTimeInForce C.int, cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("SetClientDefaults", cOrderExpiryMs, cAuthTokenTTLSeconds, cTimeInForce, cApiKeyIndex)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	err = c.SetDefaults(client.ClientDefaults{
+		OrderExpiry:  time.Duration(cOrderExpiryMs) * time.Millisecond,
+		AuthTokenTTL: time.Duration(cAuthTokenTTLSeconds) * time.Second,
+		TimeInForce:  uint8(cTimeInForce),
+	})
+	return
+}
+
+//export GetClientDefaults
+func GetClientDefaults(cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("GetClientDefaults", cApiKeyIndex)()
+	var err error
+	var defaultsStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(defaultsStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	defaults := c.Defaults()
+	defaultsBytes, err := json.Marshal(struct {
+		OrderExpiryMs       int64 `json:"orderExpiryMs"`
+		AuthTokenTTLSeconds int64 `json:"authTokenTTLSeconds"`
+		TimeInForce         uint8 `json:"defaultTimeInForce"`
+	}{
+		OrderExpiryMs:       defaults.OrderExpiry.Milliseconds(),
+		AuthTokenTTLSeconds: int64(defaults.AuthTokenTTL / time.Second),
+		TimeInForce:         defaults.TimeInForce,
+	})
+	if err != nil {
+		return
+	}
+
+	defaultsStr = string(defaultsBytes)
+	return
+}
+
+//export SetDebug
+func SetDebug(cEnabled C.int) {
+	debugMode.Store(cEnabled != 0)
+}
+
+//export GetSignerInfo
+func GetSignerInfo() (ret C.StrOrErr) {
+	defer traceCall("GetSignerInfo")()
+	var err error
+	var infoStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(infoStr),
+			}
+		}
+	}()
+
+	// identity fields stay null until a client is created
+	info := struct {
+		Version             string   `json:"version"`
+		ChainId             *int64   `json:"chainId"`
+		AccountIndex        *int64   `json:"accountIndex"`
+		ApiKeyIndex         *int64   `json:"apiKeyIndex"`
+		RegisteredClients   int      `json:"registeredClients"`
+		FatFingerProtection *bool    `json:"fatFingerProtection"`
+		Endpoint            *string  `json:"endpoint"`
+		Endpoints           []string `json:"endpoints"`
+		ReadOnly            bool     `json:"readOnly"`
+	}{
+		Version: version,
+	}
+	registered, active := snapshotClients()
+	roClient, roChainId := readOnly()
+	info.RegisteredClients = len(registered)
+	if c := active; c != nil {
+		chainId := int64(c.GetChainId())
+		accountIndex := c.GetAccountIndex()
+		apiKeyIndex := int64(c.GetApiKeyIndex())
+		info.ChainId = &chainId
+		info.AccountIndex = &accountIndex
+		info.ApiKeyIndex = &apiKeyIndex
+		if c.HTTP() != nil {
+			fatFingerProtection := c.HTTP().FatFingerProtection()
+			info.FatFingerProtection = &fatFingerProtection
+			// the endpoint in use, which differs from the primary one after a failover
+			endpoint := c.HTTP().Endpoint()
+			info.Endpoint = &endpoint
+			info.Endpoints = c.HTTP().Endpoints()
+		}
+	} else if roClient != nil {
+		chainId := int64(roChainId)
+		endpoint := roClient.Endpoint()
+		info.ChainId = &chainId
+		info.Endpoint = &endpoint
+		info.Endpoints = roClient.Endpoints()
+		info.ReadOnly = true
+	}
+
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+
+	infoStr = string(infoBytes)
+	return
+}
+
+//export HealthCheck
+func HealthCheck(cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("HealthCheck", cApiKeyIndex)()
+	var err error
+	var checkStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(checkStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	checkBytes, err := json.Marshal(c.HealthCheck(context.Background()))
+	if err != nil {
+		return
+	}
+
+	checkStr = string(checkBytes)
+	return
+}
+
+//export GetRateLimitStatus
+func GetRateLimitStatus(cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("GetRateLimitStatus", cApiKeyIndex)()
+	var err error
+	var statusStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(statusStr),
+			}
+		}
+	}()
+
+	// the active client falls back to the read-only client, like the query exports
+	var httpClient *client.HTTPClient
+	if cApiKeyIndex == -1 {
+		httpClient, err = queryClient()
+		if err != nil {
+			return
+		}
+	} else {
+		c := resolveClient(int(cApiKeyIndex), -1)
+		if c == nil || c.HTTP() == nil {
+			err = errNoClient()
+			return
+		}
+		httpClient = c.HTTP()
+	}
+
+	// times are in unix milliseconds, 0 when unknown
+	type rateLimitStatus struct {
+		Limit        int64 `json:"limit"`
+		Remaining    int64 `json:"remaining"`
+		ResetAt      int64 `json:"resetAt"`
+		RetryAfterMs int64 `json:"retryAfterMs"`
+		UpdatedAt    int64 `json:"updatedAt"`
+	}
+	status := httpClient.RateLimitStatus()
+	res := rateLimitStatus{
+		Limit:        status.Limit,
+		Remaining:    status.Remaining,
+		RetryAfterMs: status.RetryAfter.Milliseconds(),
+	}
+	if !status.ResetAt.IsZero() {
+		res.ResetAt = status.ResetAt.UnixMilli()
+	}
+	if !status.UpdatedAt.IsZero() {
+		res.UpdatedAt = status.UpdatedAt.UnixMilli()
+	}
+
+	statusBytes, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+
+	statusStr = string(statusBytes)
+	return
+}
+
+//export VerifyChainId
+func VerifyChainId(cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("VerifyChainId", cApiKeyIndex)()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+
+	// e.g. after a failover, to make sure the endpoint in use is on the client's chain
+	err = c.VerifyChainId(context.Background())
+	return
+}
+
+//export CheckOrderPrice
+func CheckOrderPrice(cMarketIndex C.int, cPrice C.int, cIsAsk C.int, cThresholdBps C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("CheckOrderPrice", cMarketIndex, cPrice, cIsAsk, cThresholdBps, cApiKeyIndex)()
+	var err error
+	var checkStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(checkStr),
+			}
+		}
+	}()
+
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
+		return
+	}
+
+	check, err := c.HTTP().CheckOrderPrice(uint8(cMarketIndex), uint32(cPrice), cIsAsk == 1, int64(cThresholdBps))
+	if err != nil {
+		return
+	}
+
+	checkBytes, err := json.Marshal(check)
+	if err != nil {
+		return
+	}
+
+	checkStr = string(checkBytes)
+	return
+}
+
+//export GetNextNonce
+func GetNextNonce(cApiKeyIndex C.int, cAccountIndex C.longlong) (ret C.StrOrErr) {
+	defer traceCall("GetNextNonce", cApiKeyIndex, cAccountIndex)()
+	var err error
+	var nonceStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(nonceStr),
+			}
+		}
+	}()
+
+	// a read-only client takes explicit indexes, it has no account of its own
+	var nonce int64
+	c := resolveClient(int(cApiKeyIndex), int64(cAccountIndex))
+	roClient, _ := readOnly()
+	switch {
+	case c != nil && c.HTTP() != nil:
+		nonce, err = c.HTTP().GetNextNonce(c.GetAccountIndex(), c.GetApiKeyIndex())
+	case c == nil && roClient != nil && cApiKeyIndex != -1 && cAccountIndex != -1:
+		nonce, err = roClient.GetNextNonce(int64(cAccountIndex), uint8(cApiKeyIndex))
+	default:
+		err = fmt.Errorf("client is not created, call CreateClient() first")
+	}
+	if err != nil {
+		return
+	}
+
+	nonceStr = strconv.FormatInt(nonce, 10)
+	return
+}
+
+//export SetFatFingerProtection
+func SetFatFingerProtection(cEnabled C.int, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SetFatFingerProtection", cEnabled, cApiKeyIndex)()
+	var err error
+	var enabledStr string
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = C.StrOrErr{
+				err: wrapErr(err),
+			}
+		} else {
+			ret = C.StrOrErr{
+				str: C.CString(enabledStr),
+			}
+		}
+	}()
+
+	// -1 targets the active client, other API keys are looked up in the active client's account
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
+		return
 	}
 
-	key := curve.SampleScalar(seedP)
-
-	publicKeyStr = hexutil.Encode(schnorr.SchnorrPkFromSk(key).ToLittleEndianBytes())
-	privateKeyStr = hexutil.Encode(key.ToLittleEndianBytes())
-
+	c.HTTP().SetFatFingerProtection(cEnabled != 0)
+	enabledStr = strconv.FormatBool(c.HTTP().FatFingerProtection())
 	return
 }
 
-//export CreateClient
-func CreateClient(cUrl *C.char, cPrivateKey *C.char, cChainId C.int, cApiKeyIndex C.int, cAccountIndex C.longlong) (ret *C.char) {
+//export SetChannelName
+func SetChannelName(cName *C.char, cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("SetChannelName", C.GoString(cName), cApiKeyIndex)()
 	var err error
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = wrapErr(err)
 		}
 	}()
 
-	url := C.GoString(cUrl)
-	privateKey := C.GoString(cPrivateKey)
-	chainId := uint32(cChainId)
-	apiKeyIndex := uint8(cApiKeyIndex)
-	accountIndex := int64(cAccountIndex)
-
-	if accountIndex <= 0 {
-		err = fmt.Errorf("invalid account index")
-		return
-	}
-
-	httpClient := client.NewHTTPClient(url)
-	txClient, err = client.NewTxClient(httpClient, privateKey, accountIndex, apiKeyIndex, chainId)
-	if err != nil {
-		err = fmt.Errorf("error occurred when creating TxClient. err: %v", err)
+	// -1 targets the active client, other API keys are looked up in the active client's account
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
 		return
 	}
-	if backupTxClients == nil {
-		backupTxClients = make(map[uint8]*client.TxClient)
-	}
-	backupTxClients[apiKeyIndex] = txClient
 
-	return nil
+	c.HTTP().SetChannelName(C.GoString(cName))
+	return
 }
 
-//export CheckClient
-func CheckClient(cApiKeyIndex C.int, cAccountIndex C.longlong) (ret *C.char) {
+//export SetDefaultHeader
+func SetDefaultHeader(cKey *C.char, cValue *C.char, cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("SetDefaultHeader", C.GoString(cKey), redacted, cApiKeyIndex)()
 	var err error
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = wrapErr(err)
 		}
 	}()
 
-	apiKeyIndex := uint8(cApiKeyIndex)
-	accountIndex := int64(cAccountIndex)
-
-	client, ok := backupTxClients[apiKeyIndex]
-	if !ok {
-		err = fmt.Errorf("api key not registered")
-		return
-	}
-
-	if client.GetApiKeyIndex() != apiKeyIndex {
-		err = fmt.Errorf("apiKeyIndex does not match. expected %v but got %v", client.GetApiKeyIndex(), apiKeyIndex)
-		return
-	}
-	if client.GetAccountIndex() != accountIndex {
-		err = fmt.Errorf("accountIndex does not match. expected %v but got %v", client.GetAccountIndex(), accountIndex)
-		return
-	}
-
-	// check that the API key registered on Lighter matches this one
-	key, err := client.HTTP().GetApiKey(accountIndex, apiKeyIndex)
-	if err != nil {
-		err = fmt.Errorf("failed to get Api Keys. err: %v", err)
-		return
-	}
-
-	pubKeyBytes := client.GetKeyManager().PubKeyBytes()
-	pubKeyStr := hexutil.Encode(pubKeyBytes[:])
-	pubKeyStr = strings.Replace(pubKeyStr, "0x", "", 1)
-
-	ak := key.ApiKeys[0]
-	if ak.PublicKey != pubKeyStr {
-		err = fmt.Errorf("private key does not match the one on Lighter. ownPubKey: %s response: %+v", pubKeyStr, ak)
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
 		return
 	}
 
+	err = c.HTTP().SetDefaultHeader(C.GoString(cKey), C.GoString(cValue))
 	return
 }
 
-//export SignChangePubKey
-func SignChangePubKey(cPubKey *C.char, cNonce C.longlong) (ret C.StrOrErr) {
-	// Note: The ChangePubKey TX needs to be signed by the API key that's being changed to as well.
-	//       Because of that, there's no reason to add the params for apiKeyIndex & accountIndex, because this
-	//       version of the SDK doesn't have support for multiple signers.
-	//       Even if it'd had, the flow would look something like this:
-	//       - first you select which client you're sending the TX from
-	//       - then we use the ApiKeyIndex & AccountIndex from that client
+//export SetHTTPTimeout
+func SetHTTPTimeout(cTimeoutMs C.longlong, cSendTimeoutMs C.longlong, cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("SetHTTPTimeout", cTimeoutMs, cSendTimeoutMs, cApiKeyIndex)()
 	var err error
-	var txInfoStr string
-
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
-			ret = C.StrOrErr{
-				err: wrapErr(err),
-			}
-		} else {
-			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
-			}
+			ret = wrapErr(err)
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
-		return
-	}
-
-	nonce := int64(cNonce)
-
-	// handle PubKey
-	pubKeyStr := C.GoString(cPubKey)
-	pubKeyBytes, err := hexutil.Decode(pubKeyStr)
-	if err != nil {
-		return
-	}
-	if len(pubKeyBytes) != 40 {
-		err = fmt.Errorf("invalid pub key length. expected 40 but got %v", len(pubKeyBytes))
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
 		return
 	}
-	var pubKey [40]byte
-	copy(pubKey[:], pubKeyBytes)
 
-	txInfo := &types.ChangePubKeyReq{
-		PubKey: pubKey,
-	}
-	ops := &types.TransactOpts{}
-	if nonce != -1 {
-		ops.Nonce = &nonce
-	}
+	// a send timeout of 0 uses the same timeout for txs as for the other requests
+	c.HTTP().SetTimeout(time.Duration(cTimeoutMs) * time.Millisecond)
+	c.HTTP().SetSendTimeout(time.Duration(cSendTimeoutMs) * time.Millisecond)
+	return
+}
 
-	tx, err := txClient.GetChangePubKeyTransaction(txInfo, ops)
-	if err != nil {
-		return
-	}
+//export EnableL1TypedData
+func EnableL1TypedData(cChainId C.int, cName *C.char, cVersion *C.char, cL1ChainId C.longlong, cVerifyingContract *C.char) (ret *C.char) {
+	defer traceCall("EnableL1TypedData", cChainId, C.GoString(cName), C.GoString(cVersion), cL1ChainId, C.GoString(cVerifyingContract))()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
 
-	// === manually add MessageToSign to the response:
-	// - marshal the tx
-	// - unmarshal it into a generic map
-	// - add the new field
-	// - marshal it again
-	txInfoBytes, err := json.Marshal(tx)
-	if err != nil {
+	// SignChangePubKey and SignTransfer add TypedDataToSign, next to MessageToSign, for clients of this chain
+	verifyingContract := C.GoString(cVerifyingContract)
+	if !common.IsHexAddress(verifyingContract) {
+		err = fmt.Errorf("invalid verifying contract address %q", verifyingContract)
 		return
 	}
-	obj := make(map[string]interface{})
-	err = json.Unmarshal(txInfoBytes, &obj)
-	obj["MessageToSign"] = tx.GetL1SignatureBody()
-	txInfoBytes, err = json.Marshal(obj)
-	if err != nil {
+	if cL1ChainId <= 0 {
+		err = fmt.Errorf("invalid L1 chain id %v", int64(cL1ChainId))
 		return
 	}
-
-	txInfoStr = string(txInfoBytes)
+	txtypes.EnableL1TypedData(uint32(cChainId), txtypes.TypedDataDomain{
+		Name:              C.GoString(cName),
+		Version:           C.GoString(cVersion),
+		ChainId:           uint64(cL1ChainId),
+		VerifyingContract: common.HexToAddress(verifyingContract).Hex(),
+	})
 	return
 }
 
-//export SignCreateOrder
-func SignCreateOrder(cMarketIndex C.int, cClientOrderIndex C.longlong, cBaseAmount C.longlong, cPrice C.int, cIsAsk C.int, cOrderType C.int, cTimeInForce C.int, cReduceOnly C.int, cTriggerPrice C.int, cOrderExpiry C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export DisableL1TypedData
+func DisableL1TypedData(cChainId C.int) {
+	defer traceCall("DisableL1TypedData", cChainId)()
+	txtypes.DisableL1TypedData(uint32(cChainId))
+}
+
+//export GetL1SignatureBody
+func GetL1SignatureBody(cTxType C.int, cTxInfo *C.char) (ret C.StrOrErr) {
+	defer traceCall("GetL1SignatureBody", cTxType, redacted)()
 	var err error
-	var txInfoStr string
+	var bodyStr string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -255,71 +3709,35 @@ func SignCreateOrder(cMarketIndex C.int, cClientOrderIndex C.longlong, cBaseAmou
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(bodyStr),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
-		return
-	}
-
-	marketIndex := uint8(cMarketIndex)
-	clientOrderIndex := int64(cClientOrderIndex)
-	baseAmount := int64(cBaseAmount)
-	price := uint32(cPrice)
-	isAsk := uint8(cIsAsk)
-	orderType := uint8(cOrderType)
-	timeInForce := uint8(cTimeInForce)
-	reduceOnly := uint8(cReduceOnly)
-	triggerPrice := uint32(cTriggerPrice)
-	orderExpiry := int64(cOrderExpiry)
-	nonce := int64(cNonce)
-
-	if orderExpiry == -1 {
-		orderExpiry = time.Now().Add(time.Hour * 24 * 28).UnixMilli() // 28 days
-	}
-
-	txInfo := &types.CreateOrderTxReq{
-		MarketIndex:      marketIndex,
-		ClientOrderIndex: clientOrderIndex,
-		BaseAmount:       baseAmount,
-		Price:            price,
-		IsAsk:            isAsk,
-		Type:             orderType,
-		TimeInForce:      timeInForce,
-		ReduceOnly:       reduceOnly,
-		TriggerPrice:     triggerPrice,
-		OrderExpiry:      orderExpiry,
-	}
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
-	}
-
-	tx, err := txClient.GetCreateOrderTransaction(txInfo, ops)
+	tx, err := parseTx(uint8(cTxType), C.GoString(cTxInfo))
 	if err != nil {
 		return
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
-	if err != nil {
+	l1Tx, ok := tx.(txtypes.L1Signable)
+	if !ok {
+		err = fmt.Errorf("tx type %d has no L1 body", uint8(cTxType))
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	bodyStr = l1Tx.GetL1SignatureBody()
 	return
 }
 
-//export SignCancelOrder
-func SignCancelOrder(cMarketIndex C.int, cOrderIndex C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export ComputeTxHash
+func ComputeTxHash(cTxType C.int, cTxInfo *C.char, cChainId C.int) (ret C.StrOrErr) {
+	defer traceCall("ComputeTxHash", cTxType, redacted, cChainId)()
 	var err error
-	var txInfoStr string
+	var hashStr string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -327,51 +3745,34 @@ func SignCancelOrder(cMarketIndex C.int, cOrderIndex C.longlong, cNonce C.longlo
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(hashStr),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
-		return
-	}
-
-	marketIndex := uint8(cMarketIndex)
-	orderIndex := int64(cOrderIndex)
-	nonce := int64(cNonce)
-
-	txInfo := &types.CancelOrderTxReq{
-		MarketIndex: marketIndex,
-		Index:       orderIndex,
-	}
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
-	}
-
-	tx, err := txClient.GetCancelOrderTransaction(txInfo, ops)
+	tx, err := parseTx(uint8(cTxType), C.GoString(cTxInfo))
 	if err != nil {
 		return
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	msgHash, err := tx.Hash(uint32(cChainId))
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	hashStr = hex.EncodeToString(msgHash)
 	return
 }
 
-//export SignWithdraw
-func SignWithdraw(cUSDCAmount C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export DecodeTxInfo
+func DecodeTxInfo(cTxType C.int, cTxInfo *C.char, cChainId C.int) (ret C.StrOrErr) {
+	defer traceCall("DecodeTxInfo", cTxType, redacted, cChainId)()
 	var err error
-	var txInfoStr string
+	var decodedStr string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -379,95 +3780,76 @@ func SignWithdraw(cUSDCAmount C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(decodedStr),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	tx, err := parseTx(uint8(cTxType), C.GoString(cTxInfo))
+	if err != nil {
 		return
 	}
 
-	usdcAmount := uint64(cUSDCAmount)
-	nonce := int64(cNonce)
-
-	txInfo := types.WithdrawTxReq{
-		USDCAmount: usdcAmount,
-	}
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
-	}
-
-	tx, err := txClient.GetWithdrawTransaction(&txInfo, ops)
+	decoded, err := txtypes.DecodeTx(tx, uint32(cChainId))
 	if err != nil {
 		return
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	decodedBytes, err := json.Marshal(decoded)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	decodedStr = string(decodedBytes)
 	return
 }
 
-//export SignCreateSubAccount
-func SignCreateSubAccount(cNonce C.longlong) (ret C.StrOrErr) {
+//export VerifyTxSignature
+func VerifyTxSignature(cTxType C.int, cTxInfo *C.char, cPubKey *C.char, cChainId C.int) (ret C.StrOrErr) {
+	defer traceCall("VerifyTxSignature", cTxType, redacted, C.GoString(cPubKey), cChainId)()
 	var err error
-	var txInfoStr string
+	valid := false
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
+		}
+		// an invalid signature is reported as "false" along with the reason
+		ret = C.StrOrErr{
+			str: C.CString(strconv.FormatBool(valid)),
 		}
 		if err != nil {
-			ret = C.StrOrErr{
-				err: wrapErr(err),
-			}
-		} else {
-			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
-			}
+			ret.err = wrapErr(err)
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	tx, err := parseTx(uint8(cTxType), C.GoString(cTxInfo))
+	if err != nil {
 		return
 	}
 
-	nonce := int64(cNonce)
-
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
-	}
-
-	tx, err := txClient.GetCreateSubAccountTransaction(ops)
+	pubKey, err := keys.ParseAPIHex(strings.TrimPrefix(C.GoString(cPubKey), "0x"))
 	if err != nil {
 		return
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
-	if err != nil {
+	if err = txtypes.VerifySignature(tx, uint32(cChainId), pubKey.Bytes()); err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	valid = true
 	return
 }
 
-//export SignCancelAllOrders
-func SignCancelAllOrders(cTimeInForce C.int, cTime C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export SignMessage
+func SignMessage(cMessage *C.char, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignMessage", redacted, cApiKeyIndex)()
 	var err error
-	var txInfoStr string
+	var signature string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -475,51 +3857,52 @@ func SignCancelAllOrders(cTimeInForce C.int, cTime C.longlong, cNonce C.longlong
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(signature),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
 		return
 	}
 
-	timeInForce := uint8(cTimeInForce)
-	t := int64(cTime)
-	nonce := int64(cNonce)
-
-	txInfo := &types.CancelAllOrdersTxReq{
-		TimeInForce: timeInForce,
-		Time:        t,
-	}
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
-	}
+	signature, err = types.SignMessage(c.GetKeyManager(), []byte(C.GoString(cMessage)))
+	return
+}
 
-	tx, err := txClient.GetCancelAllOrdersTransaction(txInfo, ops)
-	if err != nil {
-		return
-	}
+//export VerifyMessage
+func VerifyMessage(cMessage *C.char, cSignature *C.char, cPubKey *C.char) (ret *C.char) {
+	defer traceCall("VerifyMessage", redacted, redacted, C.GoString(cPubKey))()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
 
-	txInfoBytes, err := json.Marshal(tx)
+	pubKey, err := keys.ParseAPIHex(strings.TrimPrefix(C.GoString(cPubKey), "0x"))
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	err = types.VerifyMessage([]byte(C.GoString(cMessage)), C.GoString(cSignature), pubKey.Bytes())
 	return
 }
 
-//export SignModifyOrder
-func SignModifyOrder(cMarketIndex C.int, cIndex C.longlong, cBaseAmount C.longlong, cPrice C.longlong, cTriggerPrice C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export SwitchAPIKey
+func SwitchAPIKey(c C.int, cAccountIndex C.longlong) (ret C.StrOrErr) {
+	defer traceCall("SwitchAPIKey", c, cAccountIndex)()
 	var err error
-	var txInfoStr string
+	var previousStr string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -527,57 +3910,94 @@ func SignModifyOrder(cMarketIndex C.int, cIndex C.longlong, cBaseAmount C.longlo
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(previousStr),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
-		return
-	}
-
-	marketIndex := uint8(cMarketIndex)
-	index := int64(cIndex)
-	baseAmount := int64(cBaseAmount)
-	price := uint32(cPrice)
-	triggerPrice := uint32(cTriggerPrice)
-	nonce := int64(cNonce)
+	apiKeyIndex := uint8(c)
 
-	txInfo := &types.ModifyOrderTxReq{
-		MarketIndex:  marketIndex,
-		Index:        index,
-		BaseAmount:   baseAmount,
-		Price:        price,
-		TriggerPrice: triggerPrice,
-	}
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	// -1 keeps the active client's account
+	accountIndex := int64(cAccountIndex)
+	if accountIndex == -1 && txClient != nil {
+		accountIndex = txClient.GetAccountIndex()
 	}
 
-	tx, err := txClient.GetModifyOrderTransaction(txInfo, ops)
-	if err != nil {
+	next := backupTxClients[clientKey{accountIndex, apiKeyIndex}]
+	if next == nil {
+		for key := range backupTxClients {
+			if key.apiKeyIndex == apiKeyIndex {
+				err = fmt.Errorf("api key %v is registered for account %v, not account %v", apiKeyIndex, key.accountIndex, accountIndex)
+				return
+			}
+		}
+		err = fmt.Errorf("no client initialized for api key")
 		return
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	previous := struct {
+		AccountIndex int64 `json:"accountIndex"`
+		ApiKeyIndex  int   `json:"apiKeyIndex"`
+	}{AccountIndex: -1, ApiKeyIndex: -1}
+	if txClient != nil {
+		previous.AccountIndex = txClient.GetAccountIndex()
+		previous.ApiKeyIndex = int(txClient.GetApiKeyIndex())
+	}
+	previousBytes, err := json.Marshal(previous)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	if txClient != nil {
+		txClient.InvalidateAuthToken()
+	}
+	txClient = next
+	previousStr = string(previousBytes)
 	return
 }
 
-//export SignTransfer
-func SignTransfer(cToAccountIndex C.longlong, cUSDCAmount C.longlong, cFee C.longlong, cMemo *C.char, cNonce C.longlong) (ret C.StrOrErr) {
+//export SwitchClient
+func SwitchClient(cAccountIndex C.longlong, cApiKeyIndex C.int) (ret *C.char) {
+	defer traceCall("SwitchClient", cAccountIndex, cApiKeyIndex)()
 	var err error
-	var txInfoStr string
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicErr(r)
+		}
+		if err != nil {
+			ret = wrapErr(err)
+		}
+	}()
+
+	err = switchClient(int64(cAccountIndex), uint8(cApiKeyIndex))
+	return
+}
+
+// switchClient makes the registered client of the account and API key the active one.
+func switchClient(accountIndex int64, apiKeyIndex uint8) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	next := backupTxClients[clientKey{accountIndex, apiKeyIndex}]
+	if next == nil {
+		return fmt.Errorf("no client initialized for account %v api key %v", accountIndex, apiKeyIndex)
+	}
+	if txClient != nil {
+		txClient.InvalidateAuthToken()
+	}
+	txClient = next
+	return nil
+}
 
+//export SignUpdateMargin
+func SignUpdateMargin(cMarketIndex C.int, cUSDCAmount C.longlong, cDirection C.int, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignUpdateMargin", cMarketIndex, cUSDCAmount, cDirection, cExpiredAt, cNonce, cApiKeyIndex)()
+	var err error
+	var txInfoStr string
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -590,51 +4010,41 @@ func SignTransfer(cToAccountIndex C.longlong, cUSDCAmount C.longlong, cFee C.lon
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
 		return
 	}
 
-	toAccountIndex := int64(cToAccountIndex)
-	usdcAmount := int64(cUSDCAmount)
-	nonce := int64(cNonce)
-	fee := int64(cFee)
-	memo := [32]byte{}
-	memoStr := C.GoString(cMemo)
-	if len(memoStr) != 32 {
-		err = fmt.Errorf("memo expected to be 32 bytes long")
+	err = checkEnum("direction", int64(cDirection), txtypes.Constants().MarginDirections)
+	if err != nil {
 		return
 	}
-	for i := 0; i < 32; i++ {
-		memo[i] = byte(memoStr[i])
-	}
 
-	txInfo := &types.TransferTxReq{
-		ToAccountIndex: toAccountIndex,
-		USDCAmount:     usdcAmount,
-		Fee:            fee,
-		Memo:           memo,
+	marketIndex := uint8(cMarketIndex)
+	usdcAmount := int64(cUSDCAmount)
+	direction := uint8(cDirection)
+	nonce := int64(cNonce)
+
+	txInfo := &types.UpdateMarginTxReq{
+		MarketIndex: marketIndex,
+		USDCAmount:  usdcAmount,
+		Direction:   direction,
 	}
 	ops := new(types.TransactOpts)
 	if nonce != -1 {
 		ops.Nonce = &nonce
 	}
-
-	tx, err := txClient.GetTransferTransaction(txInfo, ops)
-	if err != nil {
-		return
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	tx, err := c.GetUpdateMarginTransaction(txInfo, ops)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
-	obj := make(map[string]interface{})
-	err = json.Unmarshal(txInfoBytes, &obj)
-	obj["MessageToSign"] = tx.GetL1SignatureBody()
-	txInfoBytes, err = json.Marshal(obj)
+	txInfoBytes, err := json.Marshal(tx)
 	if err != nil {
 		return
 	}
@@ -643,14 +4053,15 @@ func SignTransfer(cToAccountIndex C.longlong, cUSDCAmount C.longlong, cFee C.lon
 	return
 }
 
-//export SignCreatePublicPool
-func SignCreatePublicPool(cOperatorFee C.longlong, cInitialTotalShares C.longlong, cMinOperatorShareRate C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export SignUpdateMarginAmount
+func SignUpdateMarginAmount(cMarketIndex C.int, cAmount *C.char, cAction *C.char, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int) (ret C.StrOrErr) {
+	defer traceCall("SignUpdateMarginAmount", cMarketIndex, C.GoString(cAmount), C.GoString(cAction), cExpiredAt, cNonce, cApiKeyIndex)()
 	var err error
 	var txInfoStr string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -663,27 +4074,34 @@ func SignCreatePublicPool(cOperatorFee C.longlong, cInitialTotalShares C.longlon
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
 		return
 	}
 
-	operatorFee := int64(cOperatorFee)
-	initialTotalShares := int64(cInitialTotalShares)
-	minOperatorShareRate := int64(cMinOperatorShareRate)
+	marketIndex := uint8(cMarketIndex)
 	nonce := int64(cNonce)
 
-	txInfo := &types.CreatePublicPoolTxReq{
-		OperatorFee:          operatorFee,
-		InitialTotalShares:   initialTotalShares,
-		MinOperatorShareRate: minOperatorShareRate,
+	usdcAmount, direction, err := types.ParseUpdateMarginAmount(C.GoString(cAmount), C.GoString(cAction))
+	if err != nil {
+		return
+	}
+
+	txInfo := &types.UpdateMarginTxReq{
+		MarketIndex: marketIndex,
+		USDCAmount:  usdcAmount,
+		Direction:   direction,
 	}
 	ops := new(types.TransactOpts)
 	if nonce != -1 {
 		ops.Nonce = &nonce
 	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
 
-	tx, err := txClient.GetCreatePublicPoolTransaction(txInfo, ops)
+	tx, err := c.GetUpdateMarginTransaction(txInfo, ops)
 	if err != nil {
 		return
 	}
@@ -697,14 +4115,15 @@ func SignCreatePublicPool(cOperatorFee C.longlong, cInitialTotalShares C.longlon
 	return
 }
 
-//export SignUpdatePublicPool
-func SignUpdatePublicPool(cPublicPoolIndex C.longlong, cStatus C.int, cOperatorFee C.longlong, cMinOperatorShareRate C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export SendCreateOrder
+func SendCreateOrder(cMarketIndex C.int, cClientOrderIndex C.longlong, cBaseAmount C.longlong, cPrice C.int, cIsAsk C.int, cOrderType C.int, cTimeInForce C.int, cReduceOnly C.int, cTriggerPrice C.int, cOrderExpiry C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int, cPriceProtection C.int) (ret C.StrOrErr) {
+	defer traceCall("SendCreateOrder", cMarketIndex, cClientOrderIndex, cBaseAmount, cPrice, cIsAsk, cOrderType, cTimeInForce, cReduceOnly, cTriggerPrice, cOrderExpiry, cExpiredAt, cNonce, cApiKeyIndex, cPriceProtection)()
 	var err error
-	var txInfoStr string
+	var txHash string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -712,55 +4131,65 @@ func SignUpdatePublicPool(cPublicPoolIndex C.longlong, cStatus C.int, cOperatorF
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(txHash),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	sendOpts, err := sendOptsOf(int64(cPriceProtection))
+	if err != nil {
+		return
+	}
+	err = checkOrderArgs(int64(cIsAsk), int64(cOrderType), int64(cTimeInForce), int64(cReduceOnly))
+	if err != nil {
 		return
 	}
 
-	publicPoolIndex := int64(cPublicPoolIndex)
-	status := uint8(cStatus)
-	operatorFee := int64(cOperatorFee)
-	minOperatorShareRate := int64(cMinOperatorShareRate)
+	txInfo := &types.CreateOrderTxReq{
+		MarketIndex:      uint8(cMarketIndex),
+		ClientOrderIndex: int64(cClientOrderIndex),
+		BaseAmount:       int64(cBaseAmount),
+		Price:            uint32(cPrice),
+		IsAsk:            uint8(cIsAsk),
+		Type:             uint8(cOrderType),
+		TimeInForce:      uint8(cTimeInForce),
+		ReduceOnly:       uint8(cReduceOnly),
+		TriggerPrice:     uint32(cTriggerPrice),
+		OrderExpiry:      int64(cOrderExpiry),
+	}
 	nonce := int64(cNonce)
 
-	txInfo := &types.UpdatePublicPoolTxReq{
-		PublicPoolIndex:      publicPoolIndex,
-		Status:               status,
-		OperatorFee:          operatorFee,
-		MinOperatorShareRate: minOperatorShareRate,
-	}
 	ops := new(types.TransactOpts)
 	if nonce != -1 {
 		ops.Nonce = &nonce
 	}
-
-	tx, err := txClient.GetUpdatePublicPoolTransaction(txInfo, ops)
-	if err != nil {
-		return
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	tx, err := c.GetCreateOrderTransaction(txInfo, ops)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	txHash, err = c.SendTxWithOpts(context.Background(), tx, sendOpts)
 	return
 }
 
-//export SignMintShares
-func SignMintShares(cPublicPoolIndex C.longlong, cShareAmount C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export SendCancelOrder
+func SendCancelOrder(cMarketIndex C.int, cOrderIndex C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int, cPriceProtection C.int) (ret C.StrOrErr) {
+	defer traceCall("SendCancelOrder", cMarketIndex, cOrderIndex, cExpiredAt, cNonce, cApiKeyIndex, cPriceProtection)()
 	var err error
-	var txInfoStr string
+	var txHash string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -768,51 +4197,53 @@ func SignMintShares(cPublicPoolIndex C.longlong, cShareAmount C.longlong, cNonce
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(txHash),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	sendOpts, err := sendOptsOf(int64(cPriceProtection))
+	if err != nil {
 		return
 	}
 
-	publicPoolIndex := int64(cPublicPoolIndex)
-	shareAmount := int64(cShareAmount)
+	txInfo := &types.CancelOrderTxReq{
+		MarketIndex: uint8(cMarketIndex),
+		Index:       int64(cOrderIndex),
+	}
 	nonce := int64(cNonce)
 
-	txInfo := &types.MintSharesTxReq{
-		PublicPoolIndex: publicPoolIndex,
-		ShareAmount:     shareAmount,
-	}
 	ops := new(types.TransactOpts)
 	if nonce != -1 {
 		ops.Nonce = &nonce
 	}
-
-	tx, err := txClient.GetMintSharesTransaction(txInfo, ops)
-	if err != nil {
-		return
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	tx, err := c.GetCancelOrderTransaction(txInfo, ops)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	txHash, err = c.SendTxWithOpts(context.Background(), tx, sendOpts)
 	return
 }
 
-//export SignBurnShares
-func SignBurnShares(cPublicPoolIndex C.longlong, cShareAmount C.longlong, cNonce C.longlong) (ret C.StrOrErr) {
+//export SendCancelAllOrders
+func SendCancelAllOrders(cTimeInForce C.int, cTime C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int, cPriceProtection C.int) (ret C.StrOrErr) {
+	defer traceCall("SendCancelAllOrders", cTimeInForce, cTime, cExpiredAt, cNonce, cApiKeyIndex, cPriceProtection)()
 	var err error
-	var txInfoStr string
+	var txHash string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -820,51 +4251,53 @@ func SignBurnShares(cPublicPoolIndex C.longlong, cShareAmount C.longlong, cNonce
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(txHash),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	sendOpts, err := sendOptsOf(int64(cPriceProtection))
+	if err != nil {
 		return
 	}
 
-	publicPoolIndex := int64(cPublicPoolIndex)
-	shareAmount := int64(cShareAmount)
+	txInfo := &types.CancelAllOrdersTxReq{
+		TimeInForce: uint8(cTimeInForce),
+		Time:        int64(cTime),
+	}
 	nonce := int64(cNonce)
 
-	txInfo := &types.BurnSharesTxReq{
-		PublicPoolIndex: publicPoolIndex,
-		ShareAmount:     shareAmount,
-	}
 	ops := new(types.TransactOpts)
 	if nonce != -1 {
 		ops.Nonce = &nonce
 	}
-
-	tx, err := txClient.GetBurnSharesTransaction(txInfo, ops)
-	if err != nil {
-		return
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	tx, err := c.GetCancelAllOrdersTransaction(txInfo, ops)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	txHash, err = c.SendTxWithOpts(context.Background(), tx, sendOpts)
 	return
 }
 
-//export SignUpdateLeverage
-func SignUpdateLeverage(cMarketIndex C.int, cInitialMarginFraction C.int, cMarginMode C.int, cNonce C.longlong) (ret C.StrOrErr) {
+//export SendModifyOrder
+func SendModifyOrder(cMarketIndex C.int, cIndex C.longlong, cBaseAmount C.longlong, cPrice C.longlong, cTriggerPrice C.longlong, cOrderType C.int, cOrderExpiry C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int, cPriceProtection C.int) (ret C.StrOrErr) {
+	defer traceCall("SendModifyOrder", cMarketIndex, cIndex, cBaseAmount, cPrice, cTriggerPrice, cOrderType, cOrderExpiry, cExpiredAt, cNonce, cApiKeyIndex, cPriceProtection)()
 	var err error
-	var txInfoStr string
+	var txHash string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -872,53 +4305,53 @@ func SignUpdateLeverage(cMarketIndex C.int, cInitialMarginFraction C.int, cMargi
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(txHash),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
+		return
+	}
+	sendOpts, err := sendOptsOf(int64(cPriceProtection))
+	if err != nil {
 		return
 	}
 
-	marketIndex := uint8(cMarketIndex)
-	initialMarginFraction := uint16(cInitialMarginFraction)
+	txInfo, err := modifyOrderReq(uint8(cMarketIndex), int64(cIndex), int64(cBaseAmount), uint32(cPrice), uint32(cTriggerPrice), int(cOrderType), int64(cOrderExpiry))
+	if err != nil {
+		return
+	}
 	nonce := int64(cNonce)
-	marginMode := uint8(cMarginMode)
 
-	txInfo := &types.UpdateLeverageTxReq{
-		MarketIndex:           marketIndex,
-		InitialMarginFraction: initialMarginFraction,
-		MarginMode:            uint8(marginMode),
-	}
 	ops := new(types.TransactOpts)
 	if nonce != -1 {
 		ops.Nonce = &nonce
 	}
-
-	tx, err := txClient.GetUpdateLeverageTransaction(txInfo, ops)
-	if err != nil {
-		return
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
 	}
 
-	txInfoBytes, err := json.Marshal(tx)
+	tx, err := c.GetModifyOrderTransaction(txInfo, ops)
 	if err != nil {
 		return
 	}
 
-	txInfoStr = string(txInfoBytes)
+	txHash, err = c.SendTxWithOpts(context.Background(), tx, sendOpts)
 	return
 }
 
-//export CreateAuthToken
-func CreateAuthToken(cDeadline C.longlong) (ret C.StrOrErr) {
+//export SendWithdraw
+func SendWithdraw(cUSDCAmount C.longlong, cExpiredAt C.longlong, cNonce C.longlong, cApiKeyIndex C.int, cPriceProtection C.int) (ret C.StrOrErr) {
+	defer traceCall("SendWithdraw", cUSDCAmount, cExpiredAt, cNonce, cApiKeyIndex, cPriceProtection)()
 	var err error
-	var authToken string
+	var txHash string
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -926,56 +4359,52 @@ func CreateAuthToken(cDeadline C.longlong) (ret C.StrOrErr) {
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(authToken),
+				str: C.CString(txHash),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("client is not created, call CreateClient() first")
+	c := resolveClient(int(cApiKeyIndex), -1)
+	if c == nil {
+		err = errNoClient()
 		return
 	}
-
-	deadline := int64(cDeadline)
-	if deadline == 0 {
-		deadline = time.Now().Add(time.Hour * 7).Unix()
-	}
-
-	authToken, err = txClient.GetAuthToken(time.Unix(deadline, 0))
+	sendOpts, err := sendOptsOf(int64(cPriceProtection))
 	if err != nil {
 		return
 	}
 
-	return
-}
+	txInfo := &types.WithdrawTxReq{
+		USDCAmount: uint64(cUSDCAmount),
+	}
+	nonce := int64(cNonce)
 
-//export SwitchAPIKey
-func SwitchAPIKey(c C.int) (ret *C.char) {
-	var err error
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
-		}
-		if err != nil {
-			ret = wrapErr(err)
-		}
-	}()
+	ops := new(types.TransactOpts)
+	if nonce != -1 {
+		ops.Nonce = &nonce
+	}
+	if cExpiredAt != -1 {
+		ops.ExpiredAt = int64(cExpiredAt)
+	}
 
-	txClient = backupTxClients[uint8(c)]
-	if txClient == nil {
-		err = fmt.Errorf("no client initialized for api key")
+	tx, err := c.GetWithdrawTransaction(txInfo, ops)
+	if err != nil {
+		return
 	}
 
+	txHash, err = c.SendTxWithOpts(context.Background(), tx, sendOpts)
 	return
 }
 
-//export SignUpdateMargin
-func SignUpdateMargin(cMarketIndex C.int, cUSDCAmount C.longlong, cDirection C.int, cNonce C.longlong) (ret C.StrOrErr) {
+//export SendTxBatch
+func SendTxBatch(cTxTypes *C.char, cTxInfos *C.char) (ret C.StrOrErr) {
+	defer traceCall("SendTxBatch", C.GoString(cTxTypes), redacted)()
 	var err error
-	var txInfoStr string
+	var resultsStr string
+
 	defer func() {
 		if r := recover(); r != nil {
-			wrapErr(fmt.Errorf("panic: %v", r))
+			err = panicErr(r)
 		}
 		if err != nil {
 			ret = C.StrOrErr{
@@ -983,36 +4412,54 @@ func SignUpdateMargin(cMarketIndex C.int, cUSDCAmount C.longlong, cDirection C.i
 			}
 		} else {
 			ret = C.StrOrErr{
-				str: C.CString(txInfoStr),
+				str: C.CString(resultsStr),
 			}
 		}
 	}()
 
-	if txClient == nil {
-		err = fmt.Errorf("Client is not created, call CreateClient() first")
+	c := activeClient()
+	if c == nil || c.HTTP() == nil {
+		err = errNoClient()
+		return
 	}
 
-	marketIndex := uint8(cMarketIndex)
-	usdcAmount := int64(cUSDCAmount)
-	direction := uint8(cDirection)
-	nonce := int64(cNonce)
-
-	txInfo := &types.UpdateMarginTxReq{
-		MarketIndex: marketIndex,
-		USDCAmount:  usdcAmount,
-		Direction:   direction,
+	var txTypes []uint8
+	if err = json.Unmarshal([]byte(C.GoString(cTxTypes)), &txTypes); err != nil {
+		err = fmt.Errorf("tx types should be a JSON array of numbers. err: %v", err)
+		return
 	}
-	ops := new(types.TransactOpts)
-	if nonce != -1 {
-		ops.Nonce = &nonce
+	var txInfos []string
+	if err = json.Unmarshal([]byte(C.GoString(cTxInfos)), &txInfos); err != nil {
+		err = fmt.Errorf("tx infos should be a JSON array of signed tx JSON strings. err: %v", err)
+		return
 	}
 
-	tx, err := txClient.GetUpdateMarginTransaction(txInfo, ops)
+	// a failed batch is reported through the per-tx errors, so callers can tell it apart from bad input
+	results, sendErr := c.HTTP().SendEncodedTxBatch(txTypes, txInfos)
+	if results == nil {
+		err = sendErr
+		return
+	}
 
-	txInfoBytes, err := json.Marshal(tx)
-	txInfoStr = string(txInfoBytes)
+	type batchResult struct {
+		TxHash string `json:"tx_hash,omitempty"`
+		Error  string `json:"error,omitempty"`
+	}
+	out := make([]batchResult, len(results))
+	for i, result := range results {
+		out[i].TxHash = result.TxHash
+		if result.Err != nil {
+			out[i].Error = result.Err.Error()
+		}
+	}
+
+	resultsBytes, err := json.Marshal(out)
+	if err != nil {
+		return
+	}
 
-	return ret
+	resultsStr = string(resultsBytes)
+	return
 }
 
 func main() {}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/client/clienttest"
+	"github.com/elliottech/lighter-go/keys"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testChainId = 304
+
+var (
+	testOrderExpiry = time.Now().Add(time.Hour).UnixMilli()
+	testExpiredAt   = time.Now().Add(time.Minute).UnixMilli()
+)
+
+func testOrder(t *testing.T, c *client.TxClient) *txtypes.L2CreateOrderTxInfo {
+	t.Helper()
+	tx, err := signTestOrder(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// signTestOrder signs a fixed order like SignCreateOrder does, for tests running it off the test goroutine.
+func signTestOrder(c *client.TxClient) (*txtypes.L2CreateOrderTxInfo, error) {
+	nonce := int64(12)
+	return c.GetCreateOrderTransaction(&types.CreateOrderTxReq{
+		MarketIndex:      1,
+		ClientOrderIndex: 7,
+		BaseAmount:       1000,
+		Price:            250000,
+		TimeInForce:      txtypes.GoodTillTime,
+		OrderExpiry:      testOrderExpiry,
+	}, &types.TransactOpts{Nonce: &nonce, ExpiredAt: testExpiredAt})
+}
+
+func TestGeneratedKeyClientSignsLikeHexKeyClient(t *testing.T) {
+	t.Cleanup(DestroyAllClients)
+
+	privateKey, publicKey, err := generateAPIKey("generated key test seed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyManager, ok := generatedKeys[publicKey]
+	if !ok {
+		t.Fatal("generated key wasn't remembered")
+	}
+
+	fromGenerated := client.NewTxClientWithKeyManager(nil, keyManager, 5, 3, testChainId)
+	fromHex, err := client.NewTxClient(nil, privateKey, 5, 3, testChainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromGenerated.GetKeyManager().PubKeyBytes() != fromHex.GetKeyManager().PubKeyBytes() {
+		t.Fatal("public keys differ")
+	}
+
+	// Schnorr signatures are randomized: compare the signed txs without them, and check each signature
+	// against the key of the other client.
+	a, b := testOrder(t, fromGenerated), testOrder(t, fromHex)
+	if a.SignedHash != b.SignedHash {
+		t.Fatal("the clients signed different txs")
+	}
+	hash, err := a.Hash(testChainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkA, pkB := fromGenerated.GetKeyManager().PubKeyBytes(), fromHex.GetKeyManager().PubKeyBytes()
+	if err := schnorr.Validate(pkB[:], hash, a.Sig); err != nil {
+		t.Fatalf("signature of the generated key client doesn't verify: %v", err)
+	}
+	if err := schnorr.Validate(pkA[:], hash, b.Sig); err != nil {
+		t.Fatalf("signature of the hex key client doesn't verify: %v", err)
+	}
+}
+
+func TestDestroyClearsGeneratedKeys(t *testing.T) {
+	t.Cleanup(DestroyAllClients)
+
+	_, kept, err := generateAPIKey("kept key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, used, err := generateAPIKey("used key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewTxClientWithKeyManager(nil, generatedKeys[used], 5, 3, testChainId)
+	activateClient(c)
+
+	stateMu.Lock()
+	forgetGeneratedKeyOf(c)
+	stateMu.Unlock()
+	if _, ok := generatedKeys[used]; ok {
+		t.Fatal("the key of the destroyed client is still registered")
+	}
+	if _, ok := generatedKeys[kept]; !ok {
+		t.Fatal("an unrelated generated key was dropped")
+	}
+
+	DestroyAllClients()
+	if len(generatedKeys) != 0 {
+		t.Fatalf("%v generated keys left after DestroyAllClients", len(generatedKeys))
+	}
+}
+
+func TestDeriveApiKeysRange(t *testing.T) {
+	t.Cleanup(DestroyAllClients)
+	masterSeed := strings.Repeat("ab", 32)
+
+	for _, tc := range []struct {
+		fromIndex, count int
+		ok               bool
+	}{
+		{0, 1, true},
+		{250, 5, true}, // up to MaxApiKeyIndex
+		{250, 6, false},
+		{int(txtypes.MaxApiKeyIndex), 1, true},
+		{int(txtypes.MaxApiKeyIndex) + 1, 1, false},
+		{0, 0, false},
+		{-1, 2, false},
+	} {
+		keysStr, err := deriveApiKeys(masterSeed, 1, tc.fromIndex, tc.count)
+		if !tc.ok {
+			if err == nil {
+				t.Errorf("deriveApiKeys(%v, %v) succeeded", tc.fromIndex, tc.count)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("deriveApiKeys(%v, %v): %v", tc.fromIndex, tc.count, err)
+		}
+		var derived []derivedKey
+		if err := json.Unmarshal([]byte(keysStr), &derived); err != nil {
+			t.Fatal(err)
+		}
+		if len(derived) != tc.count || derived[0].ApiKeyIndex != tc.fromIndex {
+			t.Fatalf("deriveApiKeys(%v, %v) returned %+v", tc.fromIndex, tc.count, derived)
+		}
+		for _, key := range derived {
+			keyManager, err := keys.ParsePrivateKey(key.PrivateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := keys.FromKeyManager(keyManager).Hex(); got != key.PublicKey {
+				t.Fatalf("api key %v: public key %v doesn't match its private key (%v)", key.ApiKeyIndex, key.PublicKey, got)
+			}
+			if _, ok := generatedKeys[key.PublicKey]; !ok {
+				t.Fatalf("api key %v wasn't remembered", key.ApiKeyIndex)
+			}
+		}
+	}
+}
+
+func TestSendOptsOf(t *testing.T) {
+	opts, err := sendOptsOf(-1)
+	if err != nil || opts.PriceProtection != nil {
+		t.Fatalf("-1: %+v, %v", opts, err)
+	}
+	for _, enabled := range []bool{false, true} {
+		arg := int64(0)
+		if enabled {
+			arg = 1
+		}
+		opts, err := sendOptsOf(arg)
+		if err != nil || opts.PriceProtection == nil || *opts.PriceProtection != enabled {
+			t.Fatalf("%v: %+v, %v", arg, opts, err)
+		}
+	}
+	if _, err := sendOptsOf(2); err == nil {
+		t.Fatal("2 accepted")
+	}
+}
+
+func TestModifyOrderReq(t *testing.T) {
+	keyManager, err := keys.DeriveApiKey(make([]byte, keys.MinMasterSeedLength), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewTxClientWithKeyManager(nil, keyManager, 1, 0, testChainId)
+	sign := func(req *types.ModifyOrderTxReq) error {
+		nonce := int64(1)
+		_, err := c.GetModifyOrderTransaction(req, &types.TransactOpts{Nonce: &nonce, ExpiredAt: testExpiredAt})
+		return err
+	}
+
+	if _, err := modifyOrderReq(1, 7, 1000, 250000, 0, -1, testOrderExpiry); err == nil {
+		t.Fatal("orderExpiry accepted")
+	}
+	for _, tc := range []struct {
+		orderType    int
+		triggerPrice uint32
+		ok           bool
+	}{
+		{-1, 240000, true},
+		{int(txtypes.LimitOrder), 0, true},
+		{int(txtypes.LimitOrder), 240000, false},
+		{int(txtypes.StopLossOrder), 0, false},
+		{int(txtypes.StopLossOrder), 240000, true},
+	} {
+		req, err := modifyOrderReq(1, 7, 1000, 250000, tc.triggerPrice, tc.orderType, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sign(req); (err == nil) != tc.ok || (err != nil && !errors.Is(err, txtypes.ErrOrderTriggerPriceInvalid)) {
+			t.Errorf("order type %v, trigger price %v: got %v", tc.orderType, tc.triggerPrice, err)
+		}
+	}
+}
+
+func TestFinalizeChangePubKey(t *testing.T) {
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ownerAddress := crypto.PubkeyToAddress(owner.PublicKey).Hex()
+	requester := &clienttest.FakeRequester{AccountIndexesFunc: func(l1Address string) ([]int64, error) {
+		if l1Address == ownerAddress {
+			return []int64{1}, nil
+		}
+		return nil, nil
+	}}
+	keyManager, err := keys.DeriveApiKey(make([]byte, keys.MinMasterSeedLength), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewTxClientWithKeyManager(requester, keyManager, 1, 2, testChainId)
+
+	nonce := int64(5)
+	tx, err := c.GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: keyManager.PubKeyBytes()}, &types.TransactOpts{Nonce: &nonce, ExpiredAt: testExpiredAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// signL1 signs the message of the SignChangePubKey output, personal_sign style
+	signL1 := func(txInfo string, key *ecdsa.PrivateKey, v byte) string {
+		var toSign struct{ MessageToSign string }
+		if err := json.Unmarshal([]byte(txInfo), &toSign); err != nil {
+			t.Fatal(err)
+		}
+		sig, err := crypto.Sign(accounts.TextHash([]byte(toSign.MessageToSign)), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig[crypto.RecoveryIDOffset] += v
+		return hexutil.Encode(sig)
+	}
+
+	for _, typedData := range []bool{false, true} {
+		if typedData {
+			txtypes.EnableL1TypedData(testChainId, txtypes.TypedDataDomain{Name: "Lighter", Version: "1", ChainId: 1, VerifyingContract: "0x0000000000000000000000000000000000000001"})
+		}
+		txInfo, err := changePubKeyToSign(c, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(txInfo, "TypedDataToSign"); got != typedData {
+			t.Fatalf("typed data %v: TypedDataToSign in the output %v", typedData, got)
+		}
+
+		for _, v := range []byte{0, 27} {
+			l1Sig := signL1(txInfo, owner, v)
+			finalized, err := finalizeChangePubKey(c, txInfo, l1Sig)
+			if err != nil {
+				t.Fatalf("typed data %v, v+%v: %v", typedData, v, err)
+			}
+			parsed, err := txtypes.ParseTxInfo(txtypes.TxTypeL2ChangePubKey, []byte(finalized))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parsed.(*txtypes.L2ChangePubKeyTxInfo)
+			if got.L1Sig != l1Sig || txtypes.SigHex(got) != txtypes.SigHex(tx) {
+				t.Fatalf("finalized tx %+v doesn't carry the L1 signature and the original signature", got)
+			}
+		}
+	}
+	txtypes.DisableL1TypedData(testChainId)
+
+	txInfo, err := changePubKeyToSign(c, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := signL1(txInfo, owner, 27)
+	badV := []byte(valid)
+	copy(badV[len(badV)-2:], "05")
+	for name, l1Sig := range map[string]string{
+		"wrong signer": signL1(txInfo, stranger, 27),
+		"empty":        "",
+		"not hex":      "0xzz",
+		"short":        valid[:len(valid)-2],
+		"long":         valid + "00",
+		"recovery id":  string(badV),
+	} {
+		if _, err := finalizeChangePubKey(c, txInfo, l1Sig); !errors.Is(err, client.ErrL1SignatureRejected) {
+			t.Errorf("%v: got %v, want ErrL1SignatureRejected", name, err)
+		}
+	}
+
+	other := client.NewTxClientWithKeyManager(requester, keyManager, 2, 2, testChainId)
+	if _, err := finalizeChangePubKey(other, txInfo, valid); err == nil {
+		t.Error("tx of another account finalized")
+	}
+}
+
+func TestSwitchAndSignInterleaved(t *testing.T) {
+	t.Cleanup(DestroyAllClients)
+
+	// every (account, API key) pair has its own key
+	pubKeys := make(map[clientKey][40]byte)
+	var pairs []clientKey
+	for _, accountIndex := range []int64{1, 2} {
+		for apiKeyIndex := uint8(0); apiKeyIndex < 3; apiKeyIndex++ {
+			keyManager, err := keys.DeriveApiKey(make([]byte, keys.MinMasterSeedLength), accountIndex, apiKeyIndex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := clientKey{accountIndex, apiKeyIndex}
+			pubKeys[key] = keyManager.PubKeyBytes()
+			pairs = append(pairs, key)
+			activateClient(client.NewTxClientWithKeyManager(nil, keyManager, accountIndex, apiKeyIndex, testChainId))
+		}
+	}
+
+	// check verifies tx was signed by the key of the pair it carries, and for the expected pair.
+	check := func(tx *txtypes.L2CreateOrderTxInfo, want clientKey) error {
+		got := clientKey{tx.AccountIndex, tx.ApiKeyIndex}
+		if got != want {
+			return fmt.Errorf("signed for %+v, want %+v", got, want)
+		}
+		hash, err := tx.Hash(testChainId)
+		if err != nil {
+			return err
+		}
+		pk := pubKeys[got]
+		if err := schnorr.Validate(pk[:], hash, tx.Sig); err != nil {
+			return fmt.Errorf("tx of %+v not signed with its key: %v", got, err)
+		}
+		return nil
+	}
+
+	const signs = 20
+	var signers sync.WaitGroup
+	sign := func(f func(i int) error) {
+		signers.Add(1)
+		go func() {
+			defer signers.Done()
+			for i := 0; i < signs; i++ {
+				if err := f(i); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for w := 0; w < 4; w++ {
+		// the active client, like the Sign* exports given apiKeyIndex -1
+		sign(func(int) error {
+			c := resolveClient(-1, -1)
+			tx, err := signTestOrder(c)
+			if err != nil {
+				return err
+			}
+			return check(tx, clientKey{c.GetAccountIndex(), c.GetApiKeyIndex()})
+		})
+	}
+	for w := 0; w < 2; w++ {
+		// an explicit API key on the active account
+		sign(func(i int) error {
+			apiKeyIndex := uint8(i % 3)
+			c := resolveClient(int(apiKeyIndex), -1)
+			tx, err := signTestOrder(c)
+			if err != nil {
+				return err
+			}
+			if tx.ApiKeyIndex != apiKeyIndex {
+				return fmt.Errorf("asked for api key %v, signed with %v", apiKeyIndex, tx.ApiKeyIndex)
+			}
+			return check(tx, clientKey{c.GetAccountIndex(), apiKeyIndex})
+		})
+	}
+
+	// switch the active client until every signer is done
+	done := make(chan struct{})
+	var switchers sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		switchers.Add(1)
+		go func() {
+			defer switchers.Done()
+			for i := w; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				pair := pairs[i%len(pairs)]
+				if err := switchClient(pair.accountIndex, pair.apiKeyIndex); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	signers.Wait()
+	close(done)
+	switchers.Wait()
+}
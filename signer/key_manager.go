@@ -52,3 +52,13 @@ func (key *keyManager) PubKeyBytes() (res [40]byte) {
 func (key *keyManager) PrvKeyBytes() []byte {
 	return key.key.ToLittleEndianBytes()
 }
+
+// Wiper is implemented by key managers which can erase their private key from memory.
+type Wiper interface {
+	Wipe()
+}
+
+// Wipe zeroes the private key. The key manager can't be used to sign afterwards.
+func (key *keyManager) Wipe() {
+	key.key = curve.ECgFp5Scalar{}
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MemoLength is the size of a transfer memo in bytes.
+const MemoLength = 32
+
+// ParseMemo turns a memo string into the 32 bytes of a transfer memo. It accepts, in order:
+//   - exactly 32 bytes, used as is
+//   - 64 hex chars, with or without a 0x prefix, decoded
+//   - any other UTF-8 string up to 32 bytes, right-padded with zero bytes
+//
+// A longer string is rejected, unless hashLong is set: the memo is then the keccak256 hash of the string.
+func ParseMemo(memo string, hashLong bool) ([MemoLength]byte, error) {
+	res := [MemoLength]byte{}
+
+	if len(memo) == MemoLength {
+		copy(res[:], memo)
+		return res, nil
+	}
+
+	hexMemo := strings.TrimPrefix(memo, "0x")
+	if len(hexMemo) == 2*MemoLength {
+		if b, err := hex.DecodeString(hexMemo); err == nil {
+			copy(res[:], b)
+			return res, nil
+		}
+	}
+
+	if len(memo) > MemoLength {
+		if !hashLong {
+			return res, fmt.Errorf("memo is %v bytes long, expected at most %v bytes or %v hex chars", len(memo), MemoLength, 2*MemoLength)
+		}
+		copy(res[:], crypto.Keccak256([]byte(memo)))
+		return res, nil
+	}
+
+	copy(res[:], memo)
+	return res, nil
+}
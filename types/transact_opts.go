@@ -0,0 +1,107 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// OptsBuilder builds TransactOpts, validating them once in Build. Unset fields keep their TransactOpts meaning:
+// no nonce means it's fetched, no expiry means the default one, no account or API key means the client's.
+type OptsBuilder struct {
+	opts     TransactOpts
+	expireIn time.Duration
+	err      error
+}
+
+func NewOpts() *OptsBuilder {
+	return &OptsBuilder{}
+}
+
+func (b *OptsBuilder) WithAccount(accountIndex int64) *OptsBuilder {
+	b.opts.FromAccountIndex = &accountIndex
+	return b
+}
+
+func (b *OptsBuilder) WithApiKey(apiKeyIndex uint8) *OptsBuilder {
+	b.opts.ApiKeyIndex = &apiKeyIndex
+	return b
+}
+
+func (b *OptsBuilder) WithNonce(nonce int64) *OptsBuilder {
+	b.opts.Nonce = &nonce
+	return b
+}
+
+// WithExpiredAt sets the expiry of the tx, in milliseconds. It can't be combined with WithExpireIn.
+func (b *OptsBuilder) WithExpiredAt(expiredAt int64) *OptsBuilder {
+	if b.expireIn != 0 {
+		b.setErr(fmt.Errorf("ExpiredAt and ExpireIn are mutually exclusive"))
+	}
+	b.opts.ExpiredAt = expiredAt
+	return b
+}
+
+// WithExpireIn makes the tx expire d after Build. It can't be combined with WithExpiredAt.
+func (b *OptsBuilder) WithExpireIn(d time.Duration) *OptsBuilder {
+	if b.opts.ExpiredAt != 0 {
+		b.setErr(fmt.Errorf("ExpiredAt and ExpireIn are mutually exclusive"))
+	}
+	if d <= 0 {
+		b.setErr(fmt.Errorf("ExpireIn should be positive"))
+	}
+	b.expireIn = d
+	return b
+}
+
+// DryRun makes the tx sign-only, see TransactOpts.DryRun.
+func (b *OptsBuilder) DryRun() *OptsBuilder {
+	b.opts.DryRun = true
+	return b
+}
+
+func (b *OptsBuilder) Build() (*TransactOpts, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	opts := b.opts
+	if b.expireIn != 0 {
+		opts.ExpiredAt = time.Now().Add(b.expireIn).UnixMilli()
+	}
+	if err := ValidateOpts(&opts); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+func (b *OptsBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// ValidateOpts checks the fields set in ops against the protocol ranges. Unset fields are valid.
+func ValidateOpts(ops *TransactOpts) error {
+	if ops == nil {
+		return nil
+	}
+	if ops.FromAccountIndex != nil {
+		if *ops.FromAccountIndex < txtypes.MinAccountIndex {
+			return txtypes.ErrFromAccountIndexTooLow
+		}
+		if *ops.FromAccountIndex > txtypes.MaxAccountIndex {
+			return txtypes.ErrFromAccountIndexTooHigh
+		}
+	}
+	if ops.ApiKeyIndex != nil && *ops.ApiKeyIndex > txtypes.MaxApiKeyIndex {
+		return txtypes.ErrApiKeyIndexTooHigh
+	}
+	if ops.Nonce != nil && *ops.Nonce < txtypes.MinNonce {
+		return txtypes.ErrNonceTooLow
+	}
+	if ops.ExpiredAt < 0 || ops.ExpiredAt > txtypes.MaxTimestamp {
+		return txtypes.ErrExpiredAtInvalid
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+func TestOptsBuilderRejectsConflicts(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		build func() *OptsBuilder
+		want  error // nil for any error
+	}{
+		{"ExpiredAt then ExpireIn", func() *OptsBuilder {
+			return NewOpts().WithExpiredAt(time.Now().Add(time.Minute).UnixMilli()).WithExpireIn(time.Minute)
+		}, nil},
+		{"ExpireIn then ExpiredAt", func() *OptsBuilder {
+			return NewOpts().WithExpireIn(time.Minute).WithExpiredAt(time.Now().Add(time.Minute).UnixMilli())
+		}, nil},
+		{"zero ExpireIn", func() *OptsBuilder { return NewOpts().WithExpireIn(0) }, nil},
+		{"negative ExpireIn", func() *OptsBuilder { return NewOpts().WithExpireIn(-time.Second) }, nil},
+		{"negative ExpiredAt", func() *OptsBuilder { return NewOpts().WithExpiredAt(-1) }, txtypes.ErrExpiredAtInvalid},
+		{"ExpiredAt too high", func() *OptsBuilder { return NewOpts().WithExpiredAt(txtypes.MaxTimestamp + 1) }, txtypes.ErrExpiredAtInvalid},
+		{"negative account", func() *OptsBuilder { return NewOpts().WithAccount(-1) }, txtypes.ErrFromAccountIndexTooLow},
+		{"account too high", func() *OptsBuilder { return NewOpts().WithAccount(txtypes.MaxAccountIndex + 1) }, txtypes.ErrFromAccountIndexTooHigh},
+		{"api key too high", func() *OptsBuilder { return NewOpts().WithApiKey(txtypes.MaxApiKeyIndex + 1) }, txtypes.ErrApiKeyIndexTooHigh},
+		{"negative nonce", func() *OptsBuilder { return NewOpts().WithNonce(-1) }, txtypes.ErrNonceTooLow},
+		{"first error kept", func() *OptsBuilder {
+			return NewOpts().WithExpireIn(0).DryRun().WithNonce(3)
+		}, nil},
+	} {
+		opts, err := tc.build().Build()
+		if err == nil {
+			t.Errorf("%v: built %+v", tc.name, opts)
+			continue
+		}
+		if tc.want != nil && !errors.Is(err, tc.want) {
+			t.Errorf("%v: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestOptsBuilder(t *testing.T) {
+	before := time.Now()
+	opts, err := NewOpts().WithAccount(7).WithApiKey(txtypes.MaxApiKeyIndex).WithNonce(0).WithExpireIn(time.Minute).DryRun().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *opts.FromAccountIndex != 7 || *opts.ApiKeyIndex != txtypes.MaxApiKeyIndex || *opts.Nonce != 0 || !opts.DryRun {
+		t.Fatalf("built %+v", opts)
+	}
+	if expiredAt := time.UnixMilli(opts.ExpiredAt); expiredAt.Before(before.Add(time.Minute).Truncate(time.Millisecond)) || expiredAt.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("ExpiredAt %v isn't a minute after Build", expiredAt)
+	}
+
+	opts, err = NewOpts().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.FromAccountIndex != nil || opts.ApiKeyIndex != nil || opts.Nonce != nil || opts.ExpiredAt != 0 || opts.DryRun {
+		t.Fatalf("empty builder built %+v", opts)
+	}
+}
+
+func TestValidateOptsAcceptsUnset(t *testing.T) {
+	if err := ValidateOpts(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateOpts(&TransactOpts{}); err != nil {
+		t.Fatal(err)
+	}
+}
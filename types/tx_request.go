@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
 	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
 	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
 	ethCommon "github.com/ethereum/go-ethereum/common"
 )
 
@@ -17,7 +19,10 @@ type TransactOpts struct {
 	ApiKeyIndex      *uint8
 	ExpiredAt        int64
 	Nonce            *int64
-	DryRun           bool
+	// DryRun signs the tx without reserving a nonce of the client's NonceManager; TxClient.SendTx refuses to send it.
+	DryRun bool
+	// Ctx bounds the requests made while building the tx, i.e. fetching its nonce. Nil is context.Background().
+	Ctx context.Context
 }
 
 type PublicKey = gFp5.Element
@@ -61,6 +66,9 @@ type ModifyOrderTxReq struct {
 	BaseAmount   int64
 	Price        uint32
 	TriggerPrice uint32
+	// OrderType is the type of the modified order, when known. It isn't signed; it only lets the trigger price
+	// be checked against the order before signing.
+	OrderType *uint8
 }
 
 type CancelOrderTxReq struct {
@@ -117,20 +125,53 @@ func ConstructAuthToken(key signer.Signer, deadline time.Time, ops *TransactOpts
 	}
 	message := fmt.Sprintf("%v:%v:%v", deadline.Unix(), *ops.FromAccountIndex, *ops.ApiKeyIndex)
 
-	msgInField, err := g.ArrayFromCanonicalLittleEndianBytes([]byte(message))
+	signature, err := SignMessage(key, []byte(message))
 	if err != nil {
-		return "", fmt.Errorf("failed to convert bytes to field element. message: %s, error: %w", message, err)
+		return "", err
 	}
 
-	msgHash := p2.HashToQuinticExtension(msgInField).ToLittleEndianBytes()
+	return fmt.Sprintf("%v:%v", message, signature), nil
+}
 
+// hashMessage hashes an arbitrary message the way auth tokens are hashed before being signed.
+func hashMessage(message []byte) ([]byte, error) {
+	msgInField, err := g.ArrayFromCanonicalLittleEndianBytes(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert bytes to field element. message: %s, error: %w", message, err)
+	}
+	return p2.HashToQuinticExtension(msgInField).ToLittleEndianBytes(), nil
+}
+
+// SignMessage signs an arbitrary message like auth tokens are signed, and returns the hex-encoded signature.
+func SignMessage(key signer.Signer, message []byte) (string, error) {
+	msgHash, err := hashMessage(message)
+	if err != nil {
+		return "", err
+	}
 	signatureBytes, err := key.Sign(msgHash, p2.NewPoseidon2())
 	if err != nil {
 		return "", err
 	}
-	signature := ethCommon.Bytes2Hex(signatureBytes)
+	return txtypes.EncodeSigHex(signatureBytes), nil
+}
 
-	return fmt.Sprintf("%v:%v", message, signature), err
+// VerifyMessage checks a signature made by SignMessage against pubKey.
+func VerifyMessage(message []byte, signature string, pubKey []byte) error {
+	sig, err := txtypes.ParseSigHex(signature)
+	if err != nil {
+		return err
+	}
+	if !txtypes.IsValidPubKey(pubKey) {
+		return txtypes.ErrPubKeyInvalid
+	}
+	msgHash, err := hashMessage(message)
+	if err != nil {
+		return err
+	}
+	if err := schnorr.Validate(pubKey, msgHash, sig); err != nil {
+		return fmt.Errorf("%w: %v", txtypes.ErrInvalidSignature, err)
+	}
+	return nil
 }
 
 func ConstructChangePubKeyTx(key signer.Signer, lighterChainId uint32, tx *ChangePubKeyReq, ops *TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
@@ -315,6 +356,11 @@ func ConstructL2ModifyOrderTx(key signer.Signer, lighterChainId uint32, tx *Modi
 	if err != nil {
 		return nil, err
 	}
+	if tx.OrderType != nil {
+		if err := convertedTx.ValidateForOrderType(*tx.OrderType); err != nil {
+			return nil, err
+		}
+	}
 
 	msgHash, err := convertedTx.Hash(lighterChainId)
 	if err != nil {
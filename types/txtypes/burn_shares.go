@@ -32,6 +32,14 @@ func (txInfo *L2BurnSharesTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2BurnSharesTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2BurnSharesTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2BurnSharesTxInfo) Validate() error {
 	if txInfo.AccountIndex < MinAccountIndex {
 		return ErrFromAccountIndexTooLow
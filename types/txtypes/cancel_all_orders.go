@@ -32,6 +32,14 @@ func (txInfo *L2CancelAllOrdersTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CancelAllOrdersTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2CancelAllOrdersTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2CancelAllOrdersTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
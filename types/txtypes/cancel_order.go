@@ -32,6 +32,14 @@ func (txInfo *L2CancelOrderTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CancelOrderTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2CancelOrderTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2CancelOrderTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
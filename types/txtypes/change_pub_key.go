@@ -58,6 +58,14 @@ func (txInfo *L2ChangePubKeyTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2ChangePubKeyTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2ChangePubKeyTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2ChangePubKeyTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
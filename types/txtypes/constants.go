@@ -134,6 +134,11 @@ const (
 	MinOrderPrice uint32 = 1
 	MaxOrderPrice uint32 = (1 << 32) - 1
 
+	// Market orders have no zero-price sentinel: their Price is the worst price they may fill at.
+	// These are the prices of a market order accepting any fill.
+	MarketBuyNoLimitPrice  uint32 = MaxOrderPrice
+	MarketSellNoLimitPrice uint32 = MinOrderPrice
+
 	MinOrderCancelAllPeriod int64 = 1000 * 60 * 5            // 5 minutes
 	MaxOrderCancelAllPeriod int64 = 1000 * 60 * 60 * 24 * 15 // 15 days
 
@@ -34,6 +34,14 @@ func (txInfo *L2CreateGroupedOrdersTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CreateGroupedOrdersTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2CreateGroupedOrdersTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2CreateGroupedOrdersTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
@@ -86,11 +94,8 @@ func (txInfo *L2CreateGroupedOrdersTxInfo) Validate() error {
 		}
 
 		// Price
-		if order.Price < MinOrderPrice {
-			return ErrPriceTooLow
-		}
-		if order.Price > MaxOrderPrice {
-			return ErrPriceTooHigh
+		if err := validateOrderPrice(order.Type, order.Price); err != nil {
+			return err
 		}
 
 		// IsAsk
@@ -31,6 +31,14 @@ func (txInfo *L2CreateOrderTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CreateOrderTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2CreateOrderTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2CreateOrderTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
@@ -77,11 +85,8 @@ func (txInfo *L2CreateOrderTxInfo) Validate() error {
 	}
 
 	// Price
-	if txInfo.Price < MinOrderPrice {
-		return ErrPriceTooLow
-	}
-	if txInfo.Price > MaxOrderPrice {
-		return ErrPriceTooHigh
+	if err := validateOrderPrice(txInfo.Type, txInfo.Price); err != nil {
+		return err
 	}
 
 	// IsAsk
@@ -184,3 +189,21 @@ func (txInfo *L2CreateOrderTxInfo) Hash(lighterChainId uint32, extra ...g.Elemen
 
 	return p2.HashToQuinticExtension(elems).ToLittleEndianBytes(), nil
 }
+
+// validateOrderPrice checks Price against the rule of the order type. Every order type carries a price in
+// [MinOrderPrice, MaxOrderPrice]: the limit price, or the worst acceptable price for market and trigger orders.
+func validateOrderPrice(orderType uint8, price uint32) error {
+	if price >= MinOrderPrice && price <= MaxOrderPrice {
+		return nil
+	}
+	switch orderType {
+	case MarketOrder:
+		return ErrMarketOrderPriceTooLow
+	case StopLossOrder, TakeProfitOrder:
+		return ErrTriggerOrderPriceTooLow
+	case LimitOrder, StopLossLimitOrder, TakeProfitLimitOrder, TWAPOrder:
+		return ErrLimitOrderPriceTooLow
+	default:
+		return ErrPriceTooLow
+	}
+}
@@ -33,6 +33,14 @@ func (txInfo *L2CreatePublicPoolTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CreatePublicPoolTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2CreatePublicPoolTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2CreatePublicPoolTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
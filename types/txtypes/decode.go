@@ -0,0 +1,159 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DecodeTx describes tx for people reading a raw tx_info: camelCase field names, "buy"/"sell" instead of IsAsk,
+// enum names instead of numbers, RFC 3339 times instead of unix milliseconds and hex instead of base64 bytes.
+// The tx type name and its hash on the given chain are added as "txType" and "hash".
+func DecodeTx(tx TxInfo, lighterChainId uint32) (map[string]any, error) {
+	txInfoBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(txInfoBytes))
+	decoder.UseNumber()
+	fields := make(map[string]any)
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	constants := Constants()
+	timeInForces := constants.TimeInForces
+	if tx.GetTxType() == TxTypeL2CancelAllOrders {
+		timeInForces = constants.CancelAllTimeInForces
+	}
+	d := &txDecoder{
+		enums: map[string]map[string]int64{
+			"Type":         constants.OrderTypes,
+			"TimeInForce":  timeInForces,
+			"GroupingType": constants.GroupingTypes,
+			"MarginMode":   constants.MarginModes,
+			"Direction":    constants.MarginDirections,
+		},
+	}
+	decoded, err := d.object(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	msgHash, err := tx.Hash(lighterChainId)
+	if err != nil {
+		return nil, err
+	}
+	decoded["txType"] = enumName(constants.TxTypes, int64(tx.GetTxType()))
+	decoded["hash"] = hex.EncodeToString(msgHash)
+	return decoded, nil
+}
+
+type txDecoder struct {
+	// enums maps the enum fields to the names of their values
+	enums map[string]map[string]int64
+}
+
+func (d *txDecoder) object(fields map[string]any) (map[string]any, error) {
+	decoded := make(map[string]any, len(fields))
+	for key, value := range fields {
+		name, v, err := d.field(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", key, err)
+		}
+		decoded[name] = v
+	}
+	return decoded, nil
+}
+
+func (d *txDecoder) field(key string, value any) (string, any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		obj, err := d.object(v)
+		return camelCase(key), obj, err
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				items[i] = item
+				continue
+			}
+			decoded, err := d.object(obj)
+			if err != nil {
+				return "", nil, err
+			}
+			items[i] = decoded
+		}
+		return camelCase(key), items, nil
+	case string:
+		// []byte fields are marshalled as base64
+		if key == "PubKey" || key == "Sig" {
+			b, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return "", nil, err
+			}
+			return camelCase(key), "0x" + hex.EncodeToString(b), nil
+		}
+		return camelCase(key), v, nil
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return camelCase(key), v, nil
+		}
+		return d.number(key, n)
+	default:
+		return camelCase(key), value, nil
+	}
+}
+
+func (d *txDecoder) number(key string, n int64) (string, any, error) {
+	if key == "IsAsk" {
+		if n == 1 {
+			return "side", "sell", nil
+		}
+		return "side", "buy", nil
+	}
+	if names, ok := d.enums[key]; ok {
+		if key == "Type" {
+			return "orderType", enumName(names, n), nil
+		}
+		return camelCase(key), enumName(names, n), nil
+	}
+	switch key {
+	case "ExpiredAt", "OrderExpiry", "Time":
+		if n == NilOrderExpiry {
+			return camelCase(key), nil, nil
+		}
+		return camelCase(key), time.UnixMilli(n).UTC().Format(time.RFC3339Nano), nil
+	}
+	return camelCase(key), n, nil
+}
+
+func enumName(names map[string]int64, value int64) string {
+	for name, v := range names {
+		if v == value {
+			return name
+		}
+	}
+	return fmt.Sprintf("Unknown(%d)", value)
+}
+
+// camelCase lowercases the leading capitals of a Go field name: AccountIndex -> accountIndex, USDCAmount -> usdcAmount.
+func camelCase(name string) string {
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	// keep the capital starting the next word, as the M of USDCAmount
+	if i > 1 && i < len(runes) && unicode.IsLower(runes[i]) {
+		i--
+	}
+	return strings.ToLower(string(runes[:i])) + string(runes[i:])
+}
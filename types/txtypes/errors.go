@@ -52,6 +52,9 @@ var (
 	ErrBaseAmountNotNil                = fmt.Errorf("BaseAmount should be nil")
 	ErrPriceTooLow                     = fmt.Errorf("OrderPrice should not be less than %d", MinOrderPrice)
 	ErrPriceTooHigh                    = fmt.Errorf("OrderPrice should not be larger than %d", MaxOrderPrice)
+	ErrMarketOrderPriceTooLow          = fmt.Errorf("%w: market orders carry their worst acceptable price, use %d (sell) or %d (buy) for no bound", ErrPriceTooLow, MarketSellNoLimitPrice, MarketBuyNoLimitPrice)
+	ErrTriggerOrderPriceTooLow         = fmt.Errorf("%w: stop loss and take profit orders carry their worst acceptable execution price", ErrPriceTooLow)
+	ErrLimitOrderPriceTooLow           = fmt.Errorf("%w: limit and TWAP orders need their limit price", ErrPriceTooLow)
 	ErrIsAskInvalid                    = fmt.Errorf("IsAsk should be 0 or 1")
 	ErrOrderTypeInvalid                = fmt.Errorf("OrderType is not valid")
 	ErrOrderTimeInForceInvalid         = fmt.Errorf("OrderTimeInForce is not valid")
@@ -0,0 +1,110 @@
+package txtypes
+
+// ExportedConstants groups the protocol enums, sentinels and bounds for non-Go callers (e.g. the shared library).
+// It references the constants directly, so it can't drift from them.
+type ExportedConstants struct {
+	TxTypes               map[string]int64 `json:"tx_types"`
+	OrderTypes            map[string]int64 `json:"order_types"`
+	TimeInForces          map[string]int64 `json:"time_in_forces"`
+	CancelAllTimeInForces map[string]int64 `json:"cancel_all_time_in_forces"`
+	GroupingTypes         map[string]int64 `json:"grouping_types"`
+	MarginModes           map[string]int64 `json:"margin_modes"`
+	MarginDirections      map[string]int64 `json:"margin_directions"`
+	Sentinels             map[string]int64 `json:"sentinels"`
+	Bounds                map[string]int64 `json:"bounds"`
+}
+
+func Constants() ExportedConstants {
+	return ExportedConstants{
+		TxTypes: map[string]int64{
+			"ChangePubKey":        TxTypeL2ChangePubKey,
+			"CreateSubAccount":    TxTypeL2CreateSubAccount,
+			"CreatePublicPool":    TxTypeL2CreatePublicPool,
+			"UpdatePublicPool":    TxTypeL2UpdatePublicPool,
+			"Transfer":            TxTypeL2Transfer,
+			"Withdraw":            TxTypeL2Withdraw,
+			"CreateOrder":         TxTypeL2CreateOrder,
+			"CancelOrder":         TxTypeL2CancelOrder,
+			"CancelAllOrders":     TxTypeL2CancelAllOrders,
+			"ModifyOrder":         TxTypeL2ModifyOrder,
+			"MintShares":          TxTypeL2MintShares,
+			"BurnShares":          TxTypeL2BurnShares,
+			"UpdateLeverage":      TxTypeL2UpdateLeverage,
+			"CreateGroupedOrders": TxTypeL2CreateGroupedOrders,
+			"UpdateMargin":        TxTypeL2UpdateMargin,
+		},
+		OrderTypes: map[string]int64{
+			"LimitOrder":           LimitOrder,
+			"MarketOrder":          MarketOrder,
+			"StopLossOrder":        StopLossOrder,
+			"StopLossLimitOrder":   StopLossLimitOrder,
+			"TakeProfitOrder":      TakeProfitOrder,
+			"TakeProfitLimitOrder": TakeProfitLimitOrder,
+			"TWAPOrder":            TWAPOrder,
+		},
+		TimeInForces: map[string]int64{
+			"ImmediateOrCancel": ImmediateOrCancel,
+			"GoodTillTime":      GoodTillTime,
+			"PostOnly":          PostOnly,
+		},
+		CancelAllTimeInForces: map[string]int64{
+			"ImmediateCancelAll":      ImmediateCancelAll,
+			"ScheduledCancelAll":      ScheduledCancelAll,
+			"AbortScheduledCancelAll": AbortScheduledCancelAll,
+		},
+		GroupingTypes: map[string]int64{
+			"None":                           GroupingType,
+			"OneTriggersTheOther":            GroupingType_OneTriggersTheOther,
+			"OneCancelsTheOther":             GroupingType_OneCancelsTheOther,
+			"OneTriggersAOneCancelsTheOther": GroupingType_OneTriggersAOneCancelsTheOther,
+		},
+		MarginModes: map[string]int64{
+			"CrossMargin":    CrossMargin,
+			"IsolatedMargin": IsolatedMargin,
+		},
+		MarginDirections: map[string]int64{
+			"RemoveFromIsolatedMargin": RemoveFromIsolatedMargin,
+			"AddToIsolatedMargin":      AddToIsolatedMargin,
+		},
+		Sentinels: map[string]int64{
+			"NilApiKeyIndex":       int64(NilApiKeyIndex),
+			"NilClientOrderIndex":  NilClientOrderIndex,
+			"NilOrderIndex":        NilOrderIndex,
+			"NilOrderBaseAmount":   NilOrderBaseAmount,
+			"NilOrderPrice":        int64(NilOrderPrice),
+			"NilOrderExpiry":       NilOrderExpiry,
+			"NilOrderTriggerPrice": int64(NilOrderTriggerPrice),
+			// the worst price of a market order taking whatever the book offers
+			"MarketBuyNoLimitPrice":  int64(MarketBuyNoLimitPrice),
+			"MarketSellNoLimitPrice": int64(MarketSellNoLimitPrice),
+		},
+		Bounds: map[string]int64{
+			"MaxAccountIndex":         MaxAccountIndex,
+			"MaxMasterAccountIndex":   MaxMasterAccountIndex,
+			"MaxApiKeyIndex":          int64(MaxApiKeyIndex),
+			"MaxMarketIndex":          int64(MaxMarketIndex),
+			"MaxClientOrderIndex":     MaxClientOrderIndex,
+			"MaxOrderIndex":           MaxOrderIndex,
+			"MaxOrderBaseAmount":      MaxOrderBaseAmount,
+			"MinClientOrderIndex":     MinClientOrderIndex,
+			"MinOrderBaseAmount":      MinOrderBaseAmount,
+			"MinOrderPrice":           int64(MinOrderPrice),
+			"MaxOrderPrice":           int64(MaxOrderPrice),
+			"MinOrderTriggerPrice":    int64(MinOrderTriggerPrice),
+			"MaxOrderTriggerPrice":    int64(MaxOrderTriggerPrice),
+			"MinOrderExpiryPeriod":    MinOrderExpiryPeriod,
+			"MaxOrderExpiryPeriod":    MaxOrderExpiryPeriod,
+			"MinOrderCancelAllPeriod": MinOrderCancelAllPeriod,
+			"MaxOrderCancelAllPeriod": MaxOrderCancelAllPeriod,
+			"MaxGroupedOrderCount":    MaxGroupedOrderCount,
+			"MaxTimestamp":            MaxTimestamp,
+			"MaxExchangeUSDC":         MaxExchangeUSDC,
+			"MinTransferAmount":       MinTransferAmount,
+			"MaxTransferAmount":       MaxTransferAmount,
+			"OneUSDC":                 OneUSDC,
+			"FeeTick":                 FeeTick,
+			"MarginFractionTick":      MarginFractionTick,
+			"ShareTick":               ShareTick,
+		},
+	}
+}
@@ -15,6 +15,10 @@ type TxInfo interface {
 	// Returns empty string if the Tx is not signed.
 	GetTxHash() string
 
+	// GetSignature returns the Schnorr signature of the transaction, nil if it's not signed. See SigHex for its hex form.
+	GetSignature() []byte
+	SetSignature(sig []byte)
+
 	Validate() error
 
 	Hash(lighterChainId uint32, extra ...g.Element) (msgHash []byte, err error)
@@ -32,6 +32,14 @@ func (txInfo *L2MintSharesTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2MintSharesTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2MintSharesTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2MintSharesTxInfo) Validate() error {
 	if txInfo.AccountIndex < MinAccountIndex {
 		return ErrFromAccountIndexTooLow
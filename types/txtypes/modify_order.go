@@ -1,6 +1,8 @@
 package txtypes
 
 import (
+	"fmt"
+
 	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
 	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
 )
@@ -35,6 +37,14 @@ func (txInfo *L2ModifyOrderTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2ModifyOrderTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2ModifyOrderTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2ModifyOrderTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
@@ -118,3 +128,22 @@ func (txInfo *L2ModifyOrderTxInfo) Hash(lighterChainId uint32, extra ...g.Elemen
 
 	return p2.HashToQuinticExtension(elems).ToLittleEndianBytes(), nil
 }
+
+// ValidateForOrderType checks the modification against the type of the modified order, which the tx doesn't carry:
+// stop loss and take profit orders need a trigger price, and the other orders can't have one.
+func (txInfo *L2ModifyOrderTxInfo) ValidateForOrderType(orderType uint8) error {
+	name := enumName(Constants().OrderTypes, int64(orderType))
+	switch orderType {
+	case StopLossOrder, StopLossLimitOrder, TakeProfitOrder, TakeProfitLimitOrder:
+		if txInfo.TriggerPrice == NilOrderTriggerPrice {
+			return fmt.Errorf("triggerPrice: %w: %v needs a trigger price", ErrOrderTriggerPriceInvalid, name)
+		}
+	case LimitOrder, MarketOrder, TWAPOrder:
+		if txInfo.TriggerPrice != NilOrderTriggerPrice {
+			return fmt.Errorf("triggerPrice: %w: %v has no trigger price, got %v", ErrOrderTriggerPriceInvalid, name, txInfo.TriggerPrice)
+		}
+	default:
+		return fmt.Errorf("orderType: %w: %v", ErrOrderTypeInvalid, orderType)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// L1Signable is implemented by the txs which also need a signature of the account's L1 address.
+type L1Signable interface {
+	TxInfo
+	GetL1SignatureBody() string
+}
+
+// NewTxInfo returns an empty tx of the given type.
+func NewTxInfo(txType uint8) (TxInfo, error) {
+	switch txType {
+	case TxTypeL2ChangePubKey:
+		return &L2ChangePubKeyTxInfo{}, nil
+	case TxTypeL2CreateSubAccount:
+		return &L2CreateSubAccountTxInfo{}, nil
+	case TxTypeL2CreatePublicPool:
+		return &L2CreatePublicPoolTxInfo{}, nil
+	case TxTypeL2UpdatePublicPool:
+		return &L2UpdatePublicPoolTxInfo{}, nil
+	case TxTypeL2Transfer:
+		return &L2TransferTxInfo{}, nil
+	case TxTypeL2Withdraw:
+		return &L2WithdrawTxInfo{}, nil
+	case TxTypeL2CreateOrder:
+		return &L2CreateOrderTxInfo{}, nil
+	case TxTypeL2CancelOrder:
+		return &L2CancelOrderTxInfo{}, nil
+	case TxTypeL2CancelAllOrders:
+		return &L2CancelAllOrdersTxInfo{}, nil
+	case TxTypeL2ModifyOrder:
+		return &L2ModifyOrderTxInfo{}, nil
+	case TxTypeL2MintShares:
+		return &L2MintSharesTxInfo{}, nil
+	case TxTypeL2BurnShares:
+		return &L2BurnSharesTxInfo{}, nil
+	case TxTypeL2UpdateLeverage:
+		return &L2UpdateLeverageTxInfo{}, nil
+	case TxTypeL2CreateGroupedOrders:
+		return &L2CreateGroupedOrdersTxInfo{}, nil
+	case TxTypeL2UpdateMargin:
+		return &L2UpdateMarginTxInfo{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tx type %d", txType)
+	}
+}
+
+// optionalTxFields may be missing from the JSON of a tx, which is then unsigned.
+var optionalTxFields = map[string]bool{"Sig": true, "L1Sig": true}
+
+// ParseTxInfo decodes the JSON of a tx, as returned by GetTxInfo, into the struct of its type.
+// Unknown and missing fields are rejected, so a JSON of another tx type doesn't decode silently
+// into zero values. Only the signatures may be missing.
+func ParseTxInfo(txType uint8, data []byte) (TxInfo, error) {
+	tx, err := NewTxInfo(txType)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid tx info for tx type %d. err: %w", txType, err)
+	}
+	var missing []string
+	for _, name := range jsonFieldNames(reflect.TypeOf(tx).Elem()) {
+		if _, ok := fields[name]; !ok && !optionalTxFields[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("invalid tx info for tx type %d. missing fields: %s", txType, strings.Join(missing, ", "))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(tx); err != nil {
+		return nil, fmt.Errorf("invalid tx info for tx type %d. err: %w", txType, err)
+	}
+	return tx, nil
+}
+
+// jsonFieldNames lists the JSON keys of an untagged struct, including the ones of embedded structs.
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("json") == "-" || !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			names = append(names, jsonFieldNames(ft)...)
+			continue
+		}
+		names = append(names, field.Name)
+	}
+	return names
+}
@@ -86,6 +86,14 @@ func (txInfo *L2TransferTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2TransferTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2TransferTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2TransferTxInfo) GetTxInfo() (string, error) {
 	return getTxInfo(txInfo)
 }
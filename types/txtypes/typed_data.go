@@ -0,0 +1,126 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TypedDataDomain is the EIP-712 domain under which the L1 verification contract of a Lighter chain checks typed
+// signatures.
+type TypedDataDomain struct {
+	Name              string `json:"name"`
+	Version           string `json:"version"`
+	ChainId           uint64 `json:"chainId"`
+	VerifyingContract string `json:"verifyingContract"`
+}
+
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is an EIP-712 typed data message, in the shape taken by eth_signTypedData_v4.
+// Integers of the message are decimal strings, so 64 bit values survive JSON parsers that use float64.
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      TypedDataDomain             `json:"domain"`
+	Message     map[string]string           `json:"message"`
+}
+
+var eip712DomainType = []TypedDataField{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+var (
+	typedDataMu      sync.RWMutex
+	typedDataDomains = make(map[uint32]TypedDataDomain)
+)
+
+// EnableL1TypedData makes the typed data form of the L1 messages available for the Lighter chain, under the domain
+// of its L1 verification contract. Chains start without it, since only the legacy personal_sign string is known to
+// be accepted everywhere.
+func EnableL1TypedData(lighterChainId uint32, domain TypedDataDomain) {
+	typedDataMu.Lock()
+	defer typedDataMu.Unlock()
+	typedDataDomains[lighterChainId] = domain
+}
+
+// DisableL1TypedData reverts EnableL1TypedData for the Lighter chain.
+func DisableL1TypedData(lighterChainId uint32) {
+	typedDataMu.Lock()
+	defer typedDataMu.Unlock()
+	delete(typedDataDomains, lighterChainId)
+}
+
+// L1TypedDataDomain returns the domain of the Lighter chain, and false if typed data is not enabled for it.
+func L1TypedDataDomain(lighterChainId uint32) (TypedDataDomain, bool) {
+	typedDataMu.RLock()
+	defer typedDataMu.RUnlock()
+	domain, ok := typedDataDomains[lighterChainId]
+	return domain, ok
+}
+
+func newTypedData(domain TypedDataDomain, primaryType string, fields []TypedDataField, message map[string]string) *TypedData {
+	return &TypedData{
+		Types: map[string][]TypedDataField{
+			"EIP712Domain": eip712DomainType,
+			primaryType:    fields,
+		},
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     message,
+	}
+}
+
+// GetL1TypedData is the EIP-712 equivalent of GetL1SignatureBody. It returns nil if typed data is not enabled for
+// the Lighter chain.
+func (txInfo *L2ChangePubKeyTxInfo) GetL1TypedData(lighterChainId uint32) *TypedData {
+	domain, ok := L1TypedDataDomain(lighterChainId)
+	if !ok {
+		return nil
+	}
+	return newTypedData(domain, "RegisterLighterAccount", []TypedDataField{
+		{Name: "pubkey", Type: "bytes"},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "accountIndex", Type: "uint64"},
+		{Name: "apiKeyIndex", Type: "uint8"},
+	}, map[string]string{
+		"pubkey":       "0x" + common.Bytes2Hex(txInfo.PubKey),
+		"nonce":        strconv.FormatUint(uint64(txInfo.Nonce), 10),
+		"accountIndex": strconv.FormatUint(uint64(txInfo.AccountIndex), 10),
+		"apiKeyIndex":  strconv.FormatUint(uint64(txInfo.ApiKeyIndex), 10),
+	})
+}
+
+// GetL1TypedData is the EIP-712 equivalent of GetL1SignatureBody. It returns nil if typed data is not enabled for
+// the Lighter chain.
+func (txInfo *L2TransferTxInfo) GetL1TypedData(lighterChainId uint32) *TypedData {
+	domain, ok := L1TypedDataDomain(lighterChainId)
+	if !ok {
+		return nil
+	}
+	return newTypedData(domain, "Transfer", []TypedDataField{
+		{Name: "nonce", Type: "uint64"},
+		{Name: "from", Type: "uint64"},
+		{Name: "apiKeyIndex", Type: "uint8"},
+		{Name: "to", Type: "uint64"},
+		{Name: "amount", Type: "uint64"},
+		{Name: "fee", Type: "uint64"},
+		{Name: "memo", Type: "bytes32"},
+	}, map[string]string{
+		"nonce":       strconv.FormatUint(uint64(txInfo.Nonce), 10),
+		"from":        strconv.FormatUint(uint64(txInfo.FromAccountIndex), 10),
+		"apiKeyIndex": strconv.FormatUint(uint64(txInfo.ApiKeyIndex), 10),
+		"to":          strconv.FormatUint(uint64(txInfo.ToAccountIndex), 10),
+		"amount":      strconv.FormatUint(uint64(txInfo.USDCAmount), 10),
+		"fee":         strconv.FormatUint(uint64(txInfo.Fee), 10),
+		"memo":        "0x" + hex.EncodeToString(txInfo.Memo[:]),
+	})
+}
@@ -0,0 +1,100 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+const typedDataChainId = 304
+
+var typedDataDomain = TypedDataDomain{
+	Name:              "Lighter",
+	Version:           "1",
+	ChainId:           1,
+	VerifyingContract: "0x3B4D794a66304F130a4Db8F2551B0070dfCf5ca7",
+}
+
+// typedDataGolden is the content of a golden file: the typed data as given to eth_signTypedData_v4, and the
+// EIP-712 hash a wallet signs for it.
+type typedDataGolden struct {
+	TypedData *TypedData `json:"typedData"`
+	Hash      string     `json:"hash"`
+}
+
+func checkTypedDataGolden(t *testing.T, name string, typedData *TypedData) {
+	t.Helper()
+	if typedData == nil {
+		t.Fatalf("%v: no typed data", name)
+	}
+
+	// the typed data must be accepted by a standard EIP-712 implementation
+	raw, err := json.Marshal(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var standard apitypes.TypedData
+	if err := json.Unmarshal(raw, &standard); err != nil {
+		t.Fatal(err)
+	}
+	hash, _, err := apitypes.TypedDataAndHash(standard)
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+
+	got, err := json.MarshalIndent(typedDataGolden{TypedData: typedData, Hash: hexutil.Encode(hash)}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%v: typed data changed, got\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+func TestTypedDataGolden(t *testing.T) {
+	EnableL1TypedData(typedDataChainId, typedDataDomain)
+	t.Cleanup(func() { DisableL1TypedData(typedDataChainId) })
+
+	pubKey := make([]byte, 40)
+	for i := range pubKey {
+		pubKey[i] = byte(i + 1)
+	}
+	changePubKey := &L2ChangePubKeyTxInfo{AccountIndex: 281474976710654, ApiKeyIndex: 3, PubKey: pubKey, Nonce: 9007199254740993}
+	checkTypedDataGolden(t, "change_pub_key", changePubKey.GetL1TypedData(typedDataChainId))
+
+	transfer := &L2TransferTxInfo{
+		FromAccountIndex: 12,
+		ApiKeyIndex:      4,
+		ToAccountIndex:   281474976710654,
+		USDCAmount:       1_000_000_000,
+		Fee:              3_000_000,
+		Nonce:            42,
+	}
+	copy(transfer.Memo[:], "rent")
+	checkTypedDataGolden(t, "transfer", transfer.GetL1TypedData(typedDataChainId))
+}
+
+func TestTypedDataDisabled(t *testing.T) {
+	if typedData := (&L2TransferTxInfo{}).GetL1TypedData(typedDataChainId + 1); typedData != nil {
+		t.Fatalf("typed data of a chain without it: %+v", typedData)
+	}
+}
@@ -33,6 +33,14 @@ func (txInfo *L2UpdateLeverageTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2UpdateLeverageTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2UpdateLeverageTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2UpdateLeverageTxInfo) Validate() error {
 	if txInfo.AccountIndex < MinAccountIndex {
 		return ErrFromAccountIndexTooLow
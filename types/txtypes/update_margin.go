@@ -33,6 +33,14 @@ func (txInfo *L2UpdateMarginTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2UpdateMarginTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2UpdateMarginTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2UpdateMarginTxInfo) Validate() error {
 	if txInfo.AccountIndex < MinAccountIndex {
 		return ErrFromAccountIndexTooLow
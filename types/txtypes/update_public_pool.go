@@ -35,6 +35,14 @@ func (txInfo *L2UpdatePublicPoolTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2UpdatePublicPoolTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2UpdatePublicPoolTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2UpdatePublicPoolTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
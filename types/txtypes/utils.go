@@ -1,6 +1,14 @@
 package txtypes
 
-import "encoding/json"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SignatureLength is the size in bytes of a Schnorr signature.
+const SignatureLength = 80
 
 func IsValidPubKey(bytes []byte) bool {
 	if len(bytes) != 40 {
@@ -26,3 +34,26 @@ func getTxInfo(tx interface{}) (string, error) {
 	}
 	return string(txInfoBytes), nil
 }
+
+// EncodeSigHex is the canonical hex encoding of a signature: lowercase, without 0x prefix.
+func EncodeSigHex(sig []byte) string {
+	return hex.EncodeToString(sig)
+}
+
+// SigHex returns the signature of tx in its canonical hex encoding, "" if it's not signed.
+func SigHex(tx TxInfo) string {
+	return EncodeSigHex(tx.GetSignature())
+}
+
+// ParseSigHex decodes a hex-encoded signature, with or without 0x prefix, and checks it's SignatureLength bytes long.
+func ParseSigHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(s, "0x")
+	if len(s) != 2*SignatureLength {
+		return nil, fmt.Errorf("invalid signature length. expected %d hex chars got: %d", 2*SignatureLength, len(s))
+	}
+	sig, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature hex. err: %w", err)
+	}
+	return sig, nil
+}
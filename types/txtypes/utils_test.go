@@ -0,0 +1,106 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// allTxInfos returns an unsigned tx of every L2 tx type, with zero fields.
+func allTxInfos(t *testing.T) []TxInfo {
+	t.Helper()
+	var txs []TxInfo
+	for txType := uint8(TxTypeL2ChangePubKey); txType <= TxTypeL2UpdateMargin; txType++ {
+		tx, err := NewTxInfo(txType)
+		if err != nil {
+			continue
+		}
+		if order, ok := tx.(*L2CreateOrderTxInfo); ok {
+			order.OrderInfo = &OrderInfo{}
+		}
+		txs = append(txs, tx)
+	}
+	if len(txs) != 15 {
+		t.Fatalf("%v tx types, want 15", len(txs))
+	}
+	return txs
+}
+
+func testSig() []byte {
+	sig := make([]byte, SignatureLength)
+	for i := range sig {
+		sig[i] = byte(i * 7)
+	}
+	return sig
+}
+
+func TestSigHexRoundTrip(t *testing.T) {
+	sig := testSig()
+	for _, tx := range allTxInfos(t) {
+		if got := SigHex(tx); got != "" {
+			t.Fatalf("tx type %v: unsigned tx has signature %q", tx.GetTxType(), got)
+		}
+
+		tx.SetSignature(sig)
+		if !bytes.Equal(tx.GetSignature(), sig) {
+			t.Fatalf("tx type %v: GetSignature returned %x", tx.GetTxType(), tx.GetSignature())
+		}
+		sigHex := SigHex(tx)
+		if sigHex != hex.EncodeToString(sig) {
+			t.Fatalf("tx type %v: SigHex %q", tx.GetTxType(), sigHex)
+		}
+		parsed, err := ParseSigHex(sigHex)
+		if err != nil || !bytes.Equal(parsed, sig) {
+			t.Fatalf("tx type %v: ParseSigHex got %x, %v", tx.GetTxType(), parsed, err)
+		}
+
+		// the signature survives the JSON of the tx
+		txInfo, err := tx.GetTxInfo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := ParseTxInfo(tx.GetTxType(), []byte(txInfo))
+		if err != nil {
+			t.Fatalf("tx type %v: %v", tx.GetTxType(), err)
+		}
+		if SigHex(decoded) != sigHex {
+			t.Fatalf("tx type %v: signature %q after the JSON round trip", tx.GetTxType(), SigHex(decoded))
+		}
+	}
+}
+
+func TestParseSigHex(t *testing.T) {
+	sigHex := hex.EncodeToString(testSig())
+	for _, s := range []string{"0x" + sigHex, strings.ToUpper(sigHex)} {
+		if sig, err := ParseSigHex(s); err != nil || !bytes.Equal(sig, testSig()) {
+			t.Errorf("%q: got %x, %v", s, sig, err)
+		}
+	}
+
+	for name, s := range map[string]string{
+		"empty":          "",
+		"short":          sigHex[2:],
+		"odd":            sigHex[1:],
+		"long":           sigHex + "00",
+		"prefix only":    "0x",
+		"non-hex":        "zz" + sigHex[2:],
+		"double prefix":  "0x0x" + sigHex[4:],
+		"too short (0x)": "0x" + sigHex[2:],
+	} {
+		if sig, err := ParseSigHex(s); err == nil {
+			t.Errorf("%v: accepted as %x", name, sig)
+		}
+	}
+}
+
+func TestVerifySignatureChecksLength(t *testing.T) {
+	pubKey := bytes.Repeat([]byte{1}, 40)
+	for _, n := range []int{SignatureLength - 1, SignatureLength + 1} {
+		tx := &L2CancelOrderTxInfo{Sig: make([]byte, n)}
+		if err := VerifySignature(tx, 304, pubKey); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%v bytes: got %v, want ErrInvalidSignature", n, err)
+		}
+	}
+}
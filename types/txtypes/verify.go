@@ -0,0 +1,31 @@
+package txtypes
+
+import (
+	"fmt"
+
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+)
+
+// VerifySignature checks that the signature of tx verifies against pubKey for the given chain id.
+// It returns ErrInvalidSignature when it doesn't.
+func VerifySignature(tx TxInfo, lighterChainId uint32, pubKey []byte) error {
+	sig := tx.GetSignature()
+	if len(sig) == 0 {
+		return fmt.Errorf("tx is not signed")
+	}
+	if len(sig) != SignatureLength {
+		return fmt.Errorf("%w: expected %d bytes got: %d", ErrInvalidSignature, SignatureLength, len(sig))
+	}
+	if !IsValidPubKey(pubKey) {
+		return ErrPubKeyInvalid
+	}
+
+	msgHash, err := tx.Hash(lighterChainId)
+	if err != nil {
+		return err
+	}
+	if err := schnorr.Validate(pubKey, msgHash, sig); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return nil
+}
@@ -65,6 +65,14 @@ func (txInfo *L2WithdrawTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2WithdrawTxInfo) GetSignature() []byte {
+	return txInfo.Sig
+}
+
+func (txInfo *L2WithdrawTxInfo) SetSignature(sig []byte) {
+	txInfo.Sig = sig
+}
+
 func (txInfo *L2WithdrawTxInfo) Hash(lighterChainId uint32, extra ...g.Element) (msgHash []byte, err error) {
 	elems := make([]g.Element, 0, 8)
 
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// ParseUpdateMarginAmount turns a signed amount string and an optional action into an UpdateMargin amount and direction.
+//
+// amount is either an integer in USDC units ("-2500000") or a decimal in whole USDC ("-2.5"), with an optional sign:
+// a positive amount adds to the isolated margin and a negative one removes from it.
+// action is "add", "remove" or "". When set, it gives the direction of an unsigned amount;
+// an amount whose explicit sign says otherwise is rejected.
+func ParseUpdateMarginAmount(amount string, action string) (int64, uint8, error) {
+	amount = strings.TrimSpace(amount)
+	sign := 0
+	switch {
+	case strings.HasPrefix(amount, "-"):
+		sign = -1
+	case strings.HasPrefix(amount, "+"):
+		sign = 1
+	}
+	unsigned := strings.TrimLeft(amount, "+-")
+	if unsigned == "" || strings.Trim(unsigned, "0123456789.") != "" || strings.Count(unsigned, ".") > 1 {
+		return 0, 0, fmt.Errorf("invalid margin amount %q", amount)
+	}
+
+	value, ok := new(big.Rat).SetString(unsigned)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid margin amount %q", amount)
+	}
+	if strings.Contains(unsigned, ".") {
+		value.Mul(value, new(big.Rat).SetInt64(txtypes.OneUSDC))
+	}
+	if !value.IsInt() {
+		return 0, 0, fmt.Errorf("margin amount %q has more than 6 decimals", amount)
+	}
+	units := value.Num()
+	if !units.IsInt64() || units.Int64() > txtypes.MaxTransferAmount {
+		return 0, 0, txtypes.ErrTransferAmountTooHigh
+	}
+	if units.Sign() == 0 {
+		return 0, 0, txtypes.ErrTransferAmountTooLow
+	}
+
+	var direction uint8
+	switch action {
+	case "add":
+		direction = txtypes.AddToIsolatedMargin
+		if sign < 0 {
+			return 0, 0, fmt.Errorf("margin amount %q is negative but action is %q", amount, action)
+		}
+	case "remove":
+		direction = txtypes.RemoveFromIsolatedMargin
+		if sign > 0 {
+			return 0, 0, fmt.Errorf("margin amount %q is positive but action is %q", amount, action)
+		}
+	case "":
+		direction = txtypes.AddToIsolatedMargin
+		if sign < 0 {
+			direction = txtypes.RemoveFromIsolatedMargin
+		}
+	default:
+		return 0, 0, fmt.Errorf("invalid margin action %q, expected \"add\" or \"remove\"", action)
+	}
+	return units.Int64(), direction, nil
+}